@@ -0,0 +1,89 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/fugue/regula/v3/pkg/reporter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const defaultBaselinePath = "regula-baseline.json"
+
+var baselineCommand = &cobra.Command{
+	Use:   "baseline [command]",
+	Short: "Manage baselines of pre-existing rule failures.",
+}
+
+func NewBaselineCreateCommand() *cobra.Command {
+	description := "Create a baseline file from the rule failures in one or more paths."
+	v := viper.New()
+	cmd := &cobra.Command{
+		Use:   "create [input...]",
+		Short: description,
+		Long: joinDescriptions(
+			description,
+			"Inputs and options are handled the same way as in the 'regula run' command. Pass the baseline to 'regula run --baseline' to only report failures that are not in the baseline.",
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// This command doesn't report results, but the defaults are
+			// still needed to validate the configuration file.
+			v.SetDefault(formatFlag, reporter.DefaultFormat)
+			v.SetDefault(severityFlag, reporter.DefaultSeverity)
+			config, err := newRunConfig(cmd, v, args)
+			if err != nil {
+				return err
+			}
+			if config.baseline == "" {
+				config.baseline = defaultBaselinePath
+			}
+			config.createBaseline = true
+			if err := config.Validate(); err != nil {
+				return err
+			}
+
+			// Silence usage now that we're past arg parsing
+			cmd.SilenceUsage = true
+			return config.Execute()
+		},
+	}
+
+	cmd.Flags().String(baselineFlag, "", "Path to write the baseline to (default \""+defaultBaselinePath+"\")")
+	v.BindPFlag(baselineFlag, cmd.Flags().Lookup(baselineFlag))
+	addCacheFlag(cmd, v)
+	addConfigFlag(cmd)
+	addEnvironmentIDFlag(cmd, v)
+	addExcludeFlag(cmd, v)
+	addIncludeFlag(cmd)
+	addInputTypeFlag(cmd, v)
+	addJobsFlag(cmd, v)
+	addNoBuiltInsFlag(cmd, v)
+	addNoConfigFlag(cmd)
+	addNoIgnoreFlag(cmd, v)
+	addNoInlineIgnoreFlag(cmd, v)
+	addOfflineFlag(cmd, v)
+	addOnlyFlag(cmd, v)
+	addSyncFlag(cmd, v)
+	addVarFileFlag(cmd, v)
+	addVerifyKeyFlag(cmd, v)
+	addWaiverFileFlag(cmd, v)
+	cmd.Flags().SetNormalizeFunc(normalizeFlag)
+	return cmd
+}
+
+func init() {
+	baselineCommand.AddCommand(NewBaselineCreateCommand())
+	rootCmd.AddCommand(baselineCommand)
+}
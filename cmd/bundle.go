@@ -0,0 +1,113 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/fugue/regula/v3/pkg/rego"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const keyFlag = "key"
+
+var bundleCommand = &cobra.Command{
+	Use:   "bundle [command]",
+	Short: "Manage rule bundles.",
+}
+
+func NewBundleManifestCommand() *cobra.Command {
+	description := "Write a manifest of the rego files in a rule bundle directory, and optionally sign it."
+	cmd := &cobra.Command{
+		Use:   "manifest [directory]",
+		Short: description,
+		Long: joinDescriptions(
+			description,
+			"The manifest is written to "+rego.BundleManifestName+" and lists the SHA-256 digest of every rego file in the directory. With --key, the signature is written to "+rego.BundleSignatureName+". To sign the manifest with cosign instead, run 'cosign sign-blob --key cosign.key --output-signature "+rego.BundleSignatureName+" "+rego.BundleManifestName+"'. Archive the directory as a .tar.gz file or push it to a git repository, and use --verify-key with the public key to only load the rules if they match the manifest.",
+		),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			key, err := cmd.Flags().GetString(keyFlag)
+			if err != nil {
+				return err
+			}
+
+			// Silence usage now that we're past arg parsing
+			cmd.SilenceUsage = true
+
+			files := map[string][]byte{}
+			walkDirFunc := func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() || filepath.Ext(path) != ".rego" {
+					return nil
+				}
+				contents, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				rel, err := filepath.Rel(dir, path)
+				if err != nil {
+					return err
+				}
+				files[filepath.ToSlash(rel)] = contents
+				return nil
+			}
+			if err := filepath.WalkDir(dir, walkDirFunc); err != nil {
+				return err
+			}
+
+			manifest, err := rego.NewBundleManifest(files).Marshal()
+			if err != nil {
+				return err
+			}
+			manifestPath := filepath.Join(dir, rego.BundleManifestName)
+			if err := os.WriteFile(manifestPath, manifest, 0644); err != nil {
+				return err
+			}
+			logrus.Infof("Wrote manifest of %d files to %s", len(files), manifestPath)
+
+			if key != "" {
+				signature, err := rego.SignBundleManifest(manifest, key)
+				if err != nil {
+					return err
+				}
+				signaturePath := filepath.Join(dir, rego.BundleSignatureName)
+				if err := os.WriteFile(signaturePath, signature, 0644); err != nil {
+					return err
+				}
+				logrus.Infof("Wrote signature to %s", signaturePath)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String(keyFlag, "", "Path to a PEM encoded ed25519, ECDSA or RSA private key (PKCS #8) to sign the manifest with")
+	cmd.Flags().SetNormalizeFunc(normalizeFlag)
+	return cmd
+}
+
+func init() {
+	bundleCommand.AddCommand(NewBundleManifestCommand())
+	rootCmd.AddCommand(bundleCommand)
+}
@@ -45,6 +45,15 @@ const uploadFlag = "upload"
 const excludeFlag = "exclude"
 const onlyFlag = "only"
 const varFileFlag = "var-file"
+const jobsFlag = "jobs"
+const cacheFlag = "cache"
+const changedSinceFlag = "changed-since"
+const baselineFlag = "baseline"
+const waiverFileFlag = "waiver-file"
+const noInlineIgnoreFlag = "no-inline-ignore"
+const outputFlag = "output"
+const offlineFlag = "offline"
+const verifyKeyFlag = "verify-key"
 
 const inputTypeDescriptions = `
 Input types:
@@ -54,17 +63,24 @@ Input types:
     tf          Terraform directory or file (either .tf or .tf.json format)
     k8s         Kubernetes manifest in YAML format
     arm         Azure Resource Manager (ARM) JSON templates (feature in preview)
+    pulumi      Pulumi stack export or 'pulumi preview --json' output
+    helm        Helm chart directory, rendered with the values files in --var-file
+    cdk         AWS CDK cloud assembly directory (cdk.out)
 `
 const formatDescriptions = `
 Output formats:
-    text    A human friendly format (default)
-    json    A JSON report containing rule results and a summary
-    table   An ASCII table of rule results
-    junit   The JUnit XML format
-    tap     The Test Anything Protocol format
-    compact An alternate, more compact human friendly format
-    sarif   Static Analysis Results Interchange Format
-    none    Do not print any output on stdout
+    text        A human friendly format (default)
+    json        A JSON report containing rule results and a summary
+    table       An ASCII table of rule results
+    junit       The JUnit XML format
+    tap         The Test Anything Protocol format
+    compact     An alternate, more compact human friendly format
+    sarif       Static Analysis Results Interchange Format
+    html        A self-contained HTML page for sharing
+    gitlab      The GitLab Code Quality format
+    sonarqube   The SonarQube Generic Issue Import format
+    ocsf        OCSF Compliance Findings for security data lakes
+    none        Do not print any output on stdout
 `
 const severityDescriptions = `
 Severities:
@@ -87,7 +103,7 @@ func addNoTestInputsFlag(cmd *cobra.Command) {
 }
 
 func addIncludeFlag(cmd *cobra.Command) {
-	cmd.Flags().StringSliceP(includeFlag, "i", nil, "Specify additional rego files or directories to include")
+	cmd.Flags().StringSliceP(includeFlag, "i", nil, "Specify additional rego files or directories to include, or remote rule bundles (oci://, https:// or git:: sources)")
 }
 
 func addNoIgnoreFlag(cmd *cobra.Command, v *viper.Viper) {
@@ -114,6 +130,11 @@ func addFormatFlag(cmd *cobra.Command, v *viper.Viper) {
 	cmd.Long = joinDescriptions(cmd.Long, formatDescriptions)
 }
 
+func addOutputFlag(cmd *cobra.Command, v *viper.Viper) {
+	cmd.Flags().StringSlice(outputFlag, nil, "Also write the report in another format to a file, e.g. sarif=regula.sarif. Can be specified multiple times.")
+	v.BindPFlag(outputFlag, cmd.Flags().Lookup(outputFlag))
+}
+
 func addTraceFlag(cmd *cobra.Command) {
 	cmd.Flags().BoolP(traceFlag, "t", false, "Enable trace output")
 }
@@ -156,10 +177,49 @@ func addOnlyFlag(cmd *cobra.Command, v *viper.Viper) {
 }
 
 func addVarFileFlag(cmd *cobra.Command, v *viper.Viper) {
-	cmd.Flags().StringSlice(varFileFlag, nil, "Paths to .tfvars or .json files to be used while evaluating Terraform HCL source code. Can be specified multiple times.")
+	cmd.Flags().StringSlice(varFileFlag, nil, "Paths to .tfvars or .json files to be used while evaluating Terraform HCL source code, ARM or CloudFormation parameter files to evaluate templates with, or .yaml values files to render Helm charts with. Can be specified multiple times.")
 	v.BindPFlag(varFileFlag, cmd.Flags().Lookup(varFileFlag))
 }
 
+func addJobsFlag(cmd *cobra.Command, v *viper.Viper) {
+	cmd.Flags().IntP(jobsFlag, "j", 0, "Number of IaC configurations to process in parallel. Defaults to the number of CPUs.")
+	v.BindPFlag(jobsFlag, cmd.Flags().Lookup(jobsFlag))
+}
+
+func addCacheFlag(cmd *cobra.Command, v *viper.Viper) {
+	cmd.Flags().Bool(cacheFlag, false, "Reuse rule results for configurations that have not changed since a previous run")
+	v.BindPFlag(cacheFlag, cmd.Flags().Lookup(cacheFlag))
+}
+
+func addChangedSinceFlag(cmd *cobra.Command) {
+	cmd.Flags().String(changedSinceFlag, "", "Only evaluate IaC configurations containing files that changed since this git revision")
+}
+
+func addBaselineFlag(cmd *cobra.Command, v *viper.Viper) {
+	cmd.Flags().String(baselineFlag, "", "Path to a baseline file. Failures in the baseline are reported as BASELINED and do not count towards --severity.")
+	v.BindPFlag(baselineFlag, cmd.Flags().Lookup(baselineFlag))
+}
+
+func addWaiverFileFlag(cmd *cobra.Command, v *viper.Viper) {
+	cmd.Flags().StringSlice(waiverFileFlag, nil, "Paths to YAML or JSON files with rule waivers. Can be specified multiple times.")
+	v.BindPFlag(waiverFileFlag, cmd.Flags().Lookup(waiverFileFlag))
+}
+
+func addNoInlineIgnoreFlag(cmd *cobra.Command, v *viper.Viper) {
+	cmd.Flags().Bool(noInlineIgnoreFlag, false, "Disallow waiving rule results with regula:ignore comments in IaC source code")
+	v.BindPFlag(noInlineIgnoreFlag, cmd.Flags().Lookup(noInlineIgnoreFlag))
+}
+
+func addVerifyKeyFlag(cmd *cobra.Command, v *viper.Viper) {
+	cmd.Flags().String(verifyKeyFlag, "", "Path to a public key that rule bundles must be signed with. Unsigned or modified bundles are refused.")
+	v.BindPFlag(verifyKeyFlag, cmd.Flags().Lookup(verifyKeyFlag))
+}
+
+func addOfflineFlag(cmd *cobra.Command, v *viper.Viper) {
+	cmd.Flags().Bool(offlineFlag, false, "Only load remote rule bundles from the cache, without network access")
+	v.BindPFlag(offlineFlag, cmd.Flags().Lookup(offlineFlag))
+}
+
 func joinDescriptions(descriptions ...string) string {
 	normalizedDescriptions := make([]string, len(descriptions))
 	for i, d := range descriptions {
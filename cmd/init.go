@@ -64,9 +64,15 @@ func NewInitCommand() *cobra.Command {
 			if err := configureBoolIfSet(cmd, v, noIgnoreFlag); err != nil {
 				return err
 			}
+			if err := configureBoolIfSet(cmd, v, noInlineIgnoreFlag); err != nil {
+				return err
+			}
 			if err := configureStringSliceIfSet(cmd, v, onlyFlag); err != nil {
 				return err
 			}
+			if err := configureEnumSliceIfSet(cmd, v, outputFlag, reporter.ValidateOutputs); err != nil {
+				return err
+			}
 			if err := configureEnumIfSet(cmd, v, severityFlag, reporter.ValidateSeverity); err != nil {
 				return err
 			}
@@ -76,6 +82,12 @@ func NewInitCommand() *cobra.Command {
 			if err := configureStringSliceIfSet(cmd, v, varFileFlag); err != nil {
 				return err
 			}
+			if err := configureStringIfSet(cmd, v, verifyKeyFlag); err != nil {
+				return err
+			}
+			if err := configureStringSliceIfSet(cmd, v, waiverFileFlag); err != nil {
+				return err
+			}
 			if len(paths) > 0 {
 				v.Set(inputsFlag, paths)
 			}
@@ -120,10 +132,14 @@ func NewInitCommand() *cobra.Command {
 	addInputTypeFlag(cmd, v)
 	addNoBuiltInsFlag(cmd, v)
 	addNoIgnoreFlag(cmd, v)
+	addNoInlineIgnoreFlag(cmd, v)
 	addOnlyFlag(cmd, v)
+	addOutputFlag(cmd, v)
 	addSeverityFlag(cmd, v)
 	addSyncFlag(cmd, v)
 	addVarFileFlag(cmd, v)
+	addVerifyKeyFlag(cmd, v)
+	addWaiverFileFlag(cmd, v)
 	cmd.Flags().SetNormalizeFunc(normalizeFlag)
 	return cmd
 }
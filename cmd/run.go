@@ -15,14 +15,10 @@
 package cmd
 
 import (
-	"context"
 	_ "embed"
-	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/fugue/regula/v3/pkg/loader"
-	"github.com/fugue/regula/v3/pkg/rego"
 	"github.com/fugue/regula/v3/pkg/reporter"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -39,146 +35,186 @@ func NewRunCommand() *cobra.Command {
 		Long:  runDescription,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// CLI-arg-only options
-			noConfig, err := cmd.Flags().GetBool(noConfigFlag)
-			if err != nil {
-				return err
-			}
 			upload, err := cmd.Flags().GetBool(uploadFlag)
 			if err != nil {
 				return err
 			}
-
-			// Config-file specific options
-			var rootDir string
-			var configPath string
-			if !noConfig {
-				configPath, err = cmd.Flags().GetString(configFlag)
-				if err != nil {
-					return err
-				}
-				if err := loadConfigFile(configPath, v); err != nil {
-					return err
-				}
-				if c := v.ConfigFileUsed(); c != "" {
-					rootDir = filepath.Dir(c)
-				}
-			}
-			// Inputs
-			configFileInputs := v.GetStringSlice(inputsFlag)
-			inputs, err := translateInputs(args, configFileInputs, rootDir)
-			if err != nil {
-				return err
-			}
-
-			// Includes
-			cliIncludes, err := cmd.Flags().GetStringSlice(includeFlag)
-			if err != nil {
-				return err
-			}
-			configFileIncludes := v.GetStringSlice(includeFlag)
-			includes, err := translateIncludes(cliIncludes, configFileIncludes, rootDir)
+			changedSince, err := cmd.Flags().GetString(changedSinceFlag)
 			if err != nil {
 				return err
 			}
 
-			// Enum types
-			inputTypeNames := v.GetStringSlice(inputTypeFlag)
-			inputTypes, err := loader.InputTypesFromStrings(inputTypeNames)
+			config, err := newRunConfig(cmd, v, args)
 			if err != nil {
 				return err
 			}
-			format, err := reporter.FormatFromString(v.GetString(formatFlag))
-			if err != nil {
-				return err
-			}
-			severity, err := reporter.SeverityFromString(v.GetString(severityFlag))
-			if err != nil {
-				return err
-			}
-
-			config := &runConfig{
-				configPath:    configPath,
-				environmentId: v.GetString(environmentIDFlag),
-				excludes:      v.GetStringSlice(excludeFlag),
-				format:        format,
-				includes:      includes,
-				inputs:        inputs,
-				inputTypes:    inputTypes,
-				noBuiltIns:    v.GetBool(noBuiltInsFlag),
-				noConfig:      noConfig,
-				noIgnore:      v.GetBool(noIgnoreFlag),
-				only:          v.GetStringSlice(onlyFlag),
-				rootDir:       rootDir,
-				severity:      severity,
-				sync:          v.GetBool(syncFlag),
-				upload:        upload,
-				varFiles:      v.GetStringSlice(varFileFlag),
-			}
+			config.upload = upload
+			config.changedSince = changedSince
 			if err := config.Validate(); err != nil {
 				return err
 			}
 
 			// Silence usage now that we're past arg parsing
 			cmd.SilenceUsage = true
-			// Interpret configuration
-			configLoader, err := config.ConfigurationLoader()
-			if err != nil {
-				return err
-			}
-			providers, err := config.Providers()
-			if err != nil {
-				return err
-			}
-			resultProcessor := config.ResultProcessor()
-			if config.rootDir != "" {
-				// Changing directories is the easiest and most robust way to
-				// get all paths relative to the config file.
-				if err := os.Chdir(config.rootDir); err != nil {
-					// Not sure whether this error is possible here since we were
-					// able to load the file. But, just in case.
-					return fmt.Errorf("Unable to change to config file directory: %s", err)
-				}
-			}
-
-			// Execution
-			ctx := context.Background()
-			loadedConfigs, err := configLoader()
-			if err != nil {
-				return err
-			}
-			result, err := rego.RunRules(ctx, &rego.RunRulesOptions{
-				Providers: providers,
-				Input:     loadedConfigs.RegulaInput(),
-				Query:     config.RunRulesQuery(),
-			})
-			if err != nil {
-				return err
-			}
-			if err := resultProcessor(ctx, loadedConfigs, result); err != nil {
-				return err
-			}
-			return nil
+			return config.Execute()
 		},
 	}
 
+	addBaselineFlag(cmd, v)
+	addCacheFlag(cmd, v)
+	addChangedSinceFlag(cmd)
 	addConfigFlag(cmd)
 	addEnvironmentIDFlag(cmd, v)
 	addExcludeFlag(cmd, v)
 	addFormatFlag(cmd, v)
 	addIncludeFlag(cmd)
 	addInputTypeFlag(cmd, v)
+	addJobsFlag(cmd, v)
 	addNoBuiltInsFlag(cmd, v)
 	addNoConfigFlag(cmd)
 	addNoIgnoreFlag(cmd, v)
+	addNoInlineIgnoreFlag(cmd, v)
+	addOfflineFlag(cmd, v)
 	addOnlyFlag(cmd, v)
+	addOutputFlag(cmd, v)
 	addSeverityFlag(cmd, v)
 	addSyncFlag(cmd, v)
 	addUploadFlag(cmd)
 	addVarFileFlag(cmd, v)
+	addVerifyKeyFlag(cmd, v)
+	addWaiverFileFlag(cmd, v)
 	cmd.Flags().SetNormalizeFunc(normalizeFlag)
 	return cmd
 }
 
+// newRunConfig builds a runConfig from the configuration file and the flags
+// shared by commands that run rules.  CLI-only options are left for the
+// caller to fill in.
+func newRunConfig(cmd *cobra.Command, v *viper.Viper, args []string) (*runConfig, error) {
+	noConfig, err := cmd.Flags().GetBool(noConfigFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	// Config-file specific options
+	var rootDir string
+	var configPath string
+	if !noConfig {
+		configPath, err = cmd.Flags().GetString(configFlag)
+		if err != nil {
+			return nil, err
+		}
+		if err := loadConfigFile(configPath, v); err != nil {
+			return nil, err
+		}
+		if c := v.ConfigFileUsed(); c != "" {
+			rootDir = filepath.Dir(c)
+		}
+	}
+	// Inputs
+	configFileInputs := v.GetStringSlice(inputsFlag)
+	inputs, err := translateInputs(args, configFileInputs, rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Includes
+	cliIncludes, err := cmd.Flags().GetStringSlice(includeFlag)
+	if err != nil {
+		return nil, err
+	}
+	configFileIncludes := v.GetStringSlice(includeFlag)
+	includes, err := translateIncludes(cliIncludes, configFileIncludes, rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Baseline
+	baseline := v.GetString(baselineFlag)
+	if cmd.Flags().Changed(baselineFlag) && baseline != "" {
+		paths, err := translatePaths([]string{baseline}, rootDir)
+		if err != nil {
+			return nil, err
+		}
+		baseline = paths[0]
+	}
+
+	// Verify key
+	verifyKey := v.GetString(verifyKeyFlag)
+	if cmd.Flags().Changed(verifyKeyFlag) && verifyKey != "" {
+		paths, err := translatePaths([]string{verifyKey}, rootDir)
+		if err != nil {
+			return nil, err
+		}
+		verifyKey = paths[0]
+	}
+
+	// Waiver files
+	waiverFiles := v.GetStringSlice(waiverFileFlag)
+	if cmd.Flags().Changed(waiverFileFlag) {
+		waiverFiles, err = translatePaths(waiverFiles, rootDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Outputs
+	outputs, err := reporter.OutputsFromStrings(v.GetStringSlice(outputFlag))
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Flags().Changed(outputFlag) {
+		for i := range outputs {
+			paths, err := translatePaths([]string{outputs[i].Path}, rootDir)
+			if err != nil {
+				return nil, err
+			}
+			outputs[i].Path = paths[0]
+		}
+	}
+
+	// Enum types
+	inputTypeNames := v.GetStringSlice(inputTypeFlag)
+	inputTypes, err := loader.InputTypesFromStrings(inputTypeNames)
+	if err != nil {
+		return nil, err
+	}
+	format, err := reporter.FormatFromString(v.GetString(formatFlag))
+	if err != nil {
+		return nil, err
+	}
+	severity, err := reporter.SeverityFromString(v.GetString(severityFlag))
+	if err != nil {
+		return nil, err
+	}
+
+	return &runConfig{
+		baseline:       baseline,
+		cache:          v.GetBool(cacheFlag),
+		configPath:     configPath,
+		environmentId:  v.GetString(environmentIDFlag),
+		excludes:       v.GetStringSlice(excludeFlag),
+		format:         format,
+		includes:       includes,
+		inputs:         inputs,
+		inputTypes:     inputTypes,
+		jobs:           v.GetInt(jobsFlag),
+		noBuiltIns:     v.GetBool(noBuiltInsFlag),
+		noConfig:       noConfig,
+		noIgnore:       v.GetBool(noIgnoreFlag),
+		noInlineIgnore: v.GetBool(noInlineIgnoreFlag),
+		offline:        v.GetBool(offlineFlag),
+		only:           v.GetStringSlice(onlyFlag),
+		outputs:        outputs,
+		rootDir:        rootDir,
+		severity:       severity,
+		sync:           v.GetBool(syncFlag),
+		varFiles:       v.GetStringSlice(varFileFlag),
+		verifyKey:      verifyKey,
+		waiverFiles:    waiverFiles,
+	}, nil
+}
+
 func init() {
 	rootCmd.AddCommand(NewRunCommand())
 }
@@ -19,31 +19,48 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/fatih/color"
+	"github.com/fugue/regula/v3/pkg/baseline"
+	"github.com/fugue/regula/v3/pkg/fix"
 	"github.com/fugue/regula/v3/pkg/fugue"
+	"github.com/fugue/regula/v3/pkg/git"
 	"github.com/fugue/regula/v3/pkg/loader"
 	"github.com/fugue/regula/v3/pkg/rego"
 	"github.com/fugue/regula/v3/pkg/reporter"
+	"github.com/fugue/regula/v3/pkg/rule_waivers"
 	"github.com/sirupsen/logrus"
 )
 
 type runConfig struct {
-	configPath    string
-	environmentId string
-	excludes      []string
-	format        reporter.Format
-	includes      []string
-	inputs        []string
-	inputTypes    []loader.InputType
-	noBuiltIns    bool
-	noConfig      bool
-	noIgnore      bool
-	only          []string
-	rootDir       string
-	severity      reporter.Severity
-	sync          bool
-	upload        bool
-	varFiles      []string
+	baseline       string
+	cache          bool
+	changedSince   string
+	configPath     string
+	createBaseline bool
+	environmentId  string
+	excludes       []string
+	fix            bool
+	format         reporter.Format
+	includes       []string
+	inputs         []string
+	inputTypes     []loader.InputType
+	jobs           int
+	noBuiltIns     bool
+	noConfig       bool
+	noIgnore       bool
+	noInlineIgnore bool
+	offline        bool
+	only           []string
+	outputs        []reporter.Output
+	rootDir        string
+	severity       reporter.Severity
+	sync           bool
+	upload         bool
+	varFiles       []string
+	verifyKey      string
+	waiverFiles    []string
 }
 
 func (c *runConfig) Validate() error {
@@ -51,11 +68,18 @@ func (c *runConfig) Validate() error {
 		logrus.Warn("--sync takes precedence over options that modify the rule set (--excludes, --includes, --only, --no-built-ins). Those options will be ignored.")
 	}
 
+	if c.sync && c.offline {
+		return fmt.Errorf("--sync cannot be used with --offline, since it fetches rules from Fugue")
+	}
+
 	if c.sync && c.environmentId == "" {
 		return fmt.Errorf("--sync requires an environment-id to be set.")
 	}
 
 	if c.upload {
+		if c.changedSince != "" {
+			return fmt.Errorf("--changed-since cannot be used with --upload, since Fugue expects results for all configurations")
+		}
 		if c.rootDir == "" {
 			return fmt.Errorf("--upload requires a configuration file. The location of the configuration file is used to produce consistent relative filepaths in rule results.")
 		}
@@ -71,6 +95,15 @@ func (c *runConfig) Validate() error {
 }
 
 func (c *runConfig) Providers() ([]rego.RegoProvider, error) {
+	var verifyKey *rego.BundleKey
+	if c.verifyKey != "" {
+		key, err := rego.LoadBundleKey(c.verifyKey)
+		if err != nil {
+			return nil, err
+		}
+		verifyKey = key
+	}
+
 	if c.sync {
 		client, err := fugue.NewFugueClient()
 		if err != nil {
@@ -78,16 +111,24 @@ func (c *runConfig) Providers() ([]rego.RegoProvider, error) {
 		}
 		return []rego.RegoProvider{
 			rego.RegulaLibProvider(),
-			client.RuleBundleProvider(c.rootDir),
+			client.RuleBundleProvider(c.rootDir, verifyKey),
 			client.CustomRulesProvider(),
 			client.EnvironmentRegulaConfigProvider(c.environmentId),
 		}, nil
 	}
 
+	cacheDir, err := c.CacheDir()
+	if err != nil {
+		return nil, err
+	}
 	providers := []rego.RegoProvider{
 		rego.RegulaLibProvider(),
 		rego.RegulaConfigProvider(c.excludes, c.only),
-		rego.LocalProvider(c.includes),
+		rego.IncludesProvider(c.includes, rego.RemoteOptions{
+			CacheDir:  cacheDir,
+			Offline:   c.offline,
+			VerifyKey: verifyKey,
+		}),
 	}
 	if !c.noBuiltIns {
 		providers = append(providers, rego.RegulaRulesProvider())
@@ -96,18 +137,51 @@ func (c *runConfig) Providers() ([]rego.RegoProvider, error) {
 	return providers, nil
 }
 
+// CacheDir returns the absolute path of the directory used for the result
+// cache.  This should be called before changing to the root directory.
+func (c *runConfig) CacheDir() (string, error) {
+	return filepath.Abs(filepath.Join(c.rootDir, ".regula/cache"))
+}
+
+// ResultCache returns the cache to use for the given configurations, or nil
+// if caching is disabled.
+func (c *runConfig) ResultCache(cacheDir string, conf loader.LoadedConfigurations) *rego.ResultCache {
+	if !c.cache {
+		return nil
+	}
+	return rego.NewResultCache(cacheDir, loader.Fingerprints(conf, c.varFiles))
+}
+
 func (c *runConfig) ConfigurationLoader() (loader.ConfigurationLoader, error) {
 	inputTypes := c.inputTypes
 	if c.upload {
 		inputTypes = filterInputTypes(inputTypes)
 	}
 
-	return loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+	configLoader := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
 		Paths:       c.inputs,
 		InputTypes:  inputTypes,
 		NoGitIgnore: c.noIgnore,
 		VarFiles:    c.varFiles,
-	}), nil
+		Jobs:        c.jobs,
+	})
+	if c.changedSince == "" {
+		return configLoader, nil
+	}
+
+	return func() (loader.LoadedConfigurations, error) {
+		configs, err := configLoader()
+		if err != nil {
+			return nil, err
+		}
+		files, err := git.ChangedFiles(".", c.changedSince)
+		if err != nil {
+			return nil, err
+		}
+		changed := loader.ChangedConfigurations(configs, files)
+		logrus.Infof("%d of %d configurations changed since %s", changed.Count(), configs.Count(), c.changedSince)
+		return changed, nil
+	}, nil
 }
 
 func (c *runConfig) ResultProcessor() rego.RegoResultProcessor {
@@ -127,16 +201,16 @@ func (c *runConfig) ResultProcessor() rego.RegoResultProcessor {
 			if err := client.UploadScan(ctx, c.environmentId, *scanView); err != nil {
 				return err
 			}
-			reporter, err := reporter.GetReporter(c.format)
-			if err != nil {
+			// Fugue only knows about its own waivers, so inline ignores and
+			// waiver files are applied to the report after it is uploaded.
+			if err := c.applyLocalWaivers(conf, &scanView.Report); err != nil {
 				return err
 			}
-			reportStr, err := reporter(&scanView.Report)
-			if err != nil {
+			if err := c.applyBaseline(conf, &scanView.Report); err != nil {
 				return err
 			}
-			if reportStr != "" {
-				fmt.Print(reportStr)
+			if err := c.writeReport(&scanView.Report); err != nil {
+				return err
 			}
 			if scanView.Report.ExceedsSeverity(c.severity) {
 				return &ExceedsSeverityError{
@@ -162,16 +236,21 @@ func (c *runConfig) ResultProcessor() rego.RegoResultProcessor {
 				return err
 			}
 		}
-		reporter, err := reporter.GetReporter(c.format)
-		if err != nil {
+		if err := c.applyLocalWaivers(conf, report); err != nil {
 			return err
 		}
-		reportStr, err := reporter(report)
-		if err != nil {
+		if c.createBaseline {
+			return c.writeBaseline(conf, report)
+		}
+		if err := c.applyBaseline(conf, report); err != nil {
 			return err
 		}
-		if reportStr != "" {
-			fmt.Print(reportStr)
+		fix.AddSourceEdits(conf, report)
+		if c.fix {
+			return c.applyFixes(report)
+		}
+		if err := c.writeReport(report); err != nil {
+			return err
 		}
 		if report.ExceedsSeverity(c.severity) {
 			return &ExceedsSeverityError{
@@ -183,6 +262,160 @@ func (c *runConfig) ResultProcessor() rego.RegoResultProcessor {
 	}
 }
 
+// writeReport prints the report in the configured format and writes it to
+// each of the configured outputs.
+func (c *runConfig) writeReport(report *reporter.RegulaReport) error {
+	r, err := reporter.GetReporter(c.format)
+	if err != nil {
+		return err
+	}
+	reportStr, err := r(report)
+	if err != nil {
+		return err
+	}
+	if reportStr != "" {
+		fmt.Print(reportStr)
+	}
+
+	if len(c.outputs) < 1 {
+		return nil
+	}
+	// Terminal escape codes don't belong in files.
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+	for _, o := range c.outputs {
+		r, err := reporter.GetReporter(o.Format)
+		if err != nil {
+			return err
+		}
+		reportStr, err := r(report)
+		if err != nil {
+			return err
+		}
+		if dir := filepath.Dir(o.Path); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+		}
+		if err := os.WriteFile(o.Path, []byte(reportStr), 0644); err != nil {
+			return fmt.Errorf("Unable to write report to %s: %w", o.Path, err)
+		}
+		logrus.Infof("Wrote %s report to %s", reporter.FormatIDs[o.Format][0], o.Path)
+	}
+	return nil
+}
+
+// applyLocalWaivers applies inline ignores, unless those are disallowed, and
+// the unexpired waivers from all waiver files.
+func (c *runConfig) applyLocalWaivers(conf loader.LoadedConfigurations, report *reporter.RegulaReport) error {
+	if !c.noInlineIgnore {
+		rule_waivers.ApplyInlineIgnores(conf, report)
+	}
+	if len(c.waiverFiles) < 1 {
+		return nil
+	}
+	waivers := []rule_waivers.RuleWaiver{}
+	for _, path := range c.waiverFiles {
+		w, err := rule_waivers.LoadWaiverFile(path)
+		if err != nil {
+			return err
+		}
+		waivers = append(waivers, w...)
+	}
+	rule_waivers.ApplyRuleWaivers(conf, report, rule_waivers.Unexpired(waivers, time.Now()))
+	return nil
+}
+
+// applyBaseline marks the failures in the baseline, if one is configured.
+func (c *runConfig) applyBaseline(conf loader.LoadedConfigurations, report *reporter.RegulaReport) error {
+	if c.baseline == "" {
+		return nil
+	}
+	b, err := baseline.Load(c.baseline)
+	if err != nil {
+		return err
+	}
+	count := b.Apply(conf, report)
+	logrus.Infof("%d failures are in baseline %s", count, c.baseline)
+	return nil
+}
+
+func (c *runConfig) writeBaseline(conf loader.LoadedConfigurations, report *reporter.RegulaReport) error {
+	b := baseline.FromReport(conf, report)
+	if err := b.Write(c.baseline); err != nil {
+		return err
+	}
+	logrus.Infof("Wrote %d findings to baseline %s", len(b.Findings), c.baseline)
+	return nil
+}
+
+// applyFixes applies the suggested fixes for all failures that have them.
+func (c *runConfig) applyFixes(report *reporter.RegulaReport) error {
+	fixed, err := fix.Apply(report)
+	if err != nil {
+		return err
+	}
+	for _, r := range fixed {
+		rule := r.RuleID
+		if rule == "" {
+			rule = r.RuleName
+		}
+		fmt.Printf("Fixed %s: %s for %s in %s\n", rule, r.RuleSummary, r.ResourceID, r.SourceEdits[0].Path)
+	}
+	unfixed := report.Summary.RuleResults["FAIL"] - len(fixed)
+	logrus.Infof("Fixed %d failures, %d failures need to be fixed manually", len(fixed), unfixed)
+	return nil
+}
+
+// Execute loads the configurations, runs the rules and processes the result.
+func (c *runConfig) Execute() error {
+	// Interpret configuration
+	configLoader, err := c.ConfigurationLoader()
+	if err != nil {
+		return err
+	}
+	providers, err := c.Providers()
+	if err != nil {
+		return err
+	}
+	resultProcessor := c.ResultProcessor()
+	cacheDir, err := c.CacheDir()
+	if err != nil {
+		return err
+	}
+	if c.rootDir != "" {
+		// Changing directories is the easiest and most robust way to
+		// get all paths relative to the config file.
+		if err := os.Chdir(c.rootDir); err != nil {
+			// Not sure whether this error is possible here since we were
+			// able to load the file. But, just in case.
+			return fmt.Errorf("Unable to change to config file directory: %s", err)
+		}
+	}
+
+	// Execution
+	ctx := context.Background()
+	loadedConfigs, err := configLoader()
+	if err != nil {
+		return err
+	}
+	result, err := rego.RunRules(ctx, &rego.RunRulesOptions{
+		Providers: providers,
+		Input:     loadedConfigs.RegulaInput(),
+		Query:     c.RunRulesQuery(),
+		Jobs:      c.jobs,
+		Cache:     c.ResultCache(cacheDir, loadedConfigs),
+	})
+	if err != nil {
+		return err
+	}
+	if err := resultProcessor(ctx, loadedConfigs, result); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (c *runConfig) RunRulesQuery() string {
 	if c.upload {
 		return rego.SCAN_VIEW_QUERY
@@ -257,7 +490,20 @@ func translateInputs(cliInputs []string, configFileInputs []string, rootDir stri
 
 func translateIncludes(cliIncludes []string, configFileIncludes []string, rootDir string) ([]string, error) {
 	if len(cliIncludes) > 0 {
-		return translatePaths(cliIncludes, rootDir)
+		includes := make([]string, len(cliIncludes))
+		for i, include := range cliIncludes {
+			// Remote sources are not paths.
+			if rego.IsRemoteSource(include) {
+				includes[i] = include
+				continue
+			}
+			paths, err := translatePaths([]string{include}, rootDir)
+			if err != nil {
+				return nil, err
+			}
+			includes[i] = paths[0]
+		}
+		return includes, nil
 	}
 
 	return configFileIncludes, nil
@@ -41,6 +41,7 @@ func NewShowInputCommand() *cobra.Command {
 				Paths:      paths,
 				InputTypes: inputTypes,
 				VarFiles:   varFiles,
+				Jobs:       v.GetInt(jobsFlag),
 			})()
 			if err != nil {
 				return err
@@ -55,6 +56,7 @@ func NewShowInputCommand() *cobra.Command {
 	}
 
 	addInputTypeFlag(cmd, v)
+	addJobsFlag(cmd, v)
 	addVarFileFlag(cmd, v)
 	cmd.Flags().SetNormalizeFunc(normalizeFlag)
 	return cmd
@@ -0,0 +1,144 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package baseline records the failing rule results of a scan, so that those
+// pre-existing findings can be told apart from new ones in later scans.
+package baseline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/fugue/regula/v3/pkg/reporter"
+	"github.com/fugue/regula/v3/pkg/version"
+)
+
+// Finding identifies a failing rule result.  Source locations are
+// deliberately not part of this, so that unrelated edits to a file don't
+// affect which findings match.
+type Finding struct {
+	RuleID       string `json:"rule_id,omitempty"`
+	RuleName     string `json:"rule_name,omitempty"`
+	ResourceID   string `json:"resource_id"`
+	ResourceType string `json:"resource_type"`
+	// Filepath is the path of the configuration that contains the resource.
+	Filepath string `json:"filepath"`
+}
+
+type Baseline struct {
+	RegulaVersion string    `json:"regula_version"`
+	Findings      []Finding `json:"findings"`
+}
+
+// findingFor returns the finding for a rule result.  Rules are identified by
+// their ID, or by their name for custom rules that don't have one.
+func findingFor(configs loader.LoadedConfigurations, result reporter.RuleResult) Finding {
+	finding := Finding{
+		RuleID:       result.RuleID,
+		ResourceID:   result.ResourceID,
+		ResourceType: result.ResourceType,
+		Filepath:     result.Filepath,
+	}
+	if finding.RuleID == "" {
+		finding.RuleName = result.RuleName
+	}
+	if strp := configs.ConfigurationPath(result.Filepath); strp != nil {
+		finding.Filepath = *strp
+	}
+	return finding
+}
+
+// FromReport creates a baseline from all failing rule results in a report.
+func FromReport(configs loader.LoadedConfigurations, report *reporter.RegulaReport) *Baseline {
+	seen := map[Finding]struct{}{}
+	findings := []Finding{}
+	for _, result := range report.RuleResults {
+		if !result.IsFail() {
+			continue
+		}
+		finding := findingFor(configs, result)
+		if _, ok := seen[finding]; ok {
+			continue
+		}
+		seen[finding] = struct{}{}
+		findings = append(findings, finding)
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Filepath != b.Filepath {
+			return a.Filepath < b.Filepath
+		}
+		if a.ResourceID != b.ResourceID {
+			return a.ResourceID < b.ResourceID
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		return a.RuleName < b.RuleName
+	})
+	return &Baseline{
+		RegulaVersion: version.Version,
+		Findings:      findings,
+	}
+}
+
+// Load reads a baseline from a file.
+func Load(path string) (*Baseline, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read baseline: %w", err)
+	}
+	b := &Baseline{}
+	if err := json.Unmarshal(contents, b); err != nil {
+		return nil, fmt.Errorf("Unable to parse baseline %s: %w", path, err)
+	}
+	return b, nil
+}
+
+// Write writes the baseline to a file.
+func (b *Baseline) Write(path string) error {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// Apply changes every failing rule result in the report that is part of the
+// baseline to BASELINED, and returns the number of results that were changed.
+func (b *Baseline) Apply(configs loader.LoadedConfigurations, report *reporter.RegulaReport) int {
+	findings := map[Finding]struct{}{}
+	for _, f := range b.Findings {
+		findings[f] = struct{}{}
+	}
+	count := 0
+	for i := range report.RuleResults {
+		if !report.RuleResults[i].IsFail() {
+			continue
+		}
+		if _, ok := findings[findingFor(configs, report.RuleResults[i])]; ok {
+			report.RuleResults[i].RuleResult = "BASELINED"
+			count += 1
+		}
+	}
+	report.RecomputeSummary()
+	return count
+}
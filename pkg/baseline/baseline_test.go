@@ -0,0 +1,106 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baseline_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fugue/regula/v3/pkg/baseline"
+	"github.com/fugue/regula/v3/pkg/mocks"
+	"github.com/fugue/regula/v3/pkg/reporter"
+)
+
+func testReport() *reporter.RegulaReport {
+	return &reporter.RegulaReport{
+		RuleResults: []reporter.RuleResult{
+			{
+				Filepath:     "src/infra/main.tf",
+				ResourceID:   "aws_s3_bucket.logs",
+				ResourceType: "aws_s3_bucket",
+				RuleID:       "RULE_001",
+				RuleResult:   "FAIL",
+				RuleSeverity: "High",
+			},
+			{
+				Filepath:     "src/infra/main.tf",
+				ResourceID:   "aws_s3_bucket.logs",
+				ResourceType: "aws_s3_bucket",
+				RuleID:       "RULE_002",
+				RuleResult:   "PASS",
+				RuleSeverity: "Medium",
+			},
+			{
+				Filepath:     "src/other.yaml",
+				ResourceID:   "Bucket",
+				ResourceType: "AWS::S3::Bucket",
+				RuleName:     "my_custom_rule",
+				RuleResult:   "FAIL",
+				RuleSeverity: "Low",
+			},
+		},
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	configs := mocks.NewMockLoadedConfigurations(ctrl)
+	infra := "src/infra"
+	configs.EXPECT().ConfigurationPath("src/infra/main.tf").Return(&infra).AnyTimes()
+	configs.EXPECT().ConfigurationPath("src/other.yaml").Return(nil).AnyTimes()
+
+	report := testReport()
+	report.RecomputeSummary()
+	b := baseline.FromReport(configs, report)
+	assert.Equal(t, []baseline.Finding{
+		{
+			RuleID:       "RULE_001",
+			ResourceID:   "aws_s3_bucket.logs",
+			ResourceType: "aws_s3_bucket",
+			Filepath:     "src/infra",
+		},
+		{
+			RuleName:     "my_custom_rule",
+			ResourceID:   "Bucket",
+			ResourceType: "AWS::S3::Bucket",
+			Filepath:     "src/other.yaml",
+		},
+	}, b.Findings)
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	require.Nil(t, b.Write(path))
+	loaded, err := baseline.Load(path)
+	require.Nil(t, err)
+	assert.Equal(t, b, loaded)
+
+	// A new failure for a different rule on the same resource is not part of
+	// the baseline.
+	report = testReport()
+	report.RuleResults[1].RuleResult = "FAIL"
+	report.RecomputeSummary()
+	require.True(t, report.ExceedsSeverity(reporter.Medium))
+	assert.Equal(t, 2, loaded.Apply(configs, report))
+	assert.Equal(t, "BASELINED", report.RuleResults[0].RuleResult)
+	assert.Equal(t, "FAIL", report.RuleResults[1].RuleResult)
+	assert.Equal(t, "BASELINED", report.RuleResults[2].RuleResult)
+	assert.Equal(t, 1, report.Summary.RuleResults["FAIL"])
+	assert.Equal(t, 2, report.Summary.RuleResults["BASELINED"])
+	assert.True(t, report.ExceedsSeverity(reporter.Medium))
+	assert.False(t, report.ExceedsSeverity(reporter.High))
+}
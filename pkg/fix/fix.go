@@ -0,0 +1,284 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fix turns the fixes suggested by rules into edits to the IaC source
+// code, and applies those edits.
+package fix
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/fugue/regula/v3/pkg/reporter"
+	"github.com/sirupsen/logrus"
+)
+
+// editor computes the edit for a single fix to a resource.
+type editor interface {
+	edit(fix reporter.RuleFix) (reporter.SourceEdit, error)
+}
+
+// AddSourceEdits computes the source edits for the fixes of every failed rule
+// result.  Fixes that can't be expressed as source edits are skipped.
+func AddSourceEdits(conf loader.LoadedConfigurations, report *reporter.RegulaReport) {
+	for i := range report.RuleResults {
+		r := &report.RuleResults[i]
+		if !r.IsFail() || len(r.RuleFixes) < 1 {
+			continue
+		}
+		edits, err := Edits(conf, r)
+		if err != nil {
+			logrus.Debugf("Unable to fix %s for %s: %s", ruleName(r), r.ResourceID, err)
+			continue
+		}
+		r.SourceEdits = edits
+	}
+}
+
+// Edits returns the source edits for the fixes of a rule result.
+func Edits(conf loader.LoadedConfigurations, r *reporter.RuleResult) ([]reporter.SourceEdit, error) {
+	path := r.Filepath
+	loc, err := conf.Location(r.Filepath, []string{r.ResourceID})
+	if err != nil {
+		return nil, err
+	}
+	if len(loc) > 0 {
+		path = loc[0].Path
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var e editor
+	switch r.InputType {
+	case "tf":
+		if len(loc) < 1 {
+			return nil, fmt.Errorf("unknown source location")
+		}
+		e, err = newHCLEditor(path, contents, loc[0], r.ResourceID)
+	case "cfn":
+		// Resources in nested stacks are prefixed with the stack's ID.
+		logicalID := r.ResourceID[strings.LastIndex(r.ResourceID, ".")+1:]
+		e, err = newCfnEditor(path, contents, logicalID)
+	case "k8s":
+		e, err = newK8sEditor(path, contents, r.ResourceID)
+	default:
+		return nil, fmt.Errorf("fixes are not supported for %s input", r.InputType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	edits := []reporter.SourceEdit{}
+	for _, f := range r.RuleFixes {
+		edit, err := e.edit(f)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, edit)
+	}
+	return edits, nil
+}
+
+// Apply writes the source edits of all rule results to disk and returns the
+// rule results that were fixed.  A rule result is skipped if any of its edits
+// overlaps with an edit of an earlier rule result.
+func Apply(report *reporter.RegulaReport) ([]*reporter.RuleResult, error) {
+	fixed := []*reporter.RuleResult{}
+	accepted := map[string][]reporter.SourceEdit{}
+	for i := range report.RuleResults {
+		r := &report.RuleResults[i]
+		if len(r.SourceEdits) < 1 {
+			continue
+		}
+		if conflicts(accepted, r.SourceEdits) {
+			logrus.Warnf("Not fixing %s for %s since it overlaps with another fix. Run the command again to apply it.", ruleName(r), r.ResourceID)
+			continue
+		}
+		for _, e := range r.SourceEdits {
+			if !contains(accepted[e.Path], e) {
+				accepted[e.Path] = append(accepted[e.Path], e)
+			}
+		}
+		fixed = append(fixed, r)
+	}
+
+	paths := []string{}
+	for path := range accepted {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := applyEdits(path, accepted[path]); err != nil {
+			return nil, err
+		}
+	}
+	return fixed, nil
+}
+
+func applyEdits(path string, edits []reporter.SourceEdit) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].Start.Byte < edits[j].Start.Byte
+	})
+	result := []byte{}
+	cursor := 0
+	for _, e := range edits {
+		if e.Start.Byte < cursor || e.End.Byte > len(contents) {
+			return fmt.Errorf("Unable to fix %s: the file changed since it was evaluated", path)
+		}
+		result = append(result, contents[cursor:e.Start.Byte]...)
+		result = append(result, e.Text...)
+		cursor = e.End.Byte
+	}
+	result = append(result, contents[cursor:]...)
+	return os.WriteFile(path, result, info.Mode())
+}
+
+func conflicts(accepted map[string][]reporter.SourceEdit, edits []reporter.SourceEdit) bool {
+	for _, e := range edits {
+		for _, a := range accepted[e.Path] {
+			if a == e {
+				continue
+			}
+			if e.Start.Byte < a.End.Byte && a.Start.Byte < e.End.Byte {
+				return true
+			}
+			// Two different insertions at the same position would both add
+			// the same parent attributes.
+			if e.Start.Byte == a.Start.Byte && (e.Start.Byte == e.End.Byte || a.Start.Byte == a.End.Byte) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains(edits []reporter.SourceEdit, edit reporter.SourceEdit) bool {
+	for _, e := range edits {
+		if e == edit {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleName(r *reporter.RuleResult) string {
+	if r.RuleID != "" {
+		return r.RuleID
+	}
+	return r.RuleName
+}
+
+// pathIndex returns the list index for an attribute path element, which is a
+// number after decoding from JSON.
+func pathIndex(element interface{}) (int, bool) {
+	switch i := element.(type) {
+	case int:
+		return i, true
+	case float64:
+		if i == float64(int(i)) && i >= 0 {
+			return int(i), true
+		}
+	}
+	return 0, false
+}
+
+// source provides conversions between positions in a file.
+type source struct {
+	contents   []byte
+	lineStarts []int
+}
+
+func newSource(contents []byte) *source {
+	lineStarts := []int{0}
+	for i, c := range contents {
+		if c == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return &source{contents: contents, lineStarts: lineStarts}
+}
+
+// position converts a 1-based line and column, counted in characters, to a
+// position with a byte offset.
+func (s *source) position(line int, col int) reporter.SourcePosition {
+	offset := len(s.contents)
+	if line >= 1 && line <= len(s.lineStarts) {
+		offset = s.lineStarts[line-1]
+		for c := 1; c < col && offset < len(s.contents) && s.contents[offset] != '\n'; c++ {
+			_, size := utf8.DecodeRune(s.contents[offset:])
+			offset += size
+		}
+	}
+	return s.offset(offset)
+}
+
+// offset converts a byte offset to a position.
+func (s *source) offset(offset int) reporter.SourcePosition {
+	line := sort.Search(len(s.lineStarts), func(i int) bool {
+		return s.lineStarts[i] > offset
+	})
+	col := 1
+	for i := s.lineStarts[line-1]; i < offset; {
+		_, size := utf8.DecodeRune(s.contents[i:])
+		i += size
+		col++
+	}
+	return reporter.SourcePosition{Line: line, Col: col, Byte: offset}
+}
+
+// lineStart returns the offset of the start of the line containing offset.
+func (s *source) lineStart(offset int) int {
+	line := sort.Search(len(s.lineStarts), func(i int) bool {
+		return s.lineStarts[i] > offset
+	})
+	return s.lineStarts[line-1]
+}
+
+// indentation returns the leading whitespace of the line containing offset.
+func (s *source) indentation(offset int) string {
+	start := s.lineStart(offset)
+	end := start
+	for end < len(s.contents) && (s.contents[end] == ' ' || s.contents[end] == '\t') {
+		end++
+	}
+	return string(s.contents[start:end])
+}
+
+// firstOnLine checks whether only whitespace precedes offset on its line.
+func (s *source) firstOnLine(offset int) bool {
+	return len(s.indentation(offset)) == offset-s.lineStart(offset)
+}
+
+func (s *source) edit(path string, start int, end int, text string) reporter.SourceEdit {
+	return reporter.SourceEdit{
+		Path:  path,
+		Start: s.offset(start),
+		End:   s.offset(end),
+		Text:  text,
+	}
+}
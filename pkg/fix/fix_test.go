@@ -0,0 +1,267 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/fugue/regula/v3/pkg/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// applyFixes applies fixes using an editor and returns the new contents.
+func applyFixes(t *testing.T, e editor, contents string, fixes ...reporter.RuleFix) string {
+	edits := []reporter.SourceEdit{}
+	for _, f := range fixes {
+		edit, err := e.edit(f)
+		require.Nil(t, err)
+		edits = append(edits, edit)
+	}
+	path := filepath.Join(t.TempDir(), "input")
+	require.Nil(t, os.WriteFile(path, []byte(contents), 0644))
+	for i := range edits {
+		edits[i].Path = path
+	}
+	require.Nil(t, applyEdits(path, edits))
+	result, err := os.ReadFile(path)
+	require.Nil(t, err)
+	return string(result)
+}
+
+func TestHCLEditor(t *testing.T) {
+	contents := `resource "aws_s3_bucket" "b" {
+  bucket = "b"
+  acl    = "public-read"
+
+  logging {
+    target_bucket = "logs"
+  }
+}
+
+resource "aws_ebs_volume" "v" {}
+`
+	e, err := newHCLEditor("main.tf", []byte(contents), loader.Location{Path: "main.tf", Line: 1, Col: 1}, "aws_s3_bucket.b")
+	require.Nil(t, err)
+	assert.Equal(t, `resource "aws_s3_bucket" "b" {
+  bucket = "b"
+  acl    = "private"
+
+  logging {
+    target_bucket = "logs"
+    target_prefix = "b/"
+  }
+  versioning {
+    enabled = true
+  }
+}
+
+resource "aws_ebs_volume" "v" {}
+`, applyFixes(t, e, contents,
+		reporter.RuleFix{Attribute: []interface{}{"acl"}, Value: "private"},
+		reporter.RuleFix{Attribute: []interface{}{"logging", 0.0, "target_prefix"}, Value: "b/"},
+		reporter.RuleFix{Attribute: []interface{}{"versioning", 0.0, "enabled"}, Value: true},
+	))
+
+	e, err = newHCLEditor("main.tf", []byte(contents), loader.Location{Path: "main.tf", Line: 10, Col: 1}, "module.m.aws_ebs_volume.v[0]")
+	require.Nil(t, err)
+	assert.Contains(t, applyFixes(t, e, contents,
+		reporter.RuleFix{Attribute: []interface{}{"tags"}, Value: map[string]interface{}{"env": "prod"}},
+	), `resource "aws_ebs_volume" "v" {
+  tags = {
+    env = "prod"
+  }
+}
+`)
+
+	_, err = e.edit(reporter.RuleFix{Attribute: []interface{}{"ebs_block_device", 1.0, "encrypted"}, Value: true})
+	assert.NotNil(t, err)
+	_, err = newHCLEditor("main.tf", []byte(contents), loader.Location{Path: "main.tf", Line: 1, Col: 1}, "aws_s3_bucket.other")
+	assert.NotNil(t, err)
+}
+
+func TestCfnEditor(t *testing.T) {
+	contents := `Resources:
+  Volume:
+    Type: AWS::EC2::Volume
+    Properties:
+      Size: 10
+      Encrypted: "false"
+      Tags:
+`
+	e, err := newCfnEditor("template.yaml", []byte(contents), "Volume")
+	require.Nil(t, err)
+	assert.Equal(t, `Resources:
+  Volume:
+    Type: AWS::EC2::Volume
+    Properties:
+      KmsKeyId:
+        Ref: Key
+      Size: 10
+      Encrypted: true
+      Tags:
+        - Key: env
+          Value: prod
+`, applyFixes(t, e, contents,
+		reporter.RuleFix{Attribute: []interface{}{"Encrypted"}, Value: true},
+		reporter.RuleFix{Attribute: []interface{}{"KmsKeyId", "Ref"}, Value: "Key"},
+		reporter.RuleFix{Attribute: []interface{}{"Tags"}, Value: []interface{}{
+			map[string]interface{}{"Key": "env", "Value": "prod"},
+		}},
+	))
+
+	json := `{
+  "Resources": {
+    "Volume": {
+      "Type": "AWS::EC2::Volume",
+      "Properties": {
+        "Size": 10
+      }
+    }
+  }
+}
+`
+	e, err = newCfnEditor("template.json", []byte(json), "Volume")
+	require.Nil(t, err)
+	assert.Equal(t, `{
+  "Resources": {
+    "Volume": {
+      "Type": "AWS::EC2::Volume",
+      "Properties": {
+        "Encrypted": true,
+        "Size": 10
+      }
+    }
+  }
+}
+`, applyFixes(t, e, json, reporter.RuleFix{Attribute: []interface{}{"Encrypted"}, Value: true}))
+}
+
+func TestK8sEditor(t *testing.T) {
+	contents := `apiVersion: v1
+kind: Pod
+metadata:
+  name: other
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+  namespace: web
+spec:
+  containers:
+  - name: a # first
+    image: nginx
+`
+	e, err := newK8sEditor("pod.yaml", []byte(contents), "Pod.web.pod")
+	require.Nil(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Pod
+metadata:
+  name: other
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+  namespace: web
+spec:
+  containers:
+  - securityContext:
+      allowPrivilegeEscalation: false
+    name: a # first
+    image: nginx
+`, applyFixes(t, e, contents, reporter.RuleFix{
+		Attribute: []interface{}{"spec", "containers", 0.0, "securityContext", "allowPrivilegeEscalation"},
+		Value:     false,
+	}))
+
+	_, err = newK8sEditor("pod.yaml", []byte(contents), "Pod.default.pod")
+	assert.NotNil(t, err)
+}
+
+func TestK8sEditorList(t *testing.T) {
+	contents := `apiVersion: v1
+kind: PodList
+items:
+- metadata:
+    generateName: worker-
+  spec:
+    containers:
+    - name: a
+      image: nginx
+- metadata:
+    generateName: worker-
+  spec:
+    containers:
+    - name: b
+      image: nginx
+`
+	e, err := newK8sEditor("pods.yaml", []byte(contents), "Pod.default.worker-[1]")
+	require.Nil(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: PodList
+items:
+- metadata:
+    generateName: worker-
+  spec:
+    containers:
+    - name: a
+      image: nginx
+- metadata:
+    generateName: worker-
+  spec:
+    containers:
+    - securityContext:
+        allowPrivilegeEscalation: false
+      name: b
+      image: nginx
+`, applyFixes(t, e, contents, reporter.RuleFix{
+		Attribute: []interface{}{"spec", "containers", 0.0, "securityContext", "allowPrivilegeEscalation"},
+		Value:     false,
+	}))
+}
+
+func TestApply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.tf")
+	require.Nil(t, os.WriteFile(path, []byte("abcdef"), 0644))
+	edit := func(start int, end int, text string) reporter.SourceEdit {
+		return reporter.SourceEdit{
+			Path:  path,
+			Start: reporter.SourcePosition{Line: 1, Col: start + 1, Byte: start},
+			End:   reporter.SourcePosition{Line: 1, Col: end + 1, Byte: end},
+			Text:  text,
+		}
+	}
+	report := &reporter.RegulaReport{
+		RuleResults: []reporter.RuleResult{
+			{RuleID: "R1", SourceEdits: []reporter.SourceEdit{edit(1, 2, "B"), edit(4, 4, "-")}},
+			{RuleID: "R2"},
+			{RuleID: "R3", SourceEdits: []reporter.SourceEdit{edit(1, 3, "X")}},
+			{RuleID: "R4", SourceEdits: []reporter.SourceEdit{edit(5, 6, "F"), edit(1, 2, "B")}},
+		},
+	}
+	fixed, err := Apply(report)
+	require.Nil(t, err)
+	require.Len(t, fixed, 2)
+	assert.Equal(t, "R1", fixed[0].RuleID)
+	assert.Equal(t, "R4", fixed[1].RuleID)
+	contents, err := os.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, "aBcd-eF", string(contents))
+}
@@ -0,0 +1,184 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fix
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/fugue/regula/v3/pkg/reporter"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// hclEditor edits a resource block in a Terraform file.
+type hclEditor struct {
+	path   string
+	source *source
+	block  *hclsyntax.Block
+}
+
+// Matches the index of resources using count or for_each.
+var hclResourceIndex = regexp.MustCompile(`\[[^\]]*\]$`)
+
+func newHCLEditor(path string, contents []byte, loc loader.Location, resourceID string) (*hclEditor, error) {
+	if !strings.HasSuffix(path, ".tf") {
+		return nil, fmt.Errorf("only .tf files can be fixed")
+	}
+	file, diags := hclsyntax.ParseConfig(contents, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	parts := strings.Split(hclResourceIndex.ReplaceAllString(resourceID, ""), ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("unexpected resource ID %s", resourceID)
+	}
+	resourceType, resourceName := parts[len(parts)-2], parts[len(parts)-1]
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		r := block.Range()
+		if block.Type == "resource" && len(block.Labels) == 2 &&
+			block.Labels[0] == resourceType && block.Labels[1] == resourceName &&
+			r.Start.Line <= loc.Line && loc.Line <= r.End.Line {
+			return &hclEditor{
+				path:   path,
+				source: newSource(contents),
+				block:  block,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("resource block not found in %s", path)
+}
+
+func (e *hclEditor) edit(fix reporter.RuleFix) (reporter.SourceEdit, error) {
+	block := e.block
+	path := fix.Attribute
+	for i := 0; i < len(path); i++ {
+		name, ok := path[i].(string)
+		if !ok {
+			return reporter.SourceEdit{}, fmt.Errorf("unexpected index in attribute path %v", path)
+		}
+		if attr, ok := block.Body.Attributes[name]; ok {
+			if i < len(path)-1 {
+				return reporter.SourceEdit{}, fmt.Errorf("can't fix %v inside the value of %s", path, name)
+			}
+			r := attr.Expr.Range()
+			value, err := hclValue(fix.Value)
+			if err != nil {
+				return reporter.SourceEdit{}, err
+			}
+			text := indentLines(value, e.source.indentation(r.Start.Byte))
+			return e.source.edit(e.path, r.Start.Byte, r.End.Byte, text), nil
+		}
+
+		blocks := []*hclsyntax.Block{}
+		for _, b := range block.Body.Blocks {
+			if b.Type == name {
+				blocks = append(blocks, b)
+			}
+		}
+		if len(blocks) < 1 {
+			return e.insert(block, path[i:], fix.Value)
+		}
+		index := 0
+		if i+1 < len(path) {
+			if n, ok := pathIndex(path[i+1]); ok {
+				index = n
+				i++
+			}
+		}
+		if index >= len(blocks) {
+			return reporter.SourceEdit{}, fmt.Errorf("can't fix %v since there are only %d %s blocks", path, len(blocks), name)
+		}
+		block = blocks[index]
+	}
+	return reporter.SourceEdit{}, fmt.Errorf("attribute path %v refers to a block", path)
+}
+
+// insert adds the missing attributes and nested blocks to the end of a block.
+func (e *hclEditor) insert(block *hclsyntax.Block, path []interface{}, value interface{}) (reporter.SourceEdit, error) {
+	entry, err := hclEntry(path, value)
+	if err != nil {
+		return reporter.SourceEdit{}, err
+	}
+	blockIndent := e.source.indentation(block.TypeRange.Start.Byte)
+	indent := blockIndent + "  "
+	close := block.CloseBraceRange.Start.Byte
+	if block.CloseBraceRange.Start.Line > block.OpenBraceRange.Start.Line && e.source.firstOnLine(close) {
+		at := e.source.lineStart(close)
+		return e.source.edit(e.path, at, at, indent+indentLines(entry, indent)+"\n"), nil
+	}
+	text := "\n" + indent + indentLines(entry, indent) + "\n" + blockIndent
+	return e.source.edit(e.path, close, close, text), nil
+}
+
+// hclEntry renders an attribute or, for longer paths, nested blocks that set
+// the attribute at the end of the path.
+func hclEntry(path []interface{}, value interface{}) (string, error) {
+	name, ok := path[0].(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected index in attribute path %v", path)
+	}
+	rest := path[1:]
+	if len(rest) < 1 {
+		v, err := hclValue(value)
+		if err != nil {
+			return "", err
+		}
+		return name + " = " + v, nil
+	}
+	if index, ok := pathIndex(rest[0]); ok {
+		if index != 0 {
+			return "", fmt.Errorf("can't add %s block at index %d", name, index)
+		}
+		rest = rest[1:]
+		if len(rest) < 1 {
+			return "", fmt.Errorf("attribute path %v refers to a block", path)
+		}
+	}
+	inner, err := hclEntry(rest, value)
+	if err != nil {
+		return "", err
+	}
+	return name + " {\n  " + indentLines(inner, "  ") + "\n}", nil
+}
+
+// hclValue renders a value decoded from JSON as an HCL expression.
+func hclValue(value interface{}) (string, error) {
+	j, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	t, err := ctyjson.ImpliedType(j)
+	if err != nil {
+		return "", err
+	}
+	v, err := ctyjson.Unmarshal(j, t)
+	if err != nil {
+		return "", err
+	}
+	tokens := hclwrite.TokensForValue(v)
+	return strings.TrimSpace(string(hclwrite.Format(tokens.Bytes()))), nil
+}
+
+// indentLines indents all but the first line of text.
+func indentLines(text string, indent string) string {
+	return strings.ReplaceAll(text, "\n", "\n"+indent)
+}
@@ -0,0 +1,360 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/fugue/regula/v3/pkg/reporter"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlEditor edits a resource in a YAML or JSON file.  Fixes are inserted as
+// text so comments and formatting elsewhere in the file are kept.
+type yamlEditor struct {
+	path   string
+	source *source
+	root   *yaml.Node
+	// prefix is the path of the attributes that fixes refer to, relative to
+	// root.
+	prefix []interface{}
+}
+
+func newCfnEditor(path string, contents []byte, resourceID string) (*yamlEditor, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(contents, doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) < 1 {
+		return nil, fmt.Errorf("empty template")
+	}
+	_, resources := mappingEntry(doc.Content[0], "Resources")
+	if resources == nil || resources.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("template does not define resources")
+	}
+	_, resource := mappingEntry(resources, resourceID)
+	if resource == nil || resource.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("resource %s not found", resourceID)
+	}
+	return &yamlEditor{
+		path:   path,
+		source: newSource(contents),
+		root:   resource,
+		prefix: []interface{}{"Properties"},
+	}, nil
+}
+
+func newK8sEditor(path string, contents []byte, resourceID string) (*yamlEditor, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(contents))
+	ids := loader.NewK8sResourceIDs()
+	for {
+		doc := &yaml.Node{}
+		err := dec.Decode(doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(doc.Content) < 1 {
+			continue
+		}
+		var document interface{}
+		if err := doc.Decode(&document); err != nil {
+			return nil, err
+		}
+		if document == nil {
+			continue
+		}
+		objects, _ := ids.Objects(document, "")
+		for _, object := range objects {
+			if object.ID != resourceID {
+				continue
+			}
+			root, err := yamlNodeAt(doc.Content[0], object.Path)
+			if err != nil {
+				return nil, err
+			}
+			return &yamlEditor{
+				path:   path,
+				source: newSource(contents),
+				root:   root,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("resource %s not found", resourceID)
+}
+
+// yamlNodeAt returns the node at a path of mapping keys and sequence indices.
+func yamlNodeAt(node *yaml.Node, path []string) (*yaml.Node, error) {
+	for _, key := range path {
+		var child *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			_, child = mappingEntry(node, key)
+		case yaml.SequenceNode:
+			if idx, err := strconv.Atoi(key); err == nil && idx >= 0 && idx < len(node.Content) {
+				child = node.Content[idx]
+			}
+		}
+		if child == nil {
+			return nil, fmt.Errorf("%s not found", strings.Join(path, "."))
+		}
+		node = child
+	}
+	return node, nil
+}
+
+func (e *yamlEditor) edit(fix reporter.RuleFix) (reporter.SourceEdit, error) {
+	path := append(append([]interface{}{}, e.prefix...), fix.Attribute...)
+	node := e.root
+	flow := false
+	for i := 0; i < len(path); i++ {
+		flow = node.Style&yaml.FlowStyle != 0
+		switch node.Kind {
+		case yaml.MappingNode:
+			name, ok := path[i].(string)
+			if !ok {
+				return reporter.SourceEdit{}, fmt.Errorf("unexpected index in attribute path %v", path)
+			}
+			key, value := mappingEntry(node, name)
+			if key == nil {
+				return e.insert(node, path[i:], fix.Value)
+			}
+			if value.Kind == yaml.ScalarNode && value.Tag == "!!null" && (value.Value == "" || i < len(path)-1) {
+				return e.replaceEntry(key, value, flow, path[i:], fix.Value)
+			}
+			node = value
+		case yaml.SequenceNode:
+			index, ok := pathIndex(path[i])
+			if !ok || index >= len(node.Content) {
+				return reporter.SourceEdit{}, fmt.Errorf("can't fix %v since the list has %d elements", path, len(node.Content))
+			}
+			node = node.Content[index]
+		default:
+			return reporter.SourceEdit{}, fmt.Errorf("can't fix %v inside a scalar value", path)
+		}
+	}
+
+	if node.Kind != yaml.ScalarNode {
+		return reporter.SourceEdit{}, fmt.Errorf("can't fix %v since it is not a scalar value", path)
+	}
+	start := e.source.position(node.Line, node.Column).Byte
+	end, err := e.scalarEnd(start, node.Style, flow)
+	if err != nil {
+		return reporter.SourceEdit{}, err
+	}
+	text, err := yamlValue(fix.Value, flow)
+	if err != nil {
+		return reporter.SourceEdit{}, err
+	}
+	if !flow && strings.Contains(text, "\n") {
+		return reporter.SourceEdit{}, fmt.Errorf("can't replace %v with a collection", path)
+	}
+	return e.source.edit(e.path, start, end, text), nil
+}
+
+// insert adds an entry for the missing path in front of the first entry of a
+// mapping.
+func (e *yamlEditor) insert(mapping *yaml.Node, path []interface{}, value interface{}) (reporter.SourceEdit, error) {
+	flow := mapping.Style&yaml.FlowStyle != 0
+	if len(mapping.Content) < 1 {
+		if !flow {
+			return reporter.SourceEdit{}, fmt.Errorf("unexpected empty mapping")
+		}
+		entry, err := yamlEntry(path, value, true, "")
+		if err != nil {
+			return reporter.SourceEdit{}, err
+		}
+		start := e.source.position(mapping.Line, mapping.Column).Byte
+		end := bytes.IndexByte(e.source.contents[start:], '}')
+		if end < 0 {
+			return reporter.SourceEdit{}, fmt.Errorf("unexpected end of mapping")
+		}
+		return e.source.edit(e.path, start, start+end+1, "{"+entry+"}"), nil
+	}
+
+	first := mapping.Content[0]
+	at := e.source.position(first.Line, first.Column).Byte
+	indent := strings.Repeat(" ", first.Column-1)
+	if flow {
+		indent = e.source.indentation(at)
+	}
+	entry, err := yamlEntry(path, value, flow, indent)
+	if err != nil {
+		return reporter.SourceEdit{}, err
+	}
+	switch {
+	case !flow:
+		entry += "\n" + indent
+	case e.source.firstOnLine(at):
+		entry += ",\n" + indent
+	default:
+		entry += ", "
+	}
+	return e.source.edit(e.path, at, at, entry), nil
+}
+
+// replaceEntry replaces a mapping entry with a null value, like `key:`, with
+// an entry that sets the path.
+func (e *yamlEditor) replaceEntry(key *yaml.Node, value *yaml.Node, flow bool, path []interface{}, v interface{}) (reporter.SourceEdit, error) {
+	start := e.source.position(key.Line, key.Column).Byte
+	end, err := e.scalarEnd(start, key.Style, flow)
+	if err != nil {
+		return reporter.SourceEdit{}, err
+	}
+	colon := bytes.IndexByte(e.source.contents[end:], ':')
+	if colon < 0 {
+		return reporter.SourceEdit{}, fmt.Errorf("unexpected end of mapping")
+	}
+	end += colon + 1
+	if value.Value != "" {
+		valueStart := e.source.position(value.Line, value.Column).Byte
+		if end, err = e.scalarEnd(valueStart, value.Style, flow); err != nil {
+			return reporter.SourceEdit{}, err
+		}
+	}
+	indent := strings.Repeat(" ", key.Column-1)
+	if flow {
+		indent = e.source.indentation(start)
+	}
+	entry, err := yamlEntry(path, v, flow, indent)
+	if err != nil {
+		return reporter.SourceEdit{}, err
+	}
+	return e.source.edit(e.path, start, end, entry), nil
+}
+
+// scalarEnd finds the end offset of the scalar that starts at start.
+func (e *yamlEditor) scalarEnd(start int, style yaml.Style, flow bool) (int, error) {
+	src := e.source.contents
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		for i := start + 1; i < len(src); i++ {
+			if src[i] == '\\' {
+				i++
+			} else if src[i] == '"' {
+				return i + 1, nil
+			}
+		}
+	case style&yaml.SingleQuotedStyle != 0:
+		for i := start + 1; i < len(src); i++ {
+			if src[i] == '\'' {
+				if i+1 < len(src) && src[i+1] == '\'' {
+					i++
+				} else {
+					return i + 1, nil
+				}
+			}
+		}
+	case style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		return 0, fmt.Errorf("block scalars can't be fixed")
+	default:
+		end := start
+		for end < len(src) && src[end] != '\n' {
+			if flow && strings.IndexByte(",]}", src[end]) >= 0 {
+				break
+			}
+			if (src[end] == ' ' || src[end] == '\t') && end+1 < len(src) && src[end+1] == '#' {
+				break
+			}
+			if src[end] == ':' && (end+1 == len(src) || strings.IndexByte(" \t\r\n", src[end+1]) >= 0) {
+				break
+			}
+			end++
+		}
+		for end > start && strings.IndexByte(" \t\r", src[end-1]) >= 0 {
+			end--
+		}
+		return end, nil
+	}
+	return 0, fmt.Errorf("unterminated quoted scalar")
+}
+
+// yamlEntry renders a mapping entry that sets the value at the end of path.
+// Lines after the first are indented with indent.
+func yamlEntry(path []interface{}, value interface{}, flow bool, indent string) (string, error) {
+	key, ok := path[0].(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected index in attribute path %v", path)
+	}
+	nested, err := nestValue(path[1:], value)
+	if err != nil {
+		return "", err
+	}
+	if flow {
+		k, _ := json.Marshal(key)
+		v, err := yamlValue(nested, true)
+		if err != nil {
+			return "", err
+		}
+		return string(k) + ": " + v, nil
+	}
+	text, err := yamlValue(map[string]interface{}{key: nested}, false)
+	if err != nil {
+		return "", err
+	}
+	return indentLines(text, indent), nil
+}
+
+// nestValue builds the value for a path that does not exist yet.
+func nestValue(path []interface{}, value interface{}) (interface{}, error) {
+	for i := len(path) - 1; i >= 0; i-- {
+		switch p := path[i].(type) {
+		case string:
+			value = map[string]interface{}{p: value}
+		default:
+			if index, ok := pathIndex(p); !ok || index != 0 {
+				return nil, fmt.Errorf("can't add a list element at index %v", p)
+			}
+			value = []interface{}{value}
+		}
+	}
+	return value, nil
+}
+
+// yamlValue renders a value as JSON in flow style and as YAML otherwise.
+func yamlValue(value interface{}, flow bool) (string, error) {
+	if flow {
+		j, err := json.Marshal(value)
+		return string(j), err
+	}
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// mappingEntry returns the key and value nodes for a key in a mapping.
+func mappingEntry(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if mapping.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
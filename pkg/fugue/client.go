@@ -68,7 +68,7 @@ func getEnvWithDefault(name, defaultValue string) string {
 }
 
 type FugueClient interface {
-	RuleBundleProvider(rootDir string) rego.RegoProvider
+	RuleBundleProvider(rootDir string, verifyKey *rego.BundleKey) rego.RegoProvider
 	CustomRulesProvider() rego.RegoProvider
 	CustomRuleProvider(ruleID string) rego.RegoProvider
 	EnvironmentRegulaConfigProvider(environmentID string) rego.RegoProvider
@@ -108,7 +108,7 @@ func NewFugueClient() (FugueClient, error) {
 	}, nil
 }
 
-func (c *fugueClient) RuleBundleProvider(rootDir string) rego.RegoProvider {
+func (c *fugueClient) RuleBundleProvider(rootDir string, verifyKey *rego.BundleKey) rego.RegoProvider {
 	cacheDir := filepath.Join(rootDir, ".regula/cache")
 	bundlePath := filepath.Join(cacheDir, "bundle.tar.gz")
 
@@ -160,7 +160,7 @@ func (c *fugueClient) RuleBundleProvider(rootDir string) rego.RegoProvider {
 			}
 		}
 
-		return rego.TarGzProvider(bytes.NewReader(ruleBundle))(ctx, p)
+		return rego.TarGzProvider(bytes.NewReader(ruleBundle), verifyKey)(ctx, p)
 	}
 }
 
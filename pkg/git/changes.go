@@ -0,0 +1,107 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ChangedFiles returns the absolute paths of the files in the git repository
+// containing dir that changed since the given revision.  Like
+// `git diff <revision>...`, committed changes are compared against the merge
+// base of the revision and HEAD, so changes made on the revision's branch
+// since are not included.  Uncommitted changes and untracked files that are
+// not ignored are always included.  Deleted files are included as well.
+func ChangedFiles(dir string, revision string) ([]string, error) {
+	r, err := gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{
+		DetectDotGit: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to open git repository for %s: %w", dir, err)
+	}
+	worktree, err := r.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	baseHash, err := r.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to resolve git revision %s: %w", revision, err)
+	}
+	base, err := r.CommitObject(*baseHash)
+	if err != nil {
+		return nil, err
+	}
+	headRef, err := r.Head()
+	if err != nil {
+		return nil, err
+	}
+	head, err := r.CommitObject(headRef.Hash())
+	if err != nil {
+		return nil, err
+	}
+	mergeBases, err := base.MergeBase(head)
+	if err != nil {
+		return nil, err
+	}
+	if len(mergeBases) > 0 {
+		base = mergeBases[0]
+	}
+	baseTree, err := base.Tree()
+	if err != nil {
+		return nil, err
+	}
+	headTree, err := head.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(baseTree, headTree)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := map[string]struct{}{}
+	for _, c := range changes {
+		if c.From.Name != "" {
+			changed[c.From.Name] = struct{}{}
+		}
+		if c.To.Name != "" {
+			changed[c.To.Name] = struct{}{}
+		}
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+	for name, s := range status {
+		if s.Staging != gogit.Unmodified || s.Worktree != gogit.Unmodified {
+			changed[name] = struct{}{}
+		}
+	}
+
+	root := worktree.Filesystem.Root()
+	paths := []string{}
+	for name := range changed {
+		paths = append(paths, filepath.Join(root, filepath.FromSlash(name)))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
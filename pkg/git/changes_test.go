@@ -0,0 +1,94 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fugue/regula/v3/pkg/git"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	assert.NoError(t, err)
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	write := func(name string, contents string) {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+	}
+	commit := func(files map[string]string, removed ...string) {
+		for name, contents := range files {
+			write(name, contents)
+			_, err := worktree.Add(name)
+			assert.NoError(t, err)
+		}
+		for _, name := range removed {
+			_, err := worktree.Remove(name)
+			assert.NoError(t, err)
+		}
+		_, err := worktree.Commit("Update infra", &gogit.CommitOptions{
+			Author: &object.Signature{Name: "Regula", When: time.Now()},
+		})
+		assert.NoError(t, err)
+	}
+	checkout := func(branch string, create bool) {
+		assert.NoError(t, worktree.Checkout(&gogit.CheckoutOptions{
+			Branch: plumbing.NewBranchReferenceName(branch),
+			Create: create,
+		}))
+	}
+
+	commit(map[string]string{
+		".gitignore":         "*.log\n",
+		"infra/main.tf":      "# main",
+		"infra/network.tf":   "# network",
+		"infra/storage.tf":   "# storage",
+		"infra/unchanged.tf": "# unchanged",
+	})
+	checkout("feature", true)
+	commit(map[string]string{"infra/main.tf": "# main, changed"}, "infra/storage.tf")
+
+	// Changes on the base branch since the feature branch was created are
+	// not included.
+	checkout("master", false)
+	commit(map[string]string{"infra/dns.tf": "# dns"})
+	checkout("feature", false)
+
+	write("infra/network.tf", "# network, not committed")
+	write("infra/untracked.tf", "# untracked")
+	write("infra/debug.log", "ignored")
+
+	paths, err := git.ChangedFiles(filepath.Join(dir, "infra"), "master")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "infra", "main.tf"),
+		filepath.Join(dir, "infra", "network.tf"),
+		filepath.Join(dir, "infra", "storage.tf"),
+		filepath.Join(dir, "infra", "untracked.tf"),
+	}, paths)
+
+	_, err = git.ChangedFiles(dir, "missing")
+	assert.ErrorContains(t, err, "Unable to resolve git revision missing")
+}
@@ -17,6 +17,9 @@ package loader
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
 var validArmExts map[string]bool = map[string]bool{
@@ -38,17 +41,42 @@ func (c *ArmDetector) DetectFile(i InputFile, opts DetectOptions) (IACConfigurat
 	if err := json.Unmarshal(contents, &template.Contents); err != nil {
 		return nil, fmt.Errorf("Failed to parse file as JSON %v: %v", i.Path(), err)
 	}
-	_, hasSchema := template.Contents["$schema"]
+	schema, hasSchema := template.Contents["$schema"]
 	_, hasResources := template.Contents["resources"]
 
 	if !hasSchema && !hasResources {
 		return nil, fmt.Errorf("Input file is not an ARM template: %v", i.Path())
 	}
+	if schema, ok := schema.(string); ok && strings.Contains(schema, "deploymentParameters.json") {
+		return nil, fmt.Errorf("Input file is an ARM parameter file, not a template: %v", i.Path())
+	}
 	path := i.Path()
 
+	source, err := LoadSourceInfoNode(contents)
+	if err != nil {
+		source = nil
+	}
+
+	// Expressions are evaluated before the resources are passed to the
+	// resource view, and resources whose condition is false are left out.
+	// Nested resources and the resources of nested deployments are
+	// flattened into a single list.
+	loader := &armTemplateLoader{
+		resources: []interface{}{},
+		byID:      map[string]armResource{},
+		files:     []string{path},
+	}
+	if _, ok := template.Contents["resources"].([]interface{}); ok {
+		evaluator := newArmEvaluator(template.Contents, armParameterValues(opts.VarFiles))
+		loader.loadTemplate(evaluator, template.Contents, path, source, "", []string{path})
+		template.Contents["resources"] = loader.resources
+	}
+
 	return &armConfiguration{
-		path:     path,
-		template: *template,
+		path:      path,
+		template:  *template,
+		resources: loader.byID,
+		files:     loader.files,
 	}, nil
 }
 
@@ -57,9 +85,10 @@ func (c *ArmDetector) DetectDirectory(i InputDirectory, opts DetectOptions) (IAC
 }
 
 type armConfiguration struct {
-	path     string
-	template armTemplate
-	source   *SourceInfoNode
+	path      string
+	template  armTemplate
+	resources map[string]armResource
+	files     []string
 }
 
 func (l *armConfiguration) RegulaInput() RegulaInput {
@@ -69,40 +98,181 @@ func (l *armConfiguration) RegulaInput() RegulaInput {
 	}
 }
 
+// Location returns the location of a resource, followed by the locations of
+// the nested deployments that include it.
 func (l *armConfiguration) Location(path []string) (LocationStack, error) {
-	if l.source == nil || len(path) < 1 {
+	if len(path) < 1 {
+		return nil, nil
+	}
+	resource, ok := l.resources[path[0]]
+	if !ok {
 		return nil, nil
 	}
+	location := armResourceLocation(resource, path[1:])
+	if location == nil {
+		return nil, nil
+	}
+	if resource.deployment != "" {
+		deploymentLocation, err := l.Location([]string{resource.deployment})
+		if err != nil {
+			return nil, err
+		}
+		location = append(location, deploymentLocation...)
+	}
+	return location, nil
+}
+
+func armResourceLocation(arm armResource, attributePath []string) LocationStack {
+	if arm.source == nil {
+		return nil
+	}
 
-	resourcePath := []string{"Resources"}
-	resourcePath = append(resourcePath, path[0])
-	resource, err := l.source.GetPath(resourcePath)
+	// Resources live in (nested) arrays, so we find the array element that
+	// produced the resource.
+	resource, err := arm.source.GetPath(arm.path)
 	if err != nil {
-		return nil, nil
+		return nil
 	}
 	resourceLine, resourceColumn := resource.Location()
 	resourceLocation := Location{
-		Path: l.path,
+		Path: arm.file,
 		Line: resourceLine,
 		Col:  resourceColumn,
 	}
 
-	properties, err := resource.GetKey("Properties")
-	if err != nil {
-		return []Location{resourceLocation}, nil
+	if len(attributePath) < 1 {
+		return []Location{resourceLocation}
+	}
+
+	// Attribute paths normally include the "properties" key, since the
+	// resource view keeps resources as they are written, but we also accept
+	// paths relative to "properties".
+	if _, err := resource.GetKey(attributePath[0]); err != nil {
+		properties, err := resource.GetKey("properties")
+		if err != nil {
+			return []Location{resourceLocation}
+		}
+		resource = properties
 	}
 
-	attribute, err := properties.GetPath(path[1:])
-	if attribute != nil {
-		return []Location{resourceLocation}, nil
+	attribute, err := resource.GetPath(attributePath)
+	if err != nil {
+		return []Location{resourceLocation}
 	}
 
 	line, column := attribute.Location()
-	return []Location{{Path: l.path, Line: line, Col: column}}, nil
+	return []Location{{Path: arm.file, Line: line, Col: column}}
+}
+
+// InlineIgnores finds the annotations for a resource in its metadata, since
+// JSON does not support comments.  The value of the "regula:ignore" metadata
+// key is either a string or an array of strings, e.g.:
+//
+//     "metadata": {"regula:ignore": "FG_R00229 reason=\"Public website\""}
+func (l *armConfiguration) InlineIgnores(resourceID string) []InlineIgnore {
+	resource, ok := l.resources[resourceID]
+	if !ok {
+		return nil
+	}
+	metadata, ok := resource.resource["metadata"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	values := []interface{}{}
+	switch v := metadata["regula:ignore"].(type) {
+	case string:
+		values = append(values, v)
+	case []interface{}:
+		values = v
+	}
+	ignores := []InlineIgnore{}
+	for _, v := range values {
+		if str, ok := v.(string); ok {
+			ignores = append(ignores, parseInlineIgnores("regula:ignore "+str, Location{Path: resource.file})...)
+		}
+	}
+	return ignores
+}
+
+// armTypedName interleaves a type and a name, e.g.
+// Microsoft.Network/virtualNetworks/subnets and VNet1/Subnet1 become
+// Microsoft.Network/virtualNetworks/VNet1/subnets/Subnet1.
+func armTypedName(resourceType string, resourceName string) (string, bool) {
+	types := strings.Split(resourceType, "/")
+	names := strings.Split(resourceName, "/")
+	if len(types) < 2 || len(names) != len(types)-1 {
+		return "", false
+	}
+	parts := []string{types[0]}
+	for i, name := range names {
+		parts = append(parts, types[i+1], name)
+	}
+	return strings.Join(parts, "/"), true
+}
+
+// armParameterValues reads the values in the ARM parameter files passed in
+// with --var-file, indexed by their lowercased name.  Parameters that
+// reference a Key Vault secret are unknown.
+func armParameterValues(varFiles []string) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, varFile := range varFiles {
+		if parameters, ok := readArmParameterFile(varFile); ok {
+			armAddParameterValues(values, parameters, nil)
+		}
+	}
+	return values
+}
+
+// armAddParameterValues adds the values of parameters, which are written like
+// in a parameter file.  The values are evaluated if an evaluator is given.
+func armAddParameterValues(values map[string]interface{}, parameters map[string]interface{}, evaluator *armEvaluator) {
+	for name, parameter := range parameters {
+		p, _ := parameter.(map[string]interface{})
+		value, ok := p["value"]
+		if !ok {
+			values[strings.ToLower(name)] = armUnknown
+		} else if evaluator != nil {
+			values[strings.ToLower(name)] = evaluator.value(value)
+		} else {
+			values[strings.ToLower(name)] = value
+		}
+	}
+}
+
+// isArmParameterFile checks if a --var-file path is an ARM parameter file.
+func isArmParameterFile(path string) bool {
+	_, ok := readArmParameterFile(path)
+	return ok
+}
+
+// readArmParameterFile returns the parameters in an ARM parameter file, which
+// is recognized by its schema, or by its contentVersion if it doesn't have
+// one.
+func readArmParameterFile(path string) (map[string]interface{}, bool) {
+	if filepath.Ext(path) != ".json" {
+		return nil, false
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	file := map[string]interface{}{}
+	if err := json.Unmarshal(contents, &file); err != nil {
+		return nil, false
+	}
+	parameters, ok := file["parameters"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	if schema, ok := file["$schema"].(string); ok {
+		return parameters, strings.Contains(schema, "deploymentParameters.json")
+	}
+	_, hasContentVersion := file["contentVersion"]
+	return parameters, hasContentVersion && len(file) == 2
 }
 
 func (l *armConfiguration) LoadedFiles() []string {
-	return []string{l.path}
+	return l.files
 }
 
 type armTemplate struct {
@@ -0,0 +1,268 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const armDeploymentType = "Microsoft.Resources/deployments"
+
+// armResource is a resource in a template, together with the path of its
+// element in the (nested) resource arrays, e.g.
+// ["resources", "0", "resources", "2"], and the ID of the nested deployment
+// that includes it, if any.
+type armResource struct {
+	resource   map[string]interface{}
+	file       string
+	source     *SourceInfoNode
+	path       []string
+	deployment string
+}
+
+// armDeployment is a nested deployment that is expanded after the resources
+// of the template that includes it.
+type armDeployment struct {
+	id       string
+	resource map[string]interface{}
+	path     []string
+}
+
+// armTemplateLoader flattens the resources of a template into a single list.
+// Nested resources get their fully qualified type and name, and nested
+// deployments with an inline template or a linked template with a local path
+// are replaced by the resources of that template.
+type armTemplateLoader struct {
+	resources []interface{}
+	byID      map[string]armResource
+	files     []string
+}
+
+func (l *armTemplateLoader) loadTemplate(
+	evaluator *armEvaluator,
+	template map[string]interface{},
+	file string,
+	source *SourceInfoNode,
+	deployment string,
+	ancestors []string,
+) {
+	resources, ok := template["resources"].([]interface{})
+	if !ok {
+		return
+	}
+	evaluator.declareResources(resources, "", "")
+	deployments := []armDeployment{}
+	l.loadResources(evaluator, resources, "", "", file, source, []string{"resources"}, deployment, &deployments)
+	for _, d := range deployments {
+		l.loadDeployment(evaluator, d, file, source, ancestors)
+	}
+}
+
+// loadResources evaluates resources and their nested resources, leaving out
+// the ones whose condition is false, and indexes them by the IDs used in rule
+// results.  This mirrors `extract_resources` in fugue.resource_view.arm.
+func (l *armTemplateLoader) loadResources(
+	evaluator *armEvaluator,
+	resources []interface{},
+	parentType string,
+	parentName string,
+	file string,
+	source *SourceInfoNode,
+	path []string,
+	deployment string,
+	deployments *[]armDeployment,
+) {
+	for idx, r := range resources {
+		resource, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if evaluator.evaluate(resource["condition"]) == false {
+			continue
+		}
+		resourcePath := make([]string, len(path), len(path)+1)
+		copy(resourcePath, path)
+		resourcePath = append(resourcePath, strconv.Itoa(idx))
+
+		// The template of a nested deployment is evaluated in its own scope
+		// when it is expanded.
+		isDeployment := false
+		if resourceType, ok := evaluator.evaluate(resource["type"]).(string); ok {
+			isDeployment = parentType == "" && strings.EqualFold(resourceType, armDeploymentType)
+		}
+		evaluatedResource := map[string]interface{}{}
+		for k, v := range resource {
+			if k == "resources" {
+				continue
+			}
+			if properties, ok := v.(map[string]interface{}); ok && isDeployment && k == "properties" {
+				v = armWithout(properties, "template")
+			}
+			if k == "name" {
+				evaluatedResource[k] = evaluator.evaluateName(v)
+			} else {
+				evaluatedResource[k] = evaluator.evaluate(v)
+			}
+		}
+		resourceType, resourceName := armQualifiedTypeAndName(
+			evaluatedResource["type"],
+			evaluatedResource["name"],
+			parentType,
+			parentName,
+		)
+		if parentType != "" {
+			evaluatedResource["type"] = resourceType
+			evaluatedResource["name"] = resourceName
+		}
+		if id, ok := armTypedName(resourceType, resourceName); ok {
+			if _, exists := l.byID[id]; exists {
+				logrus.Warnf("%v: resource %v is defined more than once, skipping", file, id)
+				continue
+			}
+			l.byID[id] = armResource{
+				resource:   evaluatedResource,
+				file:       file,
+				source:     source,
+				path:       resourcePath,
+				deployment: deployment,
+			}
+			if isDeployment {
+				*deployments = append(*deployments, armDeployment{
+					id:       id,
+					resource: resource,
+					path:     resourcePath,
+				})
+			}
+		}
+		l.resources = append(l.resources, evaluatedResource)
+
+		if children, ok := resource["resources"].([]interface{}); ok {
+			childrenPath := append(resourcePath, "resources")
+			l.loadResources(evaluator, children, resourceType, resourceName, file, source, childrenPath, deployment, deployments)
+		}
+	}
+}
+
+// loadDeployment loads the template of a nested deployment.  Inline templates
+// are evaluated in the scope of the parent template, unless the deployment
+// asks for the inner scope.  Linked templates always have their own scope.
+func (l *armTemplateLoader) loadDeployment(
+	parent *armEvaluator,
+	d armDeployment,
+	file string,
+	source *SourceInfoNode,
+	ancestors []string,
+) {
+	properties, _ := d.resource["properties"].(map[string]interface{})
+	if template, ok := properties["template"].(map[string]interface{}); ok {
+		var templateSource *SourceInfoNode
+		if source != nil {
+			templateSource, _ = source.GetPath(append(d.path, "properties", "template"))
+		}
+		evaluator := parent
+		options, _ := properties["expressionEvaluationOptions"].(map[string]interface{})
+		if scope, ok := parent.evaluate(options["scope"]).(string); ok && strings.EqualFold(scope, "inner") {
+			evaluator = parent.nested(template, l.deploymentParameters(parent, properties, file))
+		}
+		l.loadTemplate(evaluator, template, file, templateSource, d.id, ancestors)
+		return
+	}
+
+	templateLink, _ := properties["templateLink"].(map[string]interface{})
+	templatePath, ok := armLocalPath(parent, templateLink, file)
+	if !ok {
+		return
+	}
+	for _, ancestor := range ancestors {
+		if ancestor == templatePath {
+			logrus.Warnf("Not loading nested deployment %v of %v, since it includes itself", d.id, file)
+			return
+		}
+	}
+	contents, err := os.ReadFile(templatePath)
+	if err != nil {
+		logrus.Warnf("Failed to load nested deployment %v of %v: %v", d.id, file, err)
+		return
+	}
+	template := map[string]interface{}{}
+	if err := json.Unmarshal(contents, &template); err != nil {
+		logrus.Warnf("Failed to parse nested deployment %v of %v: %v", d.id, file, err)
+		return
+	}
+	templateSource, err := LoadSourceInfoNode(contents)
+	if err != nil {
+		templateSource = nil
+	}
+	l.files = append(l.files, templatePath)
+	evaluator := parent.nested(template, l.deploymentParameters(parent, properties, file))
+	l.loadTemplate(evaluator, template, templatePath, templateSource, d.id, append(ancestors, templatePath))
+}
+
+// deploymentParameters returns the parameter values of a nested deployment,
+// which are either inline or in a linked parameter file with a local path.
+func (l *armTemplateLoader) deploymentParameters(
+	parent *armEvaluator,
+	properties map[string]interface{},
+	file string,
+) map[string]interface{} {
+	values := map[string]interface{}{}
+	parametersLink, _ := properties["parametersLink"].(map[string]interface{})
+	if parametersPath, ok := armLocalPath(parent, parametersLink, file); ok {
+		if parameters, ok := readArmParameterFile(parametersPath); ok {
+			l.files = append(l.files, parametersPath)
+			armAddParameterValues(values, parameters, nil)
+		} else {
+			logrus.Warnf("Failed to load ARM parameter file %v of %v", parametersPath, file)
+		}
+	}
+	if parameters, ok := properties["parameters"].(map[string]interface{}); ok {
+		armAddParameterValues(values, parameters, parent)
+	}
+	return values
+}
+
+// armLocalPath resolves the relativePath or uri of a template or parameters
+// link, relative to the template that contains it.  Remote links are not
+// loaded.
+func armLocalPath(evaluator *armEvaluator, link map[string]interface{}, file string) (string, bool) {
+	path, ok := evaluator.evaluate(link["relativePath"]).(string)
+	if !ok {
+		path, ok = evaluator.evaluate(link["uri"]).(string)
+	}
+	if !ok || path == "" || strings.Contains(path, "://") || strings.HasPrefix(path, "[") {
+		return "", false
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(file), filepath.FromSlash(path))
+	}
+	return path, true
+}
+
+// armWithout returns a copy of an object without the given key.
+func armWithout(object map[string]interface{}, key string) map[string]interface{} {
+	without := make(map[string]interface{}, len(object))
+	for k, v := range object {
+		if k != key {
+			without[k] = v
+		}
+	}
+	return without
+}
@@ -0,0 +1,1123 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// armUnknownValue is the result of an ARM template expression that can't be
+// evaluated before the template is deployed, e.g. `reference()`,
+// `subscription()` or a parameter without a value.
+type armUnknownValue struct{}
+
+var armUnknown = armUnknownValue{}
+
+// armUnknownMarker replaces an expression that can't be evaluated in the
+// resources we load, see unknownKey.
+func armUnknownMarker(expression string) map[string]interface{} {
+	return map[string]interface{}{unknownKey: expression}
+}
+
+// armIsUnknownMarker checks if a value is an unknown marker.
+func armIsUnknownMarker(value interface{}) bool {
+	marker, ok := value.(map[string]interface{})
+	if !ok || len(marker) != 1 {
+		return false
+	}
+	_, ok = marker[unknownKey].(string)
+	return ok
+}
+
+// armResourceRef is the result of `resourceId()` for a resource in the
+// template.  On its own, it is reported as the ID of that resource, like the
+// resource view does for references.  The actual value depends on the
+// deployment, so it is unknown when used in other expressions.
+type armResourceRef struct {
+	id string
+}
+
+// armKnown checks that values and their contents are fully known.
+func armKnown(values ...interface{}) bool {
+	for _, value := range values {
+		switch v := value.(type) {
+		case armUnknownValue, armResourceRef:
+			return false
+		case map[string]interface{}:
+			for _, child := range v {
+				if !armKnown(child) {
+					return false
+				}
+			}
+		case []interface{}:
+			if !armKnown(v...) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type armExpr interface{}
+
+type armLiteral struct {
+	value interface{}
+}
+
+type armCall struct {
+	name string
+	args []armExpr
+}
+
+type armProperty struct {
+	expr armExpr
+	name string
+}
+
+type armIndex struct {
+	expr  armExpr
+	index armExpr
+}
+
+// parseArmExpression parses the expression between the brackets of a
+// template expression, e.g. `concat('sa', parameters('name'))`.
+func parseArmExpression(input string) (armExpr, error) {
+	p := &armParser{input: input}
+	expr, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("Unexpected %q at position %d", p.input[p.pos:], p.pos)
+	}
+	return expr, nil
+}
+
+type armParser struct {
+	input string
+	pos   int
+}
+
+func (p *armParser) peek() byte {
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *armParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *armParser) expression() (armExpr, error) {
+	expr, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		switch p.peek() {
+		case '.':
+			p.pos++
+			p.skipSpace()
+			name := p.identifier()
+			if name == "" {
+				return nil, fmt.Errorf("Expected a property name at position %d", p.pos)
+			}
+			expr = armProperty{expr: expr, name: name}
+		case '[':
+			p.pos++
+			index, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+			if p.peek() != ']' {
+				return nil, fmt.Errorf("Expected ] at position %d", p.pos)
+			}
+			p.pos++
+			expr = armIndex{expr: expr, index: index}
+		default:
+			return expr, nil
+		}
+	}
+}
+
+func (p *armParser) primary() (armExpr, error) {
+	p.skipSpace()
+	c := p.peek()
+	if c == '\'' {
+		return p.string()
+	}
+	if c == '-' || (c >= '0' && c <= '9') {
+		return p.number()
+	}
+	name := p.identifier()
+	if name == "" {
+		return nil, fmt.Errorf("Unexpected %q at position %d", p.input[p.pos:], p.pos)
+	}
+	p.skipSpace()
+	if p.peek() != '(' {
+		return nil, fmt.Errorf("Expected ( after %s at position %d", name, p.pos)
+	}
+	p.pos++
+	call := armCall{name: strings.ToLower(name), args: []armExpr{}}
+	p.skipSpace()
+	if p.peek() == ')' {
+		p.pos++
+		return call, nil
+	}
+	for {
+		arg, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return call, nil
+		default:
+			return nil, fmt.Errorf("Expected , or ) at position %d", p.pos)
+		}
+	}
+}
+
+func (p *armParser) identifier() string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(p.pos > start && c >= '0' && c <= '9') {
+			p.pos++
+		} else {
+			break
+		}
+	}
+	return p.input[start:p.pos]
+}
+
+// string parses a string literal, in which quotes are escaped by doubling
+// them.
+func (p *armParser) string() (armExpr, error) {
+	p.pos++
+	builder := strings.Builder{}
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+		if c != '\'' {
+			builder.WriteByte(c)
+		} else if p.peek() == '\'' {
+			builder.WriteByte(c)
+			p.pos++
+		} else {
+			return armLiteral{value: builder.String()}, nil
+		}
+	}
+	return nil, fmt.Errorf("Unterminated string")
+}
+
+func (p *armParser) number() (armExpr, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.input) && (p.input[p.pos] == '.' || (p.input[p.pos] >= '0' && p.input[p.pos] <= '9')) {
+		p.pos++
+	}
+	value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if err != nil {
+		return nil, err
+	}
+	return armLiteral{value: value}, nil
+}
+
+// armEvaluator evaluates the expressions in an ARM template.  Parameters get
+// their value from the parameter files, or their default value, and
+// parameters and variables are evaluated when they are first used.
+type armEvaluator struct {
+	parameters  map[string]interface{}
+	variables   map[string]interface{}
+	values      map[string]interface{}
+	cache       map[string]interface{}
+	evaluating  map[string]bool
+	resourceIds map[string]string
+}
+
+// newArmEvaluator creates an evaluator for a template, given the parameter
+// values from the parameter files, indexed by their lowercased name.
+func newArmEvaluator(template map[string]interface{}, values map[string]interface{}) *armEvaluator {
+	e := &armEvaluator{
+		parameters:  map[string]interface{}{},
+		variables:   map[string]interface{}{},
+		values:      values,
+		cache:       map[string]interface{}{},
+		evaluating:  map[string]bool{},
+		resourceIds: map[string]string{},
+	}
+	// Names are case-insensitive.
+	if parameters, ok := template["parameters"].(map[string]interface{}); ok {
+		for name, parameter := range parameters {
+			e.parameters[strings.ToLower(name)] = parameter
+		}
+	}
+	if variables, ok := template["variables"].(map[string]interface{}); ok {
+		for name, variable := range variables {
+			e.variables[strings.ToLower(name)] = variable
+		}
+	}
+	return e
+}
+
+// nested creates an evaluator for a nested template in its own scope.  The
+// resources declared in either template can refer to each other.
+func (e *armEvaluator) nested(template map[string]interface{}, values map[string]interface{}) *armEvaluator {
+	nested := newArmEvaluator(template, values)
+	nested.resourceIds = e.resourceIds
+	return nested
+}
+
+// declareResources records the IDs of the resources in the template, so
+// `resourceId()` can refer to them.
+func (e *armEvaluator) declareResources(resources []interface{}, parentType string, parentName string) {
+	for _, r := range resources {
+		resource, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		resourceType, resourceName := armQualifiedTypeAndName(
+			e.evaluate(resource["type"]),
+			e.evaluateName(resource["name"]),
+			parentType,
+			parentName,
+		)
+		if id, ok := armTypedName(resourceType, resourceName); ok {
+			e.resourceIds[strings.ToLower(id)] = id
+		}
+		if children, ok := resource["resources"].([]interface{}); ok {
+			e.declareResources(children, resourceType, resourceName)
+		}
+	}
+}
+
+// evaluate evaluates the expressions in a template value.  Expressions that
+// can't be evaluated are replaced with an unknown marker.
+func (e *armEvaluator) evaluate(raw interface{}) interface{} {
+	switch v := raw.(type) {
+	case string:
+		value := e.expression(v)
+		if ref, ok := value.(armResourceRef); ok {
+			return ref.id
+		}
+		if !armKnown(value) {
+			return armUnknownMarker(v)
+		}
+		return value
+	case map[string]interface{}:
+		evaluated := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			evaluated[k] = e.evaluate(child)
+		}
+		return evaluated
+	case []interface{}:
+		evaluated := make([]interface{}, len(v))
+		for i, child := range v {
+			evaluated[i] = e.evaluate(child)
+		}
+		return evaluated
+	default:
+		return raw
+	}
+}
+
+// evaluateName evaluates the name of a resource.  Since resources are
+// identified by their name, a name that can't be evaluated is kept as it is
+// written in the template.
+func (e *armEvaluator) evaluateName(raw interface{}) interface{} {
+	value := e.evaluate(raw)
+	if armIsUnknownMarker(value) {
+		return raw
+	}
+	return value
+}
+
+// value evaluates a template value like evaluate, but keeps track of the
+// parts that are unknown.
+func (e *armEvaluator) value(raw interface{}) interface{} {
+	switch v := raw.(type) {
+	case string:
+		return e.expression(v)
+	case map[string]interface{}:
+		evaluated := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			evaluated[k] = e.value(child)
+		}
+		return evaluated
+	case []interface{}:
+		evaluated := make([]interface{}, len(v))
+		for i, child := range v {
+			evaluated[i] = e.value(child)
+		}
+		return evaluated
+	default:
+		return raw
+	}
+}
+
+// expression evaluates a string, which is an expression if it is enclosed
+// in brackets.  A leading "[[" escapes the bracket.
+func (e *armEvaluator) expression(s string) interface{} {
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+		return s
+	}
+	if strings.HasPrefix(s, "[[") {
+		return s[1:]
+	}
+	expr, err := parseArmExpression(s[1 : len(s)-1])
+	if err != nil {
+		return armUnknown
+	}
+	return e.eval(expr)
+}
+
+func (e *armEvaluator) eval(expr armExpr) interface{} {
+	switch x := expr.(type) {
+	case armLiteral:
+		return x.value
+	case armProperty:
+		if object, ok := e.eval(x.expr).(map[string]interface{}); ok {
+			return armLookup(object, x.name)
+		}
+	case armIndex:
+		target := e.eval(x.expr)
+		index := e.eval(x.index)
+		switch t := target.(type) {
+		case map[string]interface{}:
+			if key, ok := index.(string); ok {
+				return armLookup(t, key)
+			}
+		case []interface{}:
+			if i, ok := armInt(index); ok && i >= 0 && i < len(t) {
+				return t[i]
+			}
+		}
+	case armCall:
+		return e.call(x)
+	}
+	return armUnknown
+}
+
+func (e *armEvaluator) call(call armCall) interface{} {
+	// These functions only evaluate the arguments they need.
+	switch call.name {
+	case "if":
+		if len(call.args) != 3 {
+			return armUnknown
+		}
+		condition, ok := e.eval(call.args[0]).(bool)
+		if !ok {
+			return armUnknown
+		}
+		if condition {
+			return e.eval(call.args[1])
+		}
+		return e.eval(call.args[2])
+	case "coalesce":
+		for _, arg := range call.args {
+			value := e.eval(arg)
+			if !armKnown(value) {
+				return armUnknown
+			}
+			if value != nil {
+				return value
+			}
+		}
+		return nil
+	}
+
+	args := make([]interface{}, len(call.args))
+	for i, arg := range call.args {
+		args[i] = e.eval(arg)
+		if !armKnown(args[i]) {
+			return armUnknown
+		}
+	}
+	switch call.name {
+	case "parameters":
+		if len(args) == 1 {
+			if name, ok := args[0].(string); ok {
+				return e.parameter(name)
+			}
+		}
+		return armUnknown
+	case "variables":
+		if len(args) == 1 {
+			if name, ok := args[0].(string); ok {
+				return e.variable(name)
+			}
+		}
+		return armUnknown
+	case "resourceid":
+		return e.resourceId(args)
+	}
+	if f, ok := armFunctions[call.name]; ok {
+		if value, ok := f(args); ok {
+			return value
+		}
+	}
+	return armUnknown
+}
+
+func (e *armEvaluator) parameter(name string) interface{} {
+	key := strings.ToLower(name)
+	parameter, ok := e.parameters[key].(map[string]interface{})
+	if !ok {
+		return armUnknown
+	}
+	if value, ok := e.values[key]; ok {
+		return value
+	}
+	defaultValue, ok := parameter["defaultValue"]
+	if !ok {
+		return armUnknown
+	}
+	return e.lazy("parameters:"+key, defaultValue)
+}
+
+func (e *armEvaluator) variable(name string) interface{} {
+	key := strings.ToLower(name)
+	variable, ok := e.variables[key]
+	if !ok {
+		return armUnknown
+	}
+	return e.lazy("variables:"+key, variable)
+}
+
+// lazy evaluates a parameter default or a variable once.  Values that refer
+// to themselves are unknown.
+func (e *armEvaluator) lazy(key string, raw interface{}) interface{} {
+	if value, ok := e.cache[key]; ok {
+		return value
+	}
+	if e.evaluating[key] {
+		return armUnknown
+	}
+	e.evaluating[key] = true
+	value := e.value(raw)
+	delete(e.evaluating, key)
+	e.cache[key] = value
+	return value
+}
+
+// resourceId refers to resources in the template by their type and names.
+// This is an approximation: rather than the full Azure ID, e.g.
+// `/subscriptions/.../providers/Microsoft.Network/virtualNetworks/vnet`, it
+// returns the shortened ID of the resource in the resource view, e.g.
+// `Microsoft.Network/virtualNetworks/vnet`, so rules can follow the
+// reference.  The result is therefore unknown when it is used in other
+// expressions, see armResourceRef.  Resources in other subscriptions or
+// resource groups, or that aren't in the template, are unknown.
+func (e *armEvaluator) resourceId(args []interface{}) interface{} {
+	if len(args) < 2 {
+		return armUnknown
+	}
+	strs, ok := armStrings(args)
+	if !ok {
+		return armUnknown
+	}
+	resourceType := strings.Trim(strs[0], "/")
+	id, ok := armTypedName(resourceType, strings.Join(strs[1:], "/"))
+	if !ok {
+		return armUnknown
+	}
+	if declared, ok := e.resourceIds[strings.ToLower(id)]; ok {
+		return armResourceRef{id: declared}
+	}
+	return armUnknown
+}
+
+// armQualifiedTypeAndName joins the type and name of a nested resource with
+// the ones of its parent.
+func armQualifiedTypeAndName(resourceType interface{}, resourceName interface{}, parentType string, parentName string) (string, string) {
+	t, _ := resourceType.(string)
+	n, _ := resourceName.(string)
+	if parentType != "" {
+		t = parentType + "/" + t
+		n = parentName + "/" + n
+	}
+	return t, n
+}
+
+// armLookup looks up a property, which is case-insensitive.
+func armLookup(object map[string]interface{}, name string) interface{} {
+	if value, ok := object[name]; ok {
+		return value
+	}
+	for key, value := range object {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return armUnknown
+}
+
+func armInt(value interface{}) (int, bool) {
+	f, ok := value.(float64)
+	if !ok || f != math.Trunc(f) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+func armStrings(args []interface{}) ([]string, bool) {
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		str, ok := arg.(string)
+		if !ok {
+			return nil, false
+		}
+		strs[i] = str
+	}
+	return strs, true
+}
+
+func armNumbers(args []interface{}) ([]float64, bool) {
+	numbers := make([]float64, len(args))
+	for i, arg := range args {
+		number, ok := arg.(float64)
+		if !ok {
+			return nil, false
+		}
+		numbers[i] = number
+	}
+	return numbers, true
+}
+
+// armToString converts a value to a string like `string()` does.
+func armToString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		if v {
+			return "True", true
+		}
+		return "False", true
+	case map[string]interface{}, []interface{}:
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(bytes), true
+	}
+	return "", false
+}
+
+// armCompare compares two numbers or two strings.
+func armCompare(args []interface{}) (int, bool) {
+	if len(args) != 2 {
+		return 0, false
+	}
+	if numbers, ok := armNumbers(args); ok {
+		switch {
+		case numbers[0] < numbers[1]:
+			return -1, true
+		case numbers[0] > numbers[1]:
+			return 1, true
+		}
+		return 0, true
+	}
+	if strs, ok := armStrings(args); ok {
+		return strings.Compare(strs[0], strs[1]), true
+	}
+	return 0, false
+}
+
+func armStringFunction(f func(string) interface{}) func([]interface{}) (interface{}, bool) {
+	return func(args []interface{}) (interface{}, bool) {
+		if len(args) != 1 {
+			return nil, false
+		}
+		str, ok := args[0].(string)
+		if !ok {
+			return nil, false
+		}
+		return f(str), true
+	}
+}
+
+func armComparison(f func(int) bool) func([]interface{}) (interface{}, bool) {
+	return func(args []interface{}) (interface{}, bool) {
+		cmp, ok := armCompare(args)
+		if !ok {
+			return nil, false
+		}
+		return f(cmp), true
+	}
+}
+
+func armArithmetic(f func(float64, float64) (float64, bool)) func([]interface{}) (interface{}, bool) {
+	return func(args []interface{}) (interface{}, bool) {
+		numbers, ok := armNumbers(args)
+		if !ok || len(numbers) != 2 {
+			return nil, false
+		}
+		return f(numbers[0], numbers[1])
+	}
+}
+
+func armBooleans(f func([]bool) bool) func([]interface{}) (interface{}, bool) {
+	return func(args []interface{}) (interface{}, bool) {
+		bools := make([]bool, len(args))
+		for i, arg := range args {
+			b, ok := arg.(bool)
+			if !ok {
+				return nil, false
+			}
+			bools[i] = b
+		}
+		return f(bools), true
+	}
+}
+
+var armFormatItem = strings.NewReplacer("{{", "{", "}}", "}")
+
+// armFormat implements `format()` for the format items without a format
+// string, e.g. `{0}`.
+func armFormat(args []interface{}) (interface{}, bool) {
+	if len(args) < 1 {
+		return nil, false
+	}
+	format, ok := args[0].(string)
+	if !ok {
+		return nil, false
+	}
+	builder := strings.Builder{}
+	for len(format) > 0 {
+		start := strings.IndexAny(format, "{}")
+		if start < 0 {
+			builder.WriteString(format)
+			break
+		}
+		builder.WriteString(format[:start])
+		if strings.HasPrefix(format[start:], "{{") || strings.HasPrefix(format[start:], "}}") {
+			builder.WriteString(armFormatItem.Replace(format[start : start+2]))
+			format = format[start+2:]
+			continue
+		}
+		end := strings.Index(format[start:], "}")
+		if format[start] != '{' || end < 0 {
+			return nil, false
+		}
+		index, err := strconv.Atoi(format[start+1 : start+end])
+		if err != nil || index < 0 || index+1 >= len(args) {
+			return nil, false
+		}
+		str, ok := armToString(args[index+1])
+		if !ok {
+			return nil, false
+		}
+		builder.WriteString(str)
+		format = format[start+end+1:]
+	}
+	return builder.String(), true
+}
+
+// armFunctions are the template functions that can be evaluated without
+// deploying the template.  They return false if their arguments are not
+// valid.
+var armFunctions = map[string]func(args []interface{}) (interface{}, bool){
+	"concat": func(args []interface{}) (interface{}, bool) {
+		if strs, ok := armStrings(args); ok {
+			return strings.Join(strs, ""), true
+		}
+		concatenated := []interface{}{}
+		for _, arg := range args {
+			arr, ok := arg.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			concatenated = append(concatenated, arr...)
+		}
+		return concatenated, true
+	},
+	"format":  armFormat,
+	"tolower": armStringFunction(func(s string) interface{} { return strings.ToLower(s) }),
+	"toupper": armStringFunction(func(s string) interface{} { return strings.ToUpper(s) }),
+	"trim":    armStringFunction(func(s string) interface{} { return strings.TrimSpace(s) }),
+	"replace": func(args []interface{}) (interface{}, bool) {
+		strs, ok := armStrings(args)
+		if !ok || len(strs) != 3 {
+			return nil, false
+		}
+		return strings.ReplaceAll(strs[0], strs[1], strs[2]), true
+	},
+	"substring": func(args []interface{}) (interface{}, bool) {
+		if len(args) < 2 || len(args) > 3 {
+			return nil, false
+		}
+		str, ok := args[0].(string)
+		start, startOk := armInt(args[1])
+		if !ok || !startOk || start < 0 || start > len(str) {
+			return nil, false
+		}
+		end := len(str)
+		if len(args) == 3 {
+			length, ok := armInt(args[2])
+			if !ok || length < 0 || start+length > len(str) {
+				return nil, false
+			}
+			end = start + length
+		}
+		return str[start:end], true
+	},
+	"split": func(args []interface{}) (interface{}, bool) {
+		if len(args) != 2 {
+			return nil, false
+		}
+		str, ok := args[0].(string)
+		if !ok {
+			return nil, false
+		}
+		delimiters := []string{}
+		switch d := args[1].(type) {
+		case string:
+			delimiters = append(delimiters, d)
+		case []interface{}:
+			strs, ok := armStrings(d)
+			if !ok {
+				return nil, false
+			}
+			delimiters = strs
+		default:
+			return nil, false
+		}
+		parts := []string{str}
+		for _, delimiter := range delimiters {
+			split := []string{}
+			for _, part := range parts {
+				split = append(split, strings.Split(part, delimiter)...)
+			}
+			parts = split
+		}
+		result := make([]interface{}, len(parts))
+		for i, part := range parts {
+			result[i] = part
+		}
+		return result, true
+	},
+	"startswith": func(args []interface{}) (interface{}, bool) {
+		strs, ok := armStrings(args)
+		if !ok || len(strs) != 2 {
+			return nil, false
+		}
+		return strings.HasPrefix(strings.ToLower(strs[0]), strings.ToLower(strs[1])), true
+	},
+	"endswith": func(args []interface{}) (interface{}, bool) {
+		strs, ok := armStrings(args)
+		if !ok || len(strs) != 2 {
+			return nil, false
+		}
+		return strings.HasSuffix(strings.ToLower(strs[0]), strings.ToLower(strs[1])), true
+	},
+	"contains": func(args []interface{}) (interface{}, bool) {
+		if len(args) != 2 {
+			return nil, false
+		}
+		switch container := args[0].(type) {
+		case string:
+			item, ok := armToString(args[1])
+			return strings.Contains(container, item), ok
+		case []interface{}:
+			for _, element := range container {
+				if reflect.DeepEqual(element, args[1]) {
+					return true, true
+				}
+			}
+			return false, true
+		case map[string]interface{}:
+			key, ok := args[1].(string)
+			return armLookup(container, key) != armUnknown, ok
+		}
+		return nil, false
+	},
+	"length": func(args []interface{}) (interface{}, bool) {
+		if len(args) != 1 {
+			return nil, false
+		}
+		switch v := args[0].(type) {
+		case string:
+			return float64(len(v)), true
+		case []interface{}:
+			return float64(len(v)), true
+		case map[string]interface{}:
+			return float64(len(v)), true
+		}
+		return nil, false
+	},
+	"empty": func(args []interface{}) (interface{}, bool) {
+		if len(args) != 1 {
+			return nil, false
+		}
+		switch v := args[0].(type) {
+		case nil:
+			return true, true
+		case string:
+			return len(v) == 0, true
+		case []interface{}:
+			return len(v) == 0, true
+		case map[string]interface{}:
+			return len(v) == 0, true
+		}
+		return nil, false
+	},
+	"first": func(args []interface{}) (interface{}, bool) {
+		if len(args) != 1 {
+			return nil, false
+		}
+		switch v := args[0].(type) {
+		case string:
+			if len(v) > 0 {
+				return v[:1], true
+			}
+			return "", true
+		case []interface{}:
+			if len(v) > 0 {
+				return v[0], true
+			}
+		}
+		return nil, false
+	},
+	"last": func(args []interface{}) (interface{}, bool) {
+		if len(args) != 1 {
+			return nil, false
+		}
+		switch v := args[0].(type) {
+		case string:
+			if len(v) > 0 {
+				return v[len(v)-1:], true
+			}
+			return "", true
+		case []interface{}:
+			if len(v) > 0 {
+				return v[len(v)-1], true
+			}
+		}
+		return nil, false
+	},
+	"string": func(args []interface{}) (interface{}, bool) {
+		if len(args) != 1 {
+			return nil, false
+		}
+		return armToString(args[0])
+	},
+	"int": func(args []interface{}) (interface{}, bool) {
+		if len(args) != 1 {
+			return nil, false
+		}
+		switch v := args[0].(type) {
+		case float64:
+			return math.Trunc(v), true
+		case string:
+			i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			return float64(i), err == nil
+		}
+		return nil, false
+	},
+	"bool": func(args []interface{}) (interface{}, bool) {
+		if len(args) != 1 {
+			return nil, false
+		}
+		switch v := args[0].(type) {
+		case bool:
+			return v, true
+		case float64:
+			return v != 0, true
+		case string:
+			switch strings.ToLower(v) {
+			case "true":
+				return true, true
+			case "false":
+				return false, true
+			}
+		}
+		return nil, false
+	},
+	"json": func(args []interface{}) (interface{}, bool) {
+		if len(args) != 1 {
+			return nil, false
+		}
+		str, ok := args[0].(string)
+		if !ok {
+			return nil, false
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(str), &value); err != nil {
+			return nil, false
+		}
+		return value, true
+	},
+	"createarray": func(args []interface{}) (interface{}, bool) {
+		return append([]interface{}{}, args...), true
+	},
+	"createobject": func(args []interface{}) (interface{}, bool) {
+		if len(args)%2 != 0 {
+			return nil, false
+		}
+		object := map[string]interface{}{}
+		for i := 0; i < len(args); i += 2 {
+			key, ok := args[i].(string)
+			if !ok {
+				return nil, false
+			}
+			object[key] = args[i+1]
+		}
+		return object, true
+	},
+	"union": func(args []interface{}) (interface{}, bool) {
+		if len(args) < 1 {
+			return nil, false
+		}
+		if _, ok := args[0].(map[string]interface{}); ok {
+			union := map[string]interface{}{}
+			for _, arg := range args {
+				object, ok := arg.(map[string]interface{})
+				if !ok {
+					return nil, false
+				}
+				for k, v := range object {
+					union[k] = v
+				}
+			}
+			return union, true
+		}
+		union := []interface{}{}
+		for _, arg := range args {
+			arr, ok := arg.([]interface{})
+			if !ok {
+				return nil, false
+			}
+		elements:
+			for _, element := range arr {
+				for _, existing := range union {
+					if reflect.DeepEqual(existing, element) {
+						continue elements
+					}
+				}
+				union = append(union, element)
+			}
+		}
+		return union, true
+	},
+	"equals": func(args []interface{}) (interface{}, bool) {
+		if len(args) != 2 {
+			return nil, false
+		}
+		return reflect.DeepEqual(args[0], args[1]), true
+	},
+	"greater":         armComparison(func(cmp int) bool { return cmp > 0 }),
+	"greaterorequals": armComparison(func(cmp int) bool { return cmp >= 0 }),
+	"less":            armComparison(func(cmp int) bool { return cmp < 0 }),
+	"lessorequals":    armComparison(func(cmp int) bool { return cmp <= 0 }),
+	"not": func(args []interface{}) (interface{}, bool) {
+		if len(args) != 1 {
+			return nil, false
+		}
+		b, ok := args[0].(bool)
+		return !b, ok
+	},
+	"and": armBooleans(func(bools []bool) bool {
+		for _, b := range bools {
+			if !b {
+				return false
+			}
+		}
+		return true
+	}),
+	"or": armBooleans(func(bools []bool) bool {
+		for _, b := range bools {
+			if b {
+				return true
+			}
+		}
+		return false
+	}),
+	"true": func(args []interface{}) (interface{}, bool) {
+		return true, len(args) == 0
+	},
+	"false": func(args []interface{}) (interface{}, bool) {
+		return false, len(args) == 0
+	},
+	"null": func(args []interface{}) (interface{}, bool) {
+		return nil, len(args) == 0
+	},
+	"add": armArithmetic(func(a, b float64) (float64, bool) { return a + b, true }),
+	"sub": armArithmetic(func(a, b float64) (float64, bool) { return a - b, true }),
+	"mul": armArithmetic(func(a, b float64) (float64, bool) { return a * b, true }),
+	"div": armArithmetic(func(a, b float64) (float64, bool) {
+		if b == 0 {
+			return 0, false
+		}
+		return math.Trunc(a / b), true
+	}),
+	"mod": armArithmetic(func(a, b float64) (float64, bool) {
+		if b == 0 {
+			return 0, false
+		}
+		return math.Mod(a, b), true
+	}),
+	"min": func(args []interface{}) (interface{}, bool) {
+		numbers, ok := armNumberArgs(args)
+		if !ok || len(numbers) == 0 {
+			return nil, false
+		}
+		sort.Float64s(numbers)
+		return numbers[0], true
+	},
+	"max": func(args []interface{}) (interface{}, bool) {
+		numbers, ok := armNumberArgs(args)
+		if !ok || len(numbers) == 0 {
+			return nil, false
+		}
+		sort.Float64s(numbers)
+		return numbers[len(numbers)-1], true
+	},
+}
+
+// armNumberArgs takes the arguments of `min()` and `max()`, which are either
+// numbers or a single array of numbers.
+func armNumberArgs(args []interface{}) ([]float64, bool) {
+	if len(args) == 1 {
+		if arr, ok := args[0].([]interface{}); ok {
+			return armNumbers(arr)
+		}
+	}
+	return armNumbers(args)
+}
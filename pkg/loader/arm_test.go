@@ -0,0 +1,304 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader_test
+
+import (
+	"testing"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArmLocation(t *testing.T) {
+	path := "test_inputs/data/arm.json"
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{path},
+		InputTypes: []loader.InputType{loader.Arm},
+	})()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, loadedConfigs.Count())
+
+	testCases := []struct {
+		path []string
+		line int
+		col  int
+	}{
+		{
+			path: []string{"Microsoft.Storage/storageAccounts/storage"},
+			line: 5,
+			col:  5,
+		},
+		{
+			path: []string{"Microsoft.Storage/storageAccounts/storage", "properties", "supportsHttpsTrafficOnly"},
+			line: 15,
+			col:  9,
+		},
+		{
+			path: []string{"Microsoft.Storage/storageAccounts/storage", "networkAcls", "defaultAction"},
+			line: 17,
+			col:  11,
+		},
+		{
+			path: []string{"Microsoft.Storage/storageAccounts/storage", "sku", "name"},
+			line: 12,
+			col:  9,
+		},
+		{
+			path: []string{"Microsoft.Storage/storageAccounts/storage", "properties", "encryption"},
+			line: 5,
+			col:  5,
+		},
+		{
+			path: []string{"Microsoft.Network/virtualNetworks/vnet"},
+			line: 21,
+			col:  5,
+		},
+		{
+			path: []string{"Microsoft.Network/virtualNetworks/vnet/subnets/subnet"},
+			line: 32,
+			col:  9,
+		},
+		{
+			path: []string{"Microsoft.Network/virtualNetworks/vnet/subnets/subnet", "properties", "addressPrefix"},
+			line: 37,
+			col:  13,
+		},
+	}
+	for _, tc := range testCases {
+		location, err := loadedConfigs.Location(path, tc.path)
+		assert.Nil(t, err)
+		assert.Equal(t, []loader.Location{{Path: path, Line: tc.line, Col: tc.col}}, location, tc.path)
+	}
+
+	location, err := loadedConfigs.Location(path, []string{"Microsoft.Storage/storageAccounts/missing"})
+	assert.Nil(t, err)
+	assert.Nil(t, location)
+}
+
+func TestArmExpressions(t *testing.T) {
+	path := "test_inputs/data/arm_expressions.json"
+	load := func(varFiles []string) map[string]interface{} {
+		loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+			Paths:      []string{path},
+			InputTypes: []loader.InputType{loader.Arm},
+			VarFiles:   varFiles,
+		})()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, loadedConfigs.Count())
+		content := loadedConfigs.RegulaInput()[0]["content"].(map[string]interface{})
+		resources := map[string]interface{}{}
+		for _, r := range content["resources"].([]interface{}) {
+			resource := r.(map[string]interface{})
+			resources[resource["type"].(string)+"/"+resource["name"].(string)] = resource
+		}
+		return resources
+	}
+
+	resources := load(nil)
+	assert.Len(t, resources, 4)
+	// Names identify resources, so they are kept even if they are unknown.
+	assert.Contains(t, resources, "Microsoft.Network/publicIPAddresses/[concat('ip-', uniqueString(resourceGroup().id))]")
+	storage := resources["Microsoft.Storage/storageAccounts/regulastorage"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"_unknown": "[resourceGroup().location]"}, storage["location"])
+	assert.Equal(t, map[string]interface{}{"name": "Standard_LRS"}, storage["sku"])
+	assert.Equal(t, map[string]interface{}{
+		"environment": "dev",
+		"description": "[not an expression]",
+	}, storage["tags"])
+	assert.Equal(t, map[string]interface{}{
+		"supportsHttpsTrafficOnly": false,
+		"minimumTlsVersion":        "TLS1_0",
+		"accessTier":               "Hot",
+	}, storage["properties"])
+
+	vnet := resources["Microsoft.Network/virtualNetworks/regula-vnet"].(map[string]interface{})
+	assert.Equal(t, true, vnet["condition"])
+	assert.Equal(t, []interface{}{"Microsoft.Storage/storageAccounts/regulastorage"}, vnet["dependsOn"])
+	properties := vnet["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"addressPrefixes": []interface{}{"10.0.0.0/16"}}, properties["addressSpace"])
+	assert.Equal(t, map[string]interface{}{
+		"dnsServers": map[string]interface{}{
+			"_unknown": "[reference(resourceId('Microsoft.Network/publicIPAddresses', 'dns')).ipAddress]",
+		},
+	}, properties["dhcpOptions"])
+	assert.Equal(t, float64(3), properties["subnetCount"])
+	assert.Equal(t, map[string]interface{}{"_unknown": "[parameters('adminPassword')]"}, properties["password"])
+	assert.Equal(t, map[string]interface{}{
+		"_unknown": "[concat(resourceId('Microsoft.Storage/storageAccounts', variables('storageName')), '/blobServices/default')]",
+	}, properties["storageId"])
+	subnet := resources["Microsoft.Network/virtualNetworks/subnets/regula-vnet/regula-subnet"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"Microsoft.Network/virtualNetworks/regula-vnet"}, subnet["dependsOn"])
+
+	resources = load([]string{"test_inputs/data/arm_parameters.json"})
+	storage = resources["Microsoft.Storage/storageAccounts/regulastorage"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"name": "Standard_GRS"}, storage["sku"])
+	assert.Equal(t, map[string]interface{}{
+		"supportsHttpsTrafficOnly": true,
+		"minimumTlsVersion":        "TLS1_2",
+		"accessTier":               "Hot",
+	}, storage["properties"])
+}
+
+func TestArmExpressionsLocation(t *testing.T) {
+	path := "test_inputs/data/arm_expressions.json"
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{path},
+		InputTypes: []loader.InputType{loader.Arm},
+	})()
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		path []string
+		line int
+		col  int
+	}{
+		{
+			path: []string{"Microsoft.Storage/storageAccounts/regulastorage", "properties", "accessTier"},
+			line: 55,
+			col:  9,
+		},
+		{
+			path: []string{"Microsoft.Network/virtualNetworks/regula-vnet/subnets/regula-subnet"},
+			line: 78,
+			col:  9,
+		},
+	}
+	for _, tc := range testCases {
+		location, err := loadedConfigs.Location(path, tc.path)
+		assert.Nil(t, err)
+		assert.Equal(t, []loader.Location{{Path: path, Line: tc.line, Col: tc.col}}, location, tc.path)
+	}
+
+	location, err := loadedConfigs.Location(path, []string{"Microsoft.Network/virtualNetworks/skipped"})
+	assert.Nil(t, err)
+	assert.Nil(t, location)
+}
+
+func TestArmParameterFileIsNotATemplate(t *testing.T) {
+	_, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{"test_inputs/data/arm_parameters.json"},
+		InputTypes: []loader.InputType{loader.Arm},
+	})()
+	assert.NotNil(t, err)
+}
+
+func TestArmNestedResourcesAndDeployments(t *testing.T) {
+	path := "arm_test/deployments/main.json"
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{path},
+		InputTypes: []loader.InputType{loader.Arm},
+	})()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, loadedConfigs.Count())
+	for _, file := range []string{
+		path,
+		"arm_test/deployments/linked/keyvault.json",
+		"arm_test/deployments/linked/keyvault.parameters.json",
+	} {
+		assert.True(t, loadedConfigs.AlreadyLoaded(file), file)
+	}
+
+	content := loadedConfigs.RegulaInput()[0]["content"].(map[string]interface{})
+	resources := map[string]map[string]interface{}{}
+	for _, r := range content["resources"].([]interface{}) {
+		resource := r.(map[string]interface{})
+		assert.NotContains(t, resource, "resources")
+		resources[resource["type"].(string)+" "+resource["name"].(string)] = resource
+	}
+	assert.ElementsMatch(t, []string{
+		"Microsoft.Storage/storageAccounts regulasa",
+		"Microsoft.Storage/storageAccounts/blobServices regulasa/default",
+		"Microsoft.Storage/storageAccounts/blobServices/containers regulasa/default/logs",
+		"Microsoft.Resources/deployments network",
+		"Microsoft.Network/virtualNetworks regula-vnet",
+		"Microsoft.Network/virtualNetworks/subnets regula-vnet/default",
+		"Microsoft.Resources/deployments security",
+		"Microsoft.Network/networkSecurityGroups regula-nsg",
+		"Microsoft.Resources/deployments vault",
+		"Microsoft.KeyVault/vaults regula-kv",
+		"Microsoft.Resources/deployments remote",
+	}, func() []string {
+		keys := []string{}
+		for key := range resources {
+			keys = append(keys, key)
+		}
+		return keys
+	}())
+	network := resources["Microsoft.Resources/deployments network"]["properties"].(map[string]interface{})
+	assert.NotContains(t, network, "template")
+	vault := resources["Microsoft.KeyVault/vaults regula-kv"]["properties"].(map[string]interface{})
+	assert.Equal(t, true, vault["enableSoftDelete"])
+	assert.Equal(t, map[string]interface{}{"_unknown": "[subscription().tenantId]"}, vault["tenantId"])
+
+	linked := "arm_test/deployments/linked/keyvault.json"
+	testCases := []struct {
+		path     []string
+		location loader.LocationStack
+	}{
+		{
+			path: []string{"Microsoft.Storage/storageAccounts/regulasa/blobServices/default/containers/logs", "properties", "publicAccess"},
+			location: loader.LocationStack{
+				{Path: path, Line: 33, Col: 17},
+			},
+		},
+		{
+			path: []string{"Microsoft.Network/virtualNetworks/regula-vnet/subnets/default"},
+			location: loader.LocationStack{
+				{Path: path, Line: 74, Col: 17},
+				{Path: path, Line: 40, Col: 5},
+			},
+		},
+		{
+			path: []string{"Microsoft.Network/networkSecurityGroups/regula-nsg"},
+			location: loader.LocationStack{
+				{Path: path, Line: 98, Col: 13},
+				{Path: path, Line: 88, Col: 5},
+			},
+		},
+		{
+			path: []string{"Microsoft.KeyVault/vaults/regula-kv", "properties", "enableSoftDelete"},
+			location: loader.LocationStack{
+				{Path: linked, Line: 20, Col: 9},
+				{Path: path, Line: 111, Col: 5},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		location, err := loadedConfigs.Location(path, tc.path)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.location, location, tc.path)
+	}
+}
+
+func TestArmLinkedTemplatesDirectory(t *testing.T) {
+	// The linked directory comes before main.json in the walk, but
+	// keyvault.json is only loaded as a part of main.json.
+	main := "arm_test/deployments/main.json"
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{"arm_test/deployments"},
+		InputTypes: []loader.InputType{loader.Auto},
+	})()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{main}, loadedConfigs.Paths())
+	assert.Equal(t, main, *loadedConfigs.ConfigurationPath("arm_test/deployments/linked/keyvault.json"))
+	content := loadedConfigs.RegulaInput()[0]["content"].(map[string]interface{})
+	resources := []string{}
+	for _, r := range content["resources"].([]interface{}) {
+		resource := r.(map[string]interface{})
+		resources = append(resources, resource["type"].(string)+" "+resource["name"].(string))
+	}
+	assert.Len(t, resources, 11)
+	assert.Contains(t, resources, "Microsoft.KeyVault/vaults regula-kv")
+}
@@ -29,6 +29,16 @@ import (
 // stdIn is the path used for stdin.
 const stdIn = "<stdin>"
 
+// unknownKey is the key of the object that replaces a value in an ARM or
+// CloudFormation template that can't be known before it is deployed, e.g.
+//
+//     {"_unknown": "[reference('vnet').id]"}
+//
+// The value is the expression as it is written in the template, so rules can
+// tell unknown values apart from actual values, and can still look at how
+// they are computed.
+const unknownKey = "_unknown"
+
 // InputType is a flag that determines which types regula should look for.
 type InputType int
 
@@ -49,6 +59,13 @@ const (
 	K8s
 	// Azure Resource Manager JSON
 	Arm
+	// Pulumi stack exports and `pulumi preview --json` output
+	Pulumi
+	// Helm charts, which are rendered and loaded as Kubernetes manifests
+	Helm
+	// AWS CDK cloud assemblies, whose stack templates are loaded as
+	// CloudFormation
+	Cdk
 )
 
 // InputTypeIDs maps the InputType enums to string values that can be specified in
@@ -60,6 +77,9 @@ var InputTypeIDs = map[InputType][]string{
 	Tf:     {"tf"},
 	K8s:    {"k8s", "kubernetes"},
 	Arm:    {"arm"},
+	Pulumi: {"pulumi"},
+	Helm:   {"helm"},
+	Cdk:    {"cdk"},
 }
 
 var DefaultInputTypes = InputTypeIDs[Auto]
@@ -118,6 +138,15 @@ type LoadedConfigurations interface {
 	Location(path string, attributePath []string) (LocationStack, error)
 	// RegulaInput renders the RegulaInput from all of the contained configurations.
 	RegulaInput() []RegulaInput
+	// Paths returns the canonical paths of all of the contained configurations,
+	// in the same order as RegulaInput.
+	Paths() []string
+	// Configuration returns the configuration for the given canonical path, or
+	// nil if there is no such configuration.
+	Configuration(path string) IACConfiguration
+	// InlineIgnores returns the `regula:ignore` annotations for the resource
+	// with the given ID in the configuration that loaded the given path.
+	InlineIgnores(path string, resourceID string) []InlineIgnore
 	// Count returns the number of loaded configurations.
 	Count() int
 }
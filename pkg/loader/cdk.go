@@ -0,0 +1,257 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const cdkManifestFile = "manifest.json"
+
+// Artifact types in a cloud assembly manifest.
+const (
+	cdkStackArtifact    = "aws:cloudformation:stack"
+	cdkAssemblyArtifact = "cdk:cloud-assembly"
+)
+
+// cdkManifest is the part of a cloud assembly manifest that we need.  The
+// metadata of a stack maps construct paths to their logical IDs, among other
+// things.
+type cdkManifest struct {
+	Version   string                 `json:"version"`
+	Artifacts map[string]cdkArtifact `json:"artifacts"`
+}
+
+type cdkArtifact struct {
+	Type       string                        `json:"type"`
+	Properties map[string]interface{}        `json:"properties"`
+	Metadata   map[string][]cdkMetadataEntry `json:"metadata"`
+}
+
+type cdkMetadataEntry struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// CdkDetector loads AWS CDK cloud assemblies, i.e. the cdk.out directory
+// written by `cdk synth`.  The templates of all stacks in the assembly are
+// loaded into a single CloudFormation configuration, in which resources are
+// identified by their construct path.
+type CdkDetector struct{}
+
+func (c *CdkDetector) DetectFile(i InputFile, opts DetectOptions) (IACConfiguration, error) {
+	if i.Name() != cdkManifestFile {
+		return nil, fmt.Errorf("Expected a %s file for a CDK cloud assembly: %v", cdkManifestFile, i.Path())
+	}
+	return loadCdkAssembly(filepath.Dir(i.Path()))
+}
+
+func (c *CdkDetector) DetectDirectory(i InputDirectory, opts DetectOptions) (IACConfiguration, error) {
+	if opts.IgnoreDirs {
+		return nil, nil
+	}
+	for _, child := range i.Children() {
+		if f, ok := child.(InputFile); ok && f.Name() == cdkManifestFile {
+			return loadCdkAssembly(i.Path())
+		}
+	}
+	return nil, nil
+}
+
+func readCdkManifest(dir string) (*cdkManifest, error) {
+	contents, err := os.ReadFile(filepath.Join(dir, cdkManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &cdkManifest{}
+	if err := json.Unmarshal(contents, manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version == "" || manifest.Artifacts == nil {
+		return nil, fmt.Errorf("Not a CDK cloud assembly manifest")
+	}
+	return manifest, nil
+}
+
+func loadCdkAssembly(dir string) (IACConfiguration, error) {
+	config := &cfnConfiguration{
+		path:    dir,
+		sources: map[string]cfnResourceSource{},
+	}
+	resources := map[string]interface{}{}
+	if err := config.loadCdkAssembly(dir, resources); err != nil {
+		return nil, fmt.Errorf("Failed to load CDK cloud assembly %v: %v", dir, err)
+	}
+	for id, resource := range resources {
+		resources[id] = cfnMarkUnknown(resource, resources)
+	}
+	config.template = cfnTemplate{Contents: map[string]interface{}{
+		"Resources": resources,
+	}}
+
+	// The assembly also contains assets, nested assemblies and the templates
+	// of nested stacks, which should not be loaded on their own.
+	walkDirFunc := func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != dir {
+			config.files = append(config.files, p)
+		}
+		return nil
+	}
+	if err := filepath.WalkDir(dir, walkDirFunc); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// loadCdkAssembly adds the resources of the stacks in a cloud assembly to
+// resources, including the stacks of nested assemblies such as CDK stages.
+func (l *cfnConfiguration) loadCdkAssembly(dir string, resources map[string]interface{}) error {
+	manifest, err := readCdkManifest(dir)
+	if err != nil {
+		return err
+	}
+	artifactIds := []string{}
+	for artifactId := range manifest.Artifacts {
+		artifactIds = append(artifactIds, artifactId)
+	}
+	sort.Strings(artifactIds)
+	for _, artifactId := range artifactIds {
+		artifact := manifest.Artifacts[artifactId]
+		switch artifact.Type {
+		case cdkStackArtifact:
+			if err := l.loadCdkStack(dir, artifactId, artifact, resources); err != nil {
+				return err
+			}
+		case cdkAssemblyArtifact:
+			directoryName, _ := artifact.Properties["directoryName"].(string)
+			if directoryName == "" {
+				continue
+			}
+			if err := l.loadCdkAssembly(filepath.Join(dir, directoryName), resources); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadCdkStack loads the template of a stack like a CloudFormation template,
+// with the parameters given in the manifest, and then renames its resources
+// to their construct paths.
+func (l *cfnConfiguration) loadCdkStack(
+	dir string,
+	artifactId string,
+	artifact cdkArtifact,
+	resources map[string]interface{},
+) error {
+	templateFile, _ := artifact.Properties["templateFile"].(string)
+	if templateFile == "" {
+		return fmt.Errorf("Stack %v does not have a template file", artifactId)
+	}
+	path := filepath.Join(dir, templateFile)
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	template := &cfnTemplate{}
+	if err := yaml.Unmarshal(contents, &template); err != nil || template == nil {
+		return fmt.Errorf("Failed to parse template of stack %v: %v", artifactId, err)
+	}
+	source, err := LoadSourceInfoNode(contents)
+	if err != nil {
+		source = nil
+	}
+	parameters, _ := artifact.Properties["parameters"].(map[string]interface{})
+
+	stack := &cfnConfiguration{
+		path:    path,
+		sources: map[string]cfnResourceSource{},
+	}
+	stackResources := map[string]interface{}{}
+	stack.loadStack(path, template.Contents, source, "", parameters, []string{path}, stackResources)
+
+	ids := cdkConstructPaths(artifactId, artifact, stackResources)
+	scope := &cfnScope{ids: ids, parameters: map[string]interface{}{}}
+	for logicalId, resource := range stackResources {
+		id := ids[logicalId]
+		if _, ok := resources[id]; ok {
+			logrus.Warnf("Resource %v of stack %v is defined more than once in %v", id, artifactId, dir)
+		}
+		resources[id] = scope.rewrite(resource)
+		l.sources[id] = stack.sources[logicalId]
+	}
+	return nil
+}
+
+// cdkConstructPaths maps the logical IDs of the resources in a stack to the
+// path of the construct that defines them.  The path is taken from the
+// `aws:cdk:path` metadata of the resource, or otherwise from the manifest.
+// The default child of a construct is named after the construct, so the
+// resource of a `Bucket` construct is e.g. `MyStack/Bucket` rather than
+// `MyStack/Bucket/Resource`.  Resources without a known construct path keep
+// their logical ID, prefixed with the ID of the stack.
+func cdkConstructPaths(
+	artifactId string,
+	artifact cdkArtifact,
+	resources map[string]interface{},
+) map[string]string {
+	paths := map[string]string{}
+	for path, entries := range artifact.Metadata {
+		for _, entry := range entries {
+			if logicalId, ok := entry.Data.(string); ok && entry.Type == "aws:cdk:logicalId" {
+				paths[logicalId] = strings.TrimPrefix(path, "/")
+			}
+		}
+	}
+	for logicalId, r := range resources {
+		resource, _ := r.(map[string]interface{})
+		metadata, _ := resource["Metadata"].(map[string]interface{})
+		if path, ok := metadata["aws:cdk:path"].(string); ok && path != "" {
+			paths[logicalId] = path
+		}
+	}
+
+	ids := map[string]string{}
+	taken := map[string]bool{}
+	logicalIds := []string{}
+	for logicalId := range resources {
+		logicalIds = append(logicalIds, logicalId)
+	}
+	sort.Strings(logicalIds)
+	for _, logicalId := range logicalIds {
+		id := artifactId + "/" + logicalId
+		if path, ok := paths[logicalId]; ok {
+			id = strings.TrimSuffix(strings.TrimSuffix(path, "/Resource"), "/Default")
+		}
+		if taken[id] {
+			id = artifactId + "/" + logicalId
+		}
+		taken[id] = true
+		ids[logicalId] = id
+	}
+	return ids
+}
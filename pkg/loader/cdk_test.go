@@ -0,0 +1,66 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader_test
+
+import (
+	"testing"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCdkCloudAssembly(t *testing.T) {
+	dir := "cdk_test/cdk.out"
+	template := "cdk_test/cdk.out/AppStack.template.json"
+	for _, inputType := range []loader.InputType{loader.Cdk, loader.Auto} {
+		loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+			Paths:      []string{dir},
+			InputTypes: []loader.InputType{inputType},
+		})()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, loadedConfigs.Count())
+		assert.True(t, loadedConfigs.AlreadyLoaded(template))
+		assert.True(t, loadedConfigs.AlreadyLoaded("cdk_test/cdk.out/assembly-Prod/ProdAppStack5D3D4B2A.template.json"))
+
+		content := loadedConfigs.RegulaInput()[0]["content"].(map[string]interface{})
+		resources := content["Resources"].(map[string]interface{})
+		assert.Len(t, resources, 4)
+		for _, id := range []string{"AppStack/Logs", "AppStack/Bucket", "AppStack/Bucket/Policy", "Prod/AppStack/Bucket"} {
+			assert.Contains(t, resources, id)
+		}
+
+		// References are renamed along with the resources.
+		bucket := cfnProperties(t, resources, "AppStack/Bucket")
+		assert.Equal(t, map[string]interface{}{
+			"DestinationBucketName": map[string]interface{}{"Ref": "AppStack/Logs"},
+		}, bucket["LoggingConfiguration"])
+		policy := cfnProperties(t, resources, "AppStack/Bucket/Policy")
+		assert.Equal(t, map[string]interface{}{"Ref": "AppStack/Bucket"}, policy["Bucket"])
+		prodBucket := cfnProperties(t, resources, "Prod/AppStack/Bucket")
+		assert.Equal(t, "prod-data", prodBucket["BucketName"])
+
+		location, err := loadedConfigs.Location(dir, []string{"AppStack/Bucket"})
+		assert.Nil(t, err)
+		assert.Equal(t, loader.LocationStack{{Path: template, Line: 8, Col: 5}}, location)
+	}
+}
+
+func TestCdkDetectorNotCloudAssembly(t *testing.T) {
+	_, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{"cdk_test/cdk.out/AppStack.assets.json"},
+		InputTypes: []loader.InputType{loader.Cdk},
+	})()
+	assert.NotNil(t, err)
+}
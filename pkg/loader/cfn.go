@@ -55,11 +55,22 @@ func (c *CfnDetector) DetectFile(i InputFile, opts DetectOptions) (IACConfigurat
 		source = nil // Don't consider source code locations essential.
 	}
 
-	return &cfnConfiguration{
+	config := &cfnConfiguration{
 		path:     path,
 		template: *template,
-		source:   source,
-	}, nil
+		sources:  map[string]cfnResourceSource{},
+		files:    []string{path},
+	}
+	if hasResources {
+		resources := map[string]interface{}{}
+		parameters := cfnParameterValues(opts.VarFiles)
+		config.loadStack(path, template.Contents, source, "", parameters, []string{path}, resources)
+		for id, resource := range resources {
+			resources[id] = cfnMarkUnknown(resource, resources)
+		}
+		template.Contents["Resources"] = resources
+	}
+	return config, nil
 }
 
 func (c *CfnDetector) DetectDirectory(i InputDirectory, opts DetectOptions) (IACConfiguration, error) {
@@ -69,7 +80,21 @@ func (c *CfnDetector) DetectDirectory(i InputDirectory, opts DetectOptions) (IAC
 type cfnConfiguration struct {
 	path     string
 	template cfnTemplate
-	source   *SourceInfoNode
+	// Sources of the resources, including the resources of nested stacks.
+	sources map[string]cfnResourceSource
+	files   []string
+}
+
+type cfnResourceSource struct {
+	path   string
+	source *SourceInfoNode
+	// Logical ID of the resource in its template.  For resources generated
+	// by the SAM transform, this is the SAM resource.
+	logicalId string
+	generated bool
+	// ID of the stack resource that includes the resource's template, if it
+	// is in a nested stack.
+	stack string
 }
 
 func (l *cfnConfiguration) RegulaInput() RegulaInput {
@@ -79,40 +104,62 @@ func (l *cfnConfiguration) RegulaInput() RegulaInput {
 	}
 }
 
+// Location returns the location of a resource, followed by the locations of
+// the nested stacks that include it, like the module call stack of Terraform
+// resources.
 func (l *cfnConfiguration) Location(path []string) (LocationStack, error) {
-	if l.source == nil || len(path) < 1 {
+	if len(path) < 1 {
+		return nil, nil
+	}
+	resource, ok := l.sources[path[0]]
+	if !ok || resource.source == nil {
 		return nil, nil
 	}
+	attributePath := path[1:]
+	if resource.generated {
+		attributePath = nil
+	}
+	location := cfnResourceLocation(resource.path, resource.source, resource.logicalId, attributePath)
+	if location != nil && resource.stack != "" {
+		stackLocation, err := l.Location([]string{resource.stack})
+		if err != nil {
+			return nil, err
+		}
+		location = append(location, stackLocation...)
+	}
+	return location, nil
+}
 
+func cfnResourceLocation(path string, source *SourceInfoNode, logicalId string, attributePath []string) LocationStack {
 	resourcePath := []string{"Resources"}
-	resourcePath = append(resourcePath, path[0])
-	resource, err := l.source.GetPath(resourcePath)
+	resourcePath = append(resourcePath, logicalId)
+	resource, err := source.GetPath(resourcePath)
 	if err != nil {
-		return nil, nil
+		return nil
 	}
 	resourceLine, resourceColumn := resource.Location()
 	resourceLocation := Location{
-		Path: l.path,
+		Path: path,
 		Line: resourceLine,
 		Col:  resourceColumn,
 	}
 
 	properties, err := resource.GetKey("Properties")
 	if err != nil {
-		return []Location{resourceLocation}, nil
+		return []Location{resourceLocation}
 	}
 
-	attribute, err := properties.GetPath(path[1:])
+	attribute, err := properties.GetPath(attributePath)
 	if attribute != nil {
-		return []Location{resourceLocation}, nil
+		return []Location{resourceLocation}
 	}
 
 	line, column := attribute.Location()
-	return []Location{{Path: l.path, Line: line, Col: column}}, nil
+	return []Location{{Path: path, Line: line, Col: column}}
 }
 
 func (l *cfnConfiguration) LoadedFiles() []string {
-	return []string{l.path}
+	return l.files
 }
 
 type cfnTemplate struct {
@@ -178,6 +225,7 @@ var intrinsicFns map[string]string = map[string]string{
 	"!And":         "Fn::And",
 	"!Base64":      "Fn::Base64",
 	"!Cidr":        "Fn::Cidr",
+	"!Condition":   "Condition",
 	"!Equals":      "Fn::Equals",
 	"!FindInMap":   "Fn::FindInMap",
 	"!GetAtt":      "Fn::GetAtt",
@@ -188,6 +236,7 @@ var intrinsicFns map[string]string = map[string]string{
 	"!Not":         "Fn::Not",
 	"!Or":          "Fn::Or",
 	"!Ref":         "Ref",
+	"!Select":      "Fn::Select",
 	"!Split":       "Fn::Split",
 	"!Sub":         "Fn::Sub",
 	"!Transform":   "Fn::Transform",
@@ -0,0 +1,574 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// cfnNoValueType is the value of the AWS::NoValue pseudo parameter, which
+// removes the property or list element that it is used for.
+type cfnNoValueType struct{}
+
+var cfnNoValue = cfnNoValueType{}
+
+// cfnEvaluator resolves the intrinsic functions in a CloudFormation template
+// that only depend on its parameters, mappings and conditions.  Values that
+// can't be resolved before the stack is deployed, such as references to
+// resources or pseudo parameters, are left as they are written, so the
+// resource view can still turn references to resources into resource IDs.
+type cfnEvaluator struct {
+	parameters map[string]interface{}
+	mappings   map[string]interface{}
+	conditions map[string]interface{}
+	cache      map[string]bool
+	evaluating map[string]bool
+}
+
+// newCfnEvaluator creates an evaluator for a template, given the values that
+// are supplied for its parameters.  Parameters without a value use their
+// default.
+func newCfnEvaluator(template map[string]interface{}, values map[string]interface{}) *cfnEvaluator {
+	e := &cfnEvaluator{
+		parameters: map[string]interface{}{},
+		cache:      map[string]bool{},
+		evaluating: map[string]bool{},
+	}
+	e.mappings, _ = template["Mappings"].(map[string]interface{})
+	e.conditions, _ = template["Conditions"].(map[string]interface{})
+	parameters, _ := template["Parameters"].(map[string]interface{})
+	for name, p := range parameters {
+		parameter, _ := p.(map[string]interface{})
+		value, ok := values[name]
+		if !ok {
+			value, ok = parameter["Default"]
+		}
+		if !ok || !cfnKnown(value) {
+			continue
+		}
+		if value, ok := cfnParameterValue(parameter, value); ok {
+			e.parameters[name] = value
+		}
+	}
+	return e
+}
+
+// cfnParameterValue converts the value of a parameter to the value that
+// `Ref` returns for its type.  SSM parameters are resolved when the stack is
+// deployed, so their value is unknown.
+func cfnParameterValue(parameter map[string]interface{}, value interface{}) (interface{}, bool) {
+	parameterType, _ := parameter["Type"].(string)
+	str, isString := value.(string)
+	switch {
+	case strings.HasPrefix(parameterType, "AWS::SSM::Parameter::Value<"):
+		return nil, false
+	case parameterType == "CommaDelimitedList" || strings.HasPrefix(parameterType, "List<"):
+		if !isString {
+			return value, true
+		}
+		list := []interface{}{}
+		for _, item := range strings.Split(str, ",") {
+			list = append(list, strings.TrimSpace(item))
+		}
+		return list, true
+	case parameterType == "Number" && isString:
+		if i, err := strconv.Atoi(strings.TrimSpace(str)); err == nil {
+			return i, true
+		}
+		if f, err := strconv.ParseFloat(strings.TrimSpace(str), 64); err == nil {
+			return f, true
+		}
+	}
+	return value, true
+}
+
+// cfnKnown checks that a value doesn't contain any intrinsic functions.
+func cfnKnown(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if isCfnIntrinsic(v) {
+			return false
+		}
+		for _, child := range v {
+			if !cfnKnown(child) {
+				return false
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if !cfnKnown(child) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// template returns a copy of a template in which the resources and the SAM
+// globals are evaluated, and the resources whose condition is false are
+// left out.
+func (e *cfnEvaluator) template(template map[string]interface{}) map[string]interface{} {
+	evaluated := make(map[string]interface{}, len(template))
+	for k, v := range template {
+		evaluated[k] = v
+	}
+	if resources, ok := template["Resources"].(map[string]interface{}); ok {
+		evaluatedResources := map[string]interface{}{}
+		for id, r := range resources {
+			resource, ok := r.(map[string]interface{})
+			if !ok {
+				evaluatedResources[id] = r
+				continue
+			}
+			if condition, ok := resource["Condition"].(string); ok {
+				if value, known := e.condition(condition); known && !value {
+					continue
+				}
+			}
+			evaluatedResource := map[string]interface{}{}
+			for k, v := range resource {
+				if k == "Properties" {
+					if v = e.evaluate(v); v == cfnNoValue {
+						continue
+					}
+				}
+				evaluatedResource[k] = v
+			}
+			evaluatedResources[id] = evaluatedResource
+		}
+		evaluated["Resources"] = evaluatedResources
+	}
+	if globals, ok := template["Globals"]; ok {
+		evaluated["Globals"] = e.evaluate(globals)
+	}
+	return evaluated
+}
+
+// evaluate resolves the intrinsic functions in a value as far as possible.
+func (e *cfnEvaluator) evaluate(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if isCfnIntrinsic(v) {
+			return e.intrinsic(v)
+		}
+		evaluated := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			if child := e.evaluate(child); child != cfnNoValue {
+				evaluated[k] = child
+			}
+		}
+		return evaluated
+	case []interface{}:
+		evaluated := []interface{}{}
+		for _, child := range v {
+			if child := e.evaluate(child); child != cfnNoValue {
+				evaluated = append(evaluated, child)
+			}
+		}
+		return evaluated
+	default:
+		return value
+	}
+}
+
+func (e *cfnEvaluator) intrinsic(intrinsic map[string]interface{}) interface{} {
+	for name, args := range intrinsic {
+		switch name {
+		case "Ref":
+			if ref, ok := args.(string); ok {
+				if ref == "AWS::NoValue" {
+					return cfnNoValue
+				}
+				if value, ok := e.parameters[ref]; ok {
+					return value
+				}
+			}
+			return intrinsic
+		case "Fn::If":
+			if arr, ok := args.([]interface{}); ok && len(arr) == 3 {
+				if condition, ok := arr[0].(string); ok {
+					if value, known := e.condition(condition); known {
+						if value {
+							return e.evaluate(arr[1])
+						}
+						return e.evaluate(arr[2])
+					}
+				}
+			}
+		case "Fn::Sub":
+			return e.sub(args)
+		case "Fn::Join":
+			if arr, ok := args.([]interface{}); ok && len(arr) == 2 {
+				delimiter, _ := arr[0].(string)
+				list := e.evaluate(arr[1])
+				if strs, ok := cfnStrings(list); ok {
+					return strings.Join(strs, delimiter)
+				}
+				return map[string]interface{}{name: []interface{}{arr[0], list}}
+			}
+		case "Fn::FindInMap":
+			evaluated := e.arguments(args)
+			if keys, ok := cfnStrings(evaluated); ok && len(keys) == 3 {
+				mapping, _ := e.mappings[keys[0]].(map[string]interface{})
+				top, _ := mapping[keys[1]].(map[string]interface{})
+				if value, ok := top[keys[2]]; ok {
+					return value
+				}
+			}
+			return map[string]interface{}{name: evaluated}
+		case "Fn::Select":
+			evaluated := e.arguments(args)
+			if arr, ok := evaluated.([]interface{}); ok && len(arr) == 2 {
+				index, indexOk := cfnIndex(arr[0])
+				list, listOk := arr[1].([]interface{})
+				if indexOk && listOk && cfnKnown(list) && index >= 0 && index < len(list) {
+					return list[index]
+				}
+			}
+			return map[string]interface{}{name: evaluated}
+		case "Fn::Split":
+			evaluated := e.arguments(args)
+			if strs, ok := cfnStrings(evaluated); ok && len(strs) == 2 {
+				list := []interface{}{}
+				for _, part := range strings.Split(strs[1], strs[0]) {
+					list = append(list, part)
+				}
+				return list
+			}
+			return map[string]interface{}{name: evaluated}
+		}
+		// Other functions can't be resolved, but their arguments may be.
+		return map[string]interface{}{name: e.arguments(args)}
+	}
+	return intrinsic
+}
+
+// arguments evaluates the arguments of a function that is kept.  Arguments
+// that are AWS::NoValue, such as a branch of an unresolved Fn::If, are kept
+// as well.
+func (e *cfnEvaluator) arguments(args interface{}) interface{} {
+	arr, ok := args.([]interface{})
+	if !ok {
+		return e.argument(args)
+	}
+	evaluated := make([]interface{}, len(arr))
+	for i, arg := range arr {
+		evaluated[i] = e.argument(arg)
+	}
+	return evaluated
+}
+
+func (e *cfnEvaluator) argument(arg interface{}) interface{} {
+	if evaluated := e.evaluate(arg); evaluated != cfnNoValue {
+		return evaluated
+	}
+	return map[string]interface{}{"Ref": "AWS::NoValue"}
+}
+
+var cfnSubPlaceholder = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// sub substitutes the variables of a Fn::Sub template that are known.  If
+// all of them are, the result is a string.
+func (e *cfnEvaluator) sub(args interface{}) interface{} {
+	template, ok := args.(string)
+	var variables map[string]interface{}
+	if arr, isArr := args.([]interface{}); isArr && len(arr) == 2 {
+		template, ok = arr[0].(string)
+		variables, _ = e.evaluate(arr[1]).(map[string]interface{})
+	}
+	if !ok {
+		return map[string]interface{}{"Fn::Sub": e.evaluate(args)}
+	}
+
+	resolved := true
+	substituted := cfnSubPlaceholder.ReplaceAllStringFunc(template, func(match string) string {
+		name := match[2 : len(match)-1]
+		if strings.HasPrefix(name, "!") {
+			return match
+		}
+		value, ok := variables[name]
+		if !ok {
+			value, ok = e.parameters[name]
+		}
+		switch v := value.(type) {
+		case string:
+			return v
+		case int, float64, bool:
+			return fmt.Sprint(v)
+		}
+		resolved = false
+		return match
+	})
+
+	if resolved {
+		return strings.ReplaceAll(substituted, "${!", "${")
+	}
+	if variables != nil {
+		return map[string]interface{}{"Fn::Sub": []interface{}{substituted, variables}}
+	}
+	return map[string]interface{}{"Fn::Sub": substituted}
+}
+
+// condition evaluates a condition in the Conditions section.  It returns
+// false if the condition can't be evaluated.
+func (e *cfnEvaluator) condition(name string) (bool, bool) {
+	if value, ok := e.cache[name]; ok {
+		return value, true
+	}
+	definition, ok := e.conditions[name]
+	if !ok || e.evaluating[name] {
+		return false, false
+	}
+	e.evaluating[name] = true
+	value, known := e.conditionValue(definition)
+	delete(e.evaluating, name)
+	if known {
+		e.cache[name] = value
+	}
+	return value, known
+}
+
+func (e *cfnEvaluator) conditionValue(definition interface{}) (bool, bool) {
+	intrinsic, ok := definition.(map[string]interface{})
+	if !ok || !isCfnIntrinsic(intrinsic) {
+		return false, false
+	}
+	for name, args := range intrinsic {
+		if name == "Condition" {
+			if condition, ok := args.(string); ok {
+				return e.condition(condition)
+			}
+			return false, false
+		}
+		arr, _ := args.([]interface{})
+		switch name {
+		case "Fn::Equals":
+			if len(arr) != 2 {
+				return false, false
+			}
+			left, right := e.evaluate(arr[0]), e.evaluate(arr[1])
+			if !cfnKnown(left) || !cfnKnown(right) {
+				return false, false
+			}
+			return cfnEquals(left, right), true
+		case "Fn::Not":
+			if len(arr) != 1 {
+				return false, false
+			}
+			value, known := e.conditionValue(arr[0])
+			return !value, known
+		case "Fn::And", "Fn::Or":
+			// A false condition decides an And, and a true one an Or, even
+			// if the other conditions are unknown.
+			decisive := name == "Fn::Or"
+			known := true
+			for _, condition := range arr {
+				value, ok := e.conditionValue(condition)
+				if ok && value == decisive {
+					return decisive, true
+				}
+				known = known && ok
+			}
+			return !decisive, known
+		}
+	}
+	return false, false
+}
+
+// cfnEquals compares values like Fn::Equals, for which scalars are compared
+// as strings.
+func cfnEquals(left interface{}, right interface{}) bool {
+	switch left.(type) {
+	case map[string]interface{}, []interface{}:
+		return reflect.DeepEqual(left, right)
+	}
+	switch right.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+	return fmt.Sprint(left) == fmt.Sprint(right)
+}
+
+func cfnStrings(value interface{}) ([]string, bool) {
+	arr, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	strs := make([]string, len(arr))
+	for i, v := range arr {
+		str, ok := v.(string)
+		if !ok {
+			return nil, false
+		}
+		strs[i] = str
+	}
+	return strs, true
+}
+
+func cfnIndex(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), v == float64(int(v))
+	case string:
+		i, err := strconv.Atoi(v)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// cfnMarkUnknown replaces the intrinsic functions in a resource that can't be
+// resolved before the stack is deployed with an unknown marker (see
+// unknownKey), e.g. `{"_unknown": {"Fn::ImportValue": "SharedVpcId"}}`.
+// These are imports, and references to parameters without a value, to
+// pseudo parameters and to resources that aren't in the template.
+// References to resources in the template are kept, since rules follow them.
+func cfnMarkUnknown(value interface{}, resources map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 1 {
+			if ref, ok := v["Ref"].(string); ok {
+				if _, ok := resources[ref]; ok || ref == "AWS::NoValue" {
+					return v
+				}
+				return map[string]interface{}{unknownKey: v}
+			}
+			if _, ok := v["Fn::ImportValue"]; ok {
+				return map[string]interface{}{unknownKey: v}
+			}
+			if getAtt, ok := v["Fn::GetAtt"]; ok {
+				if cfnGetAttResource(getAtt, resources) {
+					return v
+				}
+				return map[string]interface{}{unknownKey: v}
+			}
+		}
+		marked := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			marked[k] = cfnMarkUnknown(child, resources)
+		}
+		return marked
+	case []interface{}:
+		marked := make([]interface{}, len(v))
+		for i, child := range v {
+			marked[i] = cfnMarkUnknown(child, resources)
+		}
+		return marked
+	default:
+		return value
+	}
+}
+
+// cfnGetAttResource checks if a Fn::GetAtt refers to one of the resources.
+// The short form may be split on dots when it is decoded, while the IDs of
+// resources in nested stacks contain dots, so names are matched by prefix.
+func cfnGetAttResource(getAtt interface{}, resources map[string]interface{}) bool {
+	var name string
+	switch g := getAtt.(type) {
+	case []interface{}:
+		if len(g) > 0 {
+			name, _ = g[0].(string)
+		}
+	case []string:
+		name = strings.Join(g, ".")
+	case string:
+		name = g
+	}
+	for id := range resources {
+		if name == id || strings.HasPrefix(name, id+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// cfnParameterValues reads the values in the CloudFormation parameter files
+// passed in with --var-file.
+func cfnParameterValues(varFiles []string) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, varFile := range varFiles {
+		if parameters, ok := readCfnParameterFile(varFile); ok {
+			for name, value := range parameters {
+				values[name] = value
+			}
+		}
+	}
+	return values
+}
+
+// isCfnParameterFile checks if a --var-file path is a CloudFormation
+// parameter file.
+func isCfnParameterFile(path string) bool {
+	_, ok := readCfnParameterFile(path)
+	return ok
+}
+
+// readCfnParameterFile reads a CloudFormation parameter file, which is either
+// a list of parameters as used by the AWS CLI:
+//
+//	[{"ParameterKey": "Env", "ParameterValue": "prod"}]
+//
+// or a template configuration file:
+//
+//	{"Parameters": {"Env": "prod"}}
+func readCfnParameterFile(path string) (map[string]interface{}, bool) {
+	if filepath.Ext(path) != ".json" {
+		return nil, false
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var file interface{}
+	if err := json.Unmarshal(contents, &file); err != nil {
+		return nil, false
+	}
+	parameters := map[string]interface{}{}
+	switch f := file.(type) {
+	case []interface{}:
+		for _, p := range f {
+			parameter, _ := p.(map[string]interface{})
+			key, ok := parameter["ParameterKey"].(string)
+			if !ok {
+				return nil, false
+			}
+			if value, ok := parameter["ParameterValue"]; ok {
+				parameters[key] = value
+			}
+		}
+		return parameters, len(f) > 0
+	case map[string]interface{}:
+		values, ok := f["Parameters"].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		for key, value := range values {
+			if _, ok := value.(string); !ok {
+				return nil, false
+			}
+			parameters[key] = value
+		}
+		return parameters, true
+	}
+	return nil, false
+}
@@ -0,0 +1,240 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// loadStack adds the resources of a template to resources, after evaluating
+// its intrinsic functions with the given parameter values, applying the SAM
+// transform and expanding nested stacks with a local TemplateURL.  The
+// resources of a nested stack are prefixed with the ID of the stack resource,
+// and their references and parameters are resolved in the scope of the nested
+// template.
+func (l *cfnConfiguration) loadStack(
+	path string,
+	template map[string]interface{},
+	source *SourceInfoNode,
+	stack string,
+	parameters map[string]interface{},
+	ancestors []string,
+	resources map[string]interface{},
+) {
+	template = newCfnEvaluator(template, parameters).template(template)
+	stackResources, origins := applySamTransform(template)
+	var scope *cfnScope
+	if stack != "" {
+		ids := map[string]string{}
+		for logicalId := range stackResources {
+			ids[logicalId] = stack + "." + logicalId
+		}
+		scope = newCfnScope(ids, template["Parameters"], parameters)
+	}
+
+	logicalIds := []string{}
+	for logicalId := range stackResources {
+		logicalIds = append(logicalIds, logicalId)
+	}
+	sort.Strings(logicalIds)
+	for _, logicalId := range logicalIds {
+		id := logicalId
+		resource := stackResources[logicalId]
+		if scope != nil {
+			id = scope.ids[logicalId]
+			resource = scope.rewrite(resource)
+		}
+		resources[id] = resource
+		origin, generated := origins[logicalId]
+		if !generated {
+			origin = logicalId
+		}
+		l.sources[id] = cfnResourceSource{
+			path:      path,
+			source:    source,
+			logicalId: origin,
+			generated: generated,
+			stack:     stack,
+		}
+		l.loadNestedStack(path, id, resource, ancestors, resources)
+	}
+}
+
+func (l *cfnConfiguration) loadNestedStack(
+	path string,
+	id string,
+	resource interface{},
+	ancestors []string,
+	resources map[string]interface{},
+) {
+	r, _ := resource.(map[string]interface{})
+	if r["Type"] != "AWS::CloudFormation::Stack" {
+		return
+	}
+	properties, _ := r["Properties"].(map[string]interface{})
+	templateURL, _ := properties["TemplateURL"].(string)
+	if templateURL == "" || strings.Contains(templateURL, "://") {
+		return
+	}
+	if !filepath.IsAbs(templateURL) {
+		templateURL = filepath.Join(filepath.Dir(path), templateURL)
+	}
+	for _, ancestor := range ancestors {
+		if ancestor == templateURL {
+			logrus.Warnf("Not loading nested stack %v of %v, since it includes itself", id, path)
+			return
+		}
+	}
+
+	contents, err := os.ReadFile(templateURL)
+	if err != nil {
+		logrus.Warnf("Failed to load nested stack %v of %v: %v", id, path, err)
+		return
+	}
+	template := &cfnTemplate{}
+	if err := yaml.Unmarshal(contents, &template); err != nil || template == nil {
+		logrus.Warnf("Failed to parse nested stack %v of %v: %v", id, path, err)
+		return
+	}
+	source, err := LoadSourceInfoNode(contents)
+	if err != nil {
+		source = nil
+	}
+	parameters, _ := properties["Parameters"].(map[string]interface{})
+	l.files = append(l.files, templateURL)
+	l.loadStack(templateURL, template.Contents, source, id, parameters, append(ancestors, templateURL), resources)
+}
+
+// cfnScope rewrites the resources of a nested stack so they can be merged
+// into the resources of the root template: references to resources in the
+// nested template are renamed to their new IDs, and references to its
+// parameters are replaced by the values passed in by the parent stack, or
+// their defaults.
+type cfnScope struct {
+	// New IDs of the resources in the template, by logical ID.
+	ids        map[string]string
+	parameters map[string]interface{}
+}
+
+func newCfnScope(ids map[string]string, declared interface{}, passed map[string]interface{}) *cfnScope {
+	parameters := map[string]interface{}{}
+	if declared, ok := declared.(map[string]interface{}); ok {
+		for name, parameter := range declared {
+			if value, ok := passed[name]; ok {
+				parameters[name] = value
+			} else if parameter, ok := parameter.(map[string]interface{}); ok {
+				if value, ok := parameter["Default"]; ok {
+					parameters[name] = value
+				}
+			}
+		}
+	}
+	return &cfnScope{
+		ids:        ids,
+		parameters: parameters,
+	}
+}
+
+func (s *cfnScope) rewrite(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 1 {
+			if ref, ok := v["Ref"].(string); ok {
+				if id, ok := s.ids[ref]; ok {
+					return map[string]interface{}{"Ref": id}
+				}
+				// Parameter values are already in the scope of the parent.
+				if value, ok := s.parameters[ref]; ok {
+					return value
+				}
+				return v
+			}
+			getAtt, ok := v["Fn::GetAtt"].([]interface{})
+			if names, isStrings := v["Fn::GetAtt"].([]string); isStrings {
+				// The short form is split when it is decoded.
+				getAtt, ok = []interface{}{}, true
+				for _, name := range names {
+					getAtt = append(getAtt, name)
+				}
+			}
+			if ok && len(getAtt) > 0 {
+				if name, ok := getAtt[0].(string); ok {
+					if id, ok := s.ids[name]; ok {
+						rewritten := []interface{}{id}
+						for _, attribute := range getAtt[1:] {
+							rewritten = append(rewritten, s.rewrite(attribute))
+						}
+						return map[string]interface{}{"Fn::GetAtt": rewritten}
+					}
+				}
+			}
+			switch sub := v["Fn::Sub"].(type) {
+			case string:
+				return map[string]interface{}{"Fn::Sub": s.rewriteSub(sub, nil)}
+			case []interface{}:
+				if len(sub) == 2 {
+					if template, ok := sub[0].(string); ok {
+						variables, _ := sub[1].(map[string]interface{})
+						return map[string]interface{}{"Fn::Sub": []interface{}{
+							s.rewriteSub(template, variables),
+							s.rewrite(sub[1]),
+						}}
+					}
+				}
+			}
+		}
+		rewritten := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			rewritten[k] = s.rewrite(child)
+		}
+		return rewritten
+	case []interface{}:
+		rewritten := make([]interface{}, len(v))
+		for i, child := range v {
+			rewritten[i] = s.rewrite(child)
+		}
+		return rewritten
+	default:
+		return value
+	}
+}
+
+var cfnSubVariable = regexp.MustCompile(`\$\{([A-Za-z0-9:]+)((?:\.[A-Za-z0-9]+)*)\}`)
+
+// rewriteSub rewrites the variables in a Fn::Sub template, except for the
+// ones that are defined by the Fn::Sub itself.
+func (s *cfnScope) rewriteSub(template string, variables map[string]interface{}) string {
+	return cfnSubVariable.ReplaceAllStringFunc(template, func(match string) string {
+		submatches := cfnSubVariable.FindStringSubmatch(match)
+		name, attribute := submatches[1], submatches[2]
+		if _, ok := variables[name]; ok {
+			return match
+		}
+		if id, ok := s.ids[name]; ok {
+			return "${" + id + attribute + "}"
+		}
+		if value, ok := s.parameters[name].(string); ok && attribute == "" {
+			return value
+		}
+		return match
+	})
+}
@@ -213,3 +213,190 @@ func coerceRegulaInput(t *testing.T, regulaInput loader.RegulaInput) loader.Regu
 
 	return coerced
 }
+
+func loadCfnTemplate(t *testing.T, path string) (loader.LoadedConfigurations, map[string]interface{}) {
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{path},
+		InputTypes: []loader.InputType{loader.Cfn},
+	})()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, loadedConfigs.Count())
+	content := loadedConfigs.RegulaInput()[0]["content"].(map[string]interface{})
+	return loadedConfigs, content["Resources"].(map[string]interface{})
+}
+
+func cfnProperties(t *testing.T, resources map[string]interface{}, id string) map[string]interface{} {
+	resource, ok := resources[id].(map[string]interface{})
+	assert.True(t, ok, id)
+	properties, _ := resource["Properties"].(map[string]interface{})
+	return properties
+}
+
+func TestCfnNestedStacks(t *testing.T) {
+	root := "cfn_test/nested/root.yaml"
+	network := "cfn_test/nested/network.yaml"
+	subnets := "cfn_test/nested/subnets.yaml"
+	loadedConfigs, resources := loadCfnTemplate(t, root)
+	for _, path := range []string{root, network, subnets} {
+		assert.True(t, loadedConfigs.AlreadyLoaded(path), path)
+	}
+	assert.Len(t, resources, 7)
+	assert.Contains(t, resources, "Remote")
+
+	vpc := cfnProperties(t, resources, "Network.Vpc")
+	assert.Equal(t, "10.0.0.0/16", vpc["CidrBlock"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"Key": "Name", "Value": "main-vpc"},
+	}, vpc["Tags"])
+	flowLog := cfnProperties(t, resources, "Network.FlowLog")
+	assert.Equal(t, map[string]interface{}{"Ref": "Network.Vpc"}, flowLog["ResourceId"])
+	assert.Equal(t, map[string]interface{}{"Ref": "Logs"}, flowLog["LogDestination"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"Key": "Network", "Value": map[string]interface{}{"Fn::GetAtt": []interface{}{"Network.Vpc", "CidrBlock"}}},
+	}, flowLog["Tags"])
+	subnet := cfnProperties(t, resources, "Network.Subnets.Subnet")
+	assert.Equal(t, map[string]interface{}{"Ref": "Network.Vpc"}, subnet["VpcId"])
+	assert.Equal(t, map[string]interface{}{"Fn::Sub": "${AWS::Region}-${Unknown}"}, subnet["CidrBlock"])
+
+	location, err := loadedConfigs.Location(root, []string{"Network.Subnets.Subnet"})
+	assert.Nil(t, err)
+	assert.Equal(t, loader.LocationStack{
+		{Path: subnets, Line: 6, Col: 3},
+		{Path: network, Line: 29, Col: 3},
+		{Path: root, Line: 5, Col: 3},
+	}, location)
+}
+
+func TestCfnNestedStacksDirectory(t *testing.T) {
+	// network.yaml comes before root.yaml in the walk, but is only loaded
+	// as a part of root.yaml.
+	root := "cfn_test/nested/root.yaml"
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{"cfn_test/nested"},
+		InputTypes: []loader.InputType{loader.Auto},
+	})()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{root}, loadedConfigs.Paths())
+	for _, path := range []string{"cfn_test/nested/network.yaml", "cfn_test/nested/subnets.yaml"} {
+		assert.Equal(t, root, *loadedConfigs.ConfigurationPath(path), path)
+	}
+	content := loadedConfigs.RegulaInput()[0]["content"].(map[string]interface{})
+	assert.Len(t, content["Resources"], 7)
+}
+
+func TestCfnSamTransform(t *testing.T) {
+	path := "cfn_test/sam.yaml"
+	loadedConfigs, resources := loadCfnTemplate(t, path)
+	types := map[string]interface{}{}
+	for id, resource := range resources {
+		types[id] = resource.(map[string]interface{})["Type"]
+	}
+	assert.Equal(t, map[string]interface{}{
+		"Api":                         "AWS::ApiGateway::RestApi",
+		"ApiDeployment":               "AWS::ApiGateway::Deployment",
+		"ApiDomainName":               "AWS::ApiGateway::DomainName",
+		"Apiv1Stage":                  "AWS::ApiGateway::Stage",
+		"Bucket":                      "AWS::S3::Bucket",
+		"Handler":                     "AWS::Lambda::Function",
+		"HandlerGetPermission":        "AWS::Lambda::Permission",
+		"HandlerNightly":              "AWS::Events::Rule",
+		"HandlerNightlyPermission":    "AWS::Lambda::Permission",
+		"HandlerRole":                 "AWS::IAM::Role",
+		"ServerlessRestApi":           "AWS::ApiGateway::RestApi",
+		"ServerlessRestApiDeployment": "AWS::ApiGateway::Deployment",
+		"ServerlessRestApiProdStage":  "AWS::ApiGateway::Stage",
+		"Table":                       "AWS::DynamoDB::Table",
+	}, types)
+
+	function := cfnProperties(t, resources, "Handler")
+	assert.Equal(t, "python3.9", function["Runtime"])
+	assert.Equal(t, map[string]interface{}{"Mode": "Active"}, function["TracingConfig"])
+	assert.Equal(t, map[string]interface{}{"S3Bucket": "artifacts", "S3Key": "handler.zip"}, function["Code"])
+	assert.Equal(t, map[string]interface{}{
+		"Variables": map[string]interface{}{
+			"STAGE": "prod",
+			"TABLE": map[string]interface{}{"Ref": "Table"},
+		},
+	}, function["Environment"])
+	role := cfnProperties(t, resources, "HandlerRole")
+	assert.Equal(t, []interface{}{
+		"arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole",
+		"arn:aws:iam::aws:policy/AWSXrayWriteOnlyAccess",
+		"arn:aws:iam::aws:policy/AmazonDynamoDBReadOnlyAccess",
+	}, role["ManagedPolicyArns"])
+	assert.Len(t, role["Policies"], 1)
+	stage := cfnProperties(t, resources, "Apiv1Stage")
+	assert.Equal(t, true, stage["TracingEnabled"])
+	table := cfnProperties(t, resources, "Table")
+	assert.Equal(t, "PAY_PER_REQUEST", table["BillingMode"])
+
+	// Generated resources are located at the SAM resource.
+	for _, id := range []string{"Handler", "HandlerRole", "ServerlessRestApi"} {
+		location, err := loadedConfigs.Location(path, []string{id, "Role"})
+		assert.Nil(t, err)
+		assert.Equal(t, loader.LocationStack{{Path: path, Line: 11, Col: 3}}, location, id)
+	}
+}
+
+func TestCfnConditionsAndParameters(t *testing.T) {
+	path := "cfn_test/conditions.yaml"
+	_, resources := loadCfnTemplate(t, path)
+	assert.Len(t, resources, 2)
+	assert.NotContains(t, resources, "ProdAlarm")
+	bucket := cfnProperties(t, resources, "Bucket")
+	assert.Equal(t, map[string]interface{}{"Fn::Sub": "dev-${AWS::AccountId}-data"}, bucket["BucketName"])
+	assert.NotContains(t, bucket, "BucketEncryption")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"Key": "Name", "Value": "dev-bucket"},
+		map[string]interface{}{"Key": "Zone", "Value": "us-east-1b"},
+		map[string]interface{}{"Key": "Key", "Value": map[string]interface{}{
+			"_unknown": map[string]interface{}{"Ref": "KeyArn"},
+		}},
+		map[string]interface{}{"Key": "Vpc", "Value": map[string]interface{}{
+			"_unknown": map[string]interface{}{"Fn::ImportValue": "network-vpc"},
+		}},
+	}, bucket["Tags"])
+	logGroup := cfnProperties(t, resources, "LogGroup")
+	assert.Equal(t, 7, logGroup["RetentionInDays"])
+
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{path},
+		InputTypes: []loader.InputType{loader.Cfn},
+		VarFiles:   []string{"cfn_test/conditions.parameters.json"},
+	})()
+	assert.Nil(t, err)
+	content := loadedConfigs.RegulaInput()[0]["content"].(map[string]interface{})
+	resources = content["Resources"].(map[string]interface{})
+	assert.Len(t, resources, 3)
+	bucket = cfnProperties(t, resources, "Bucket")
+	assert.Equal(t, map[string]interface{}{
+		"ServerSideEncryptionConfiguration": []interface{}{
+			map[string]interface{}{
+				"ServerSideEncryptionByDefault": map[string]interface{}{
+					"SSEAlgorithm":   "aws:kms",
+					"KMSMasterKeyID": "arn:aws:kms:us-east-1:123456789012:key/example",
+				},
+			},
+		},
+	}, bucket["BucketEncryption"])
+	logGroup = cfnProperties(t, resources, "LogGroup")
+	assert.Equal(t, 365, logGroup["RetentionInDays"])
+	tags := bucket["Tags"].([]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"Key":   "Key",
+		"Value": "arn:aws:kms:us-east-1:123456789012:key/example",
+	}, tags[2])
+	alarm := cfnProperties(t, resources, "ProdAlarm")
+	assert.Equal(t, map[string]interface{}{"Fn::Sub": []interface{}{
+		"prod-${Name}",
+		map[string]interface{}{"Name": map[string]interface{}{"Ref": "Bucket"}},
+	}}, alarm["AlarmName"])
+}
+
+func TestCfnParameterFileIsNotATemplate(t *testing.T) {
+	_, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{"cfn_test/conditions.parameters.json"},
+		InputTypes: []loader.InputType{loader.Cfn},
+	})()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ChangedConfigurations returns a LoadedConfigurations with only those
+// configurations from configs that loaded at least one of the given files.
+// A file that no longer exists is attributed to the configuration that loaded
+// its closest existing directory, so that e.g. deleting a file from a
+// Terraform module still causes the root module to be evaluated.
+func ChangedConfigurations(configs LoadedConfigurations, files []string) LoadedConfigurations {
+	changed := map[string]bool{}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			f = closestExistingDir(f)
+		}
+		if p := owningConfigurationPath(configs, f); p != nil {
+			logrus.Debugf("%s changed configuration %s", f, *p)
+			changed[*p] = true
+		}
+	}
+
+	filtered := newLoadedConfigurations()
+	for _, p := range configs.Paths() {
+		if changed[p] {
+			filtered.AddConfiguration(p, configs.Configuration(p))
+		}
+	}
+	return filtered
+}
+
+// owningConfigurationPath looks up a path both as given and relative to the
+// working directory, since configurations are keyed by the paths that were
+// passed to the loader.
+func owningConfigurationPath(configs LoadedConfigurations, path string) *string {
+	if p := configs.ConfigurationPath(path); p != nil {
+		return p
+	}
+	if !filepath.IsAbs(path) {
+		return nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil {
+		return nil
+	}
+	return configs.ConfigurationPath(rel)
+}
+
+func closestExistingDir(path string) string {
+	dir := filepath.Dir(path)
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Fingerprint returns a hash of the paths and contents of all files loaded by
+// a configuration, together with the given var files.  It returns an empty
+// string if the configuration can't be fingerprinted, e.g. when it was read
+// from stdin.
+func Fingerprint(config IACConfiguration, varFiles []string) string {
+	files := append([]string{}, config.LoadedFiles()...)
+	sort.Strings(files)
+
+	hasher := sha256.New()
+	add := func(kind string, path string) bool {
+		if path == stdIn {
+			return false
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			// LoadedFiles may contain directories and paths that don't
+			// exist, such as a missing `.terraform` directory.
+			fmt.Fprintf(hasher, "%s %q missing\n", kind, path)
+			return true
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return false
+		}
+		sum := sha256.Sum256(contents)
+		fmt.Fprintf(hasher, "%s %q %x\n", kind, path, sum)
+		return true
+	}
+
+	for _, f := range files {
+		if !add("file", f) {
+			return ""
+		}
+	}
+	for _, f := range varFiles {
+		if !add("var-file", f) {
+			return ""
+		}
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// Fingerprints returns the Fingerprint of every configuration, in the same
+// order as RegulaInput.
+func Fingerprints(configs LoadedConfigurations, varFiles []string) []string {
+	fingerprints := []string{}
+	for _, path := range configs.Paths() {
+		fingerprints = append(fingerprints, Fingerprint(configs.Configuration(path), varFiles))
+	}
+	return fingerprints
+}
@@ -0,0 +1,295 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	helmloader "helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+const helmChartFile = "Chart.yaml"
+
+// The release that charts are rendered for, like `helm template` does.
+const (
+	helmReleaseName      = "release-name"
+	helmReleaseNamespace = "default"
+)
+
+// isHelmValuesFile returns true for --var-file paths that are Helm values
+// files rather than Terraform variable files.
+func isHelmValuesFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// HelmDetector renders Helm charts and loads the rendered manifests like
+// Kubernetes manifests.
+type HelmDetector struct{}
+
+func (c *HelmDetector) DetectFile(i InputFile, opts DetectOptions) (IACConfiguration, error) {
+	if i.Name() != helmChartFile {
+		return nil, fmt.Errorf("Expected a %s file for a Helm chart: %v", helmChartFile, i.Path())
+	}
+	return loadHelmChart(filepath.Dir(i.Path()), opts)
+}
+
+func (c *HelmDetector) DetectDirectory(i InputDirectory, opts DetectOptions) (IACConfiguration, error) {
+	if opts.IgnoreDirs {
+		return nil, nil
+	}
+	for _, child := range i.Children() {
+		if f, ok := child.(InputFile); ok && f.Name() == helmChartFile {
+			return loadHelmChart(i.Path(), opts)
+		}
+	}
+	return nil, nil
+}
+
+func loadHelmChart(dir string, opts DetectOptions) (IACConfiguration, error) {
+	chrt, err := helmloader.Load(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load Helm chart %v: %v", dir, err)
+	}
+
+	values := map[string]interface{}{}
+	for _, varFile := range opts.VarFiles {
+		if !isHelmValuesFile(varFile) {
+			continue
+		}
+		fileValues, err := chartutil.ReadValuesFile(varFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read Helm values file %v: %v", varFile, err)
+		}
+		values = helmMergeValues(values, fileValues)
+	}
+	if err := chartutil.ProcessDependencies(chrt, values); err != nil {
+		return nil, fmt.Errorf("Failed to process dependencies of Helm chart %v: %v", dir, err)
+	}
+	renderValues, err := chartutil.ToRenderValues(chrt, values, chartutil.ReleaseOptions{
+		Name:      helmReleaseName,
+		Namespace: helmReleaseNamespace,
+		Revision:  1,
+		IsInstall: true,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to render Helm chart %v: %v", dir, err)
+	}
+	// DNS lookups are left disabled, so that rendering a chart doesn't reach
+	// out to the network.
+	rendered, err := engine.Engine{}.Render(chrt, renderValues)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to render Helm chart %v: %v", dir, err)
+	}
+
+	resources := map[string]interface{}{}
+	sources := map[string]helmResourceSource{}
+	// Resources with a generateName are numbered across all templates.
+	ids := NewK8sResourceIDs()
+	names := []string{}
+	for name := range rendered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !validK8sExts[path.Ext(name)] || strings.TrimSpace(rendered[name]) == "" {
+			continue
+		}
+		templatePath := helmTemplatePath(dir, chrt, name)
+		documentSources := map[string]SourceInfoNode{}
+		resourceIds, err := loadK8sDocuments(templatePath, []byte(rendered[name]), ids, resources, documentSources)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load rendered template %v: %v", name, err)
+		}
+		lines := strings.Split(rendered[name], "\n")
+		for _, id := range resourceIds {
+			sources[id] = helmResourceSource{
+				template: templatePath,
+				rendered: lines,
+				node:     documentSources[id],
+			}
+		}
+	}
+
+	files := []string{}
+	walkDirFunc := func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	}
+	if err := filepath.WalkDir(dir, walkDirFunc); err != nil {
+		return nil, err
+	}
+
+	return &helmConfiguration{
+		path: dir,
+		content: map[string]interface{}{
+			"k8s_resource_view_version": "0.0.1",
+			"resources":                 resources,
+		},
+		sources:   sources,
+		templates: map[string][]string{},
+		files:     files,
+	}, nil
+}
+
+// helmTemplatePath finds the file on disk for a rendered template, which is
+// named after the chart, e.g. `mychart/charts/redis/templates/service.yaml`.
+// Subcharts can be packaged, in which case there is no such file.
+func helmTemplatePath(dir string, chrt *chart.Chart, name string) string {
+	relative := strings.TrimPrefix(name, chrt.Name()+"/")
+	templatePath := filepath.Join(dir, filepath.FromSlash(relative))
+	if _, err := os.Stat(templatePath); err != nil {
+		return ""
+	}
+	return templatePath
+}
+
+// helmMergeValues merges values files in the same way as `helm template -f`,
+// with nested maps merged and the values in override taking precedence.
+func helmMergeValues(base map[string]interface{}, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		if overrideMap, ok := v.(map[string]interface{}); ok {
+			if baseMap, ok := merged[k].(map[string]interface{}); ok {
+				merged[k] = helmMergeValues(baseMap, overrideMap)
+				continue
+			}
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+type helmResourceSource struct {
+	// Path of the template that rendered the resource, if it is known.
+	template string
+	// Rendered lines of the template.
+	rendered []string
+	node     SourceInfoNode
+}
+
+type helmConfiguration struct {
+	path      string
+	content   map[string]interface{}
+	sources   map[string]helmResourceSource
+	templates map[string][]string
+	files     []string
+}
+
+func (l *helmConfiguration) RegulaInput() RegulaInput {
+	return RegulaInput{
+		"filepath": l.path,
+		"content":  l.content,
+	}
+}
+
+// Location maps the resource or attribute in the rendered template back to
+// the template.  Lines that are not templated are found as-is, otherwise we
+// look for the key of the attribute, and fall back to the resource.  If there
+// are several candidates, the line closest to the rendered line is used.
+func (l *helmConfiguration) Location(attributePath []string) (LocationStack, error) {
+	if len(attributePath) < 1 {
+		return nil, nil
+	}
+	source, ok := l.sources[attributePath[0]]
+	if !ok || source.template == "" {
+		return nil, nil
+	}
+	templateLines, ok := l.templates[source.template]
+	if !ok {
+		contents, err := os.ReadFile(source.template)
+		if err != nil {
+			return nil, nil
+		}
+		templateLines = strings.Split(string(contents), "\n")
+		l.templates[source.template] = templateLines
+	}
+
+	resource := &source.node
+	node, _ := resource.GetPath(attributePath[1:])
+	candidates := []*SourceInfoNode{node}
+	if node != resource {
+		candidates = append(candidates, resource)
+	}
+	for _, candidate := range candidates {
+		line, _ := candidate.Location()
+		if line < 1 || line > len(source.rendered) {
+			continue
+		}
+		matchers := []func(string) bool{
+			func(templateLine string) bool {
+				return strings.TrimSpace(templateLine) == strings.TrimSpace(source.rendered[line-1])
+			},
+		}
+		if candidate.key != nil {
+			key := candidate.key.Value + ":"
+			matchers = append(matchers, func(templateLine string) bool {
+				trimmed := strings.TrimLeft(strings.TrimSpace(templateLine), "- ")
+				return strings.HasPrefix(trimmed, key)
+			})
+		}
+		for _, matches := range matchers {
+			if templateLine := helmClosestLine(templateLines, line, matches); templateLine > 0 {
+				text := templateLines[templateLine-1]
+				column := len(text) - len(strings.TrimLeft(text, " ")) + 1
+				return []Location{{Path: source.template, Line: templateLine, Col: column}}, nil
+			}
+		}
+	}
+	return []Location{{Path: source.template, Line: 1, Col: 1}}, nil
+}
+
+// helmClosestLine returns the number of the matching line closest to the
+// given line, or 0 if no line matches.
+func helmClosestLine(lines []string, line int, matches func(string) bool) int {
+	closest := 0
+	for i, text := range lines {
+		if !matches(text) {
+			continue
+		}
+		if closest == 0 || abs(i+1-line) < abs(closest-line) {
+			closest = i + 1
+		}
+	}
+	return closest
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func (l *helmConfiguration) LoadedFiles() []string {
+	return l.files
+}
@@ -0,0 +1,146 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/stretchr/testify/assert"
+)
+
+const helmChart = "helm_test/webapp"
+const helmDeployment = "Deployment.default.release-name-webapp"
+
+func loadHelmChart(t *testing.T, inputType loader.InputType, varFiles ...string) loader.LoadedConfigurations {
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{helmChart},
+		InputTypes: []loader.InputType{inputType},
+		VarFiles:   varFiles,
+	})()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, loadedConfigs.Count())
+	return loadedConfigs
+}
+
+func helmResources(t *testing.T, loadedConfigs loader.LoadedConfigurations) map[string]interface{} {
+	content, ok := loadedConfigs.RegulaInput()[0]["content"].(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, "0.0.1", content["k8s_resource_view_version"])
+	resources, ok := content["resources"].(map[string]interface{})
+	assert.True(t, ok)
+	return resources
+}
+
+func helmContainer(t *testing.T, resource interface{}) map[string]interface{} {
+	template := resource.(map[string]interface{})["spec"].(map[string]interface{})["template"]
+	spec := template.(map[string]interface{})["spec"].(map[string]interface{})
+	containers := spec["containers"].([]interface{})
+	assert.Len(t, containers, 1)
+	return containers[0].(map[string]interface{})
+}
+
+func TestHelmChart(t *testing.T) {
+	for _, inputType := range []loader.InputType{loader.Auto, loader.Helm} {
+		loadedConfigs := loadHelmChart(t, inputType)
+		assert.True(t, loadedConfigs.AlreadyLoaded(helmChart+"/templates/deployment.yaml"))
+		assert.True(t, loadedConfigs.AlreadyLoaded(helmChart+"/values.yaml"))
+
+		resources := helmResources(t, loadedConfigs)
+		assert.Len(t, resources, 2)
+		assert.Contains(t, resources, "Service.default.release-name-webapp")
+		container := helmContainer(t, resources[helmDeployment])
+		assert.Equal(t, "nginx:1.23", container["image"])
+		assert.Equal(t, map[string]interface{}{"privileged": false}, container["securityContext"])
+	}
+}
+
+func TestHelmChartValuesFiles(t *testing.T) {
+	loadedConfigs := loadHelmChart(t, loader.Helm, "helm_test/values-prod.yaml", "helm_test/missing.tfvars")
+	resources := helmResources(t, loadedConfigs)
+	assert.Len(t, resources, 1)
+	container := helmContainer(t, resources[helmDeployment])
+	assert.Equal(t, "nginx:1.23", container["image"])
+	assert.Equal(t, map[string]interface{}{"privileged": true}, container["securityContext"])
+}
+
+func TestHelmChartLocation(t *testing.T) {
+	loadedConfigs := loadHelmChart(t, loader.Helm)
+	containerPath := []string{"spec", "template", "spec", "containers", "0"}
+	testCases := []struct {
+		path []string
+		line int
+		col  int
+	}{
+		{path: []string{}, line: 1, col: 1},
+		// Templated line
+		{path: append(containerPath, "image"), line: 19, col: 11},
+		{path: append(containerPath, "securityContext", "privileged"), line: 21, col: 13},
+		// Static line
+		{path: append(containerPath, "ports", "0", "containerPort"), line: 23, col: 13},
+		// Missing attributes fall back to the closest parent
+		{path: append(containerPath, "securityContext", "runAsUser"), line: 20, col: 11},
+	}
+	for _, tc := range testCases {
+		location, err := loadedConfigs.Location(helmChart, append([]string{helmDeployment}, tc.path...))
+		assert.Nil(t, err)
+		assert.Equal(t, []loader.Location{{
+			Path: helmChart + "/templates/deployment.yaml",
+			Line: tc.line,
+			Col:  tc.col,
+		}}, location, "%v", tc.path)
+	}
+}
+
+func TestHelmChartGenerateName(t *testing.T) {
+	chart := "helm_test/jobs"
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{chart},
+		InputTypes: []loader.InputType{loader.Helm},
+	})()
+	assert.Nil(t, err)
+	resources := helmResources(t, loadedConfigs)
+	assert.Len(t, resources, 2)
+	for idx, template := range []string{"migrate", "seed"} {
+		id := fmt.Sprintf("Job.default.release-name-job-[%d]", idx)
+		assert.Contains(t, resources, id)
+		assert.Equal(t, template, helmContainer(t, resources[id])["name"])
+		location, err := loadedConfigs.Location(chart, []string{id})
+		assert.Nil(t, err)
+		assert.Equal(t, []loader.Location{{
+			Path: chart + "/templates/" + template + ".yaml",
+			Line: 1,
+			Col:  1,
+		}}, location)
+	}
+}
+
+func TestHelmChartNoDNSLookups(t *testing.T) {
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{"helm_test/dns"},
+		InputTypes: []loader.InputType{loader.Helm},
+	})()
+	assert.Nil(t, err)
+	resources := helmResources(t, loadedConfigs)
+	configMap, ok := resources["ConfigMap.default.release-name-hosts"].(map[string]interface{})
+	assert.True(t, ok)
+	// getHostByName would resolve localhost without a network if lookups
+	// were enabled.
+	assert.Equal(t, map[string]interface{}{
+		"localhost": "",
+		"registry":  "",
+	}, configMap["data"])
+}
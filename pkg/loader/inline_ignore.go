@@ -0,0 +1,118 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// InlineIgnore is a `regula:ignore` annotation next to a resource in IaC
+// source code, e.g.:
+//
+//     # regula:ignore FG_R00229,FG_R00100 reason="Public website"
+type InlineIgnore struct {
+	// Rules contains the IDs or names of the rules to ignore.
+	Rules  []string
+	Reason string
+	// Location is where the annotation was found.
+	Location Location
+}
+
+// Matches checks if the annotation applies to a rule.
+func (i InlineIgnore) Matches(ruleID string, ruleName string) bool {
+	for _, r := range i.Rules {
+		if r == "*" || (ruleID != "" && r == ruleID) || (ruleName != "" && r == ruleName) {
+			return true
+		}
+	}
+	return false
+}
+
+var inlineIgnoreRegex = regexp.MustCompile(
+	`regula:ignore\s+([A-Za-z0-9_.*-]+(?:\s*,\s*[A-Za-z0-9_.*-]+)*)(?:\s+reason\s*=\s*"((?:[^"\\]|\\.)*)")?`,
+)
+
+func parseInlineIgnores(text string, location Location) []InlineIgnore {
+	ignores := []InlineIgnore{}
+	for _, match := range inlineIgnoreRegex.FindAllStringSubmatch(text, -1) {
+		rules := []string{}
+		for _, r := range strings.Split(match[1], ",") {
+			rules = append(rules, strings.TrimSpace(r))
+		}
+		ignores = append(ignores, InlineIgnore{
+			Rules:    rules,
+			Reason:   strings.ReplaceAll(match[2], `\"`, `"`),
+			Location: location,
+		})
+	}
+	return ignores
+}
+
+// inlineIgnoreProvider can be implemented by configurations that carry inline
+// ignores in the configuration itself rather than in comments, which is the
+// case for formats without comments such as ARM templates.
+type inlineIgnoreProvider interface {
+	InlineIgnores(resourceID string) []InlineIgnore
+}
+
+func isCommentLine(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, prefix := range []string{"#", "//", "/*", "*"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// commentInlineIgnores finds the annotations in the comments directly above
+// the given location and on the same line.
+func commentInlineIgnores(lines []string, loc Location) []InlineIgnore {
+	if loc.Line < 1 || loc.Line > len(lines) {
+		return nil
+	}
+	ignores := []InlineIgnore{}
+	parseLine := func(line int) {
+		text := lines[line-1]
+		ignores = append(ignores, parseInlineIgnores(text, Location{
+			Path: loc.Path,
+			Line: line,
+			Col:  strings.Index(text, "regula:ignore") + 1,
+		})...)
+	}
+	parseLine(loc.Line)
+	for line := loc.Line - 1; line >= 1 && isCommentLine(lines[line-1]); line-- {
+		parseLine(line)
+	}
+	return ignores
+}
+
+func readLines(path string) ([]string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	scanner.Buffer(nil, len(contents)+1)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
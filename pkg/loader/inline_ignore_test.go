@@ -0,0 +1,97 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader_test
+
+import (
+	"testing"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInlineIgnores(t *testing.T) {
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{"test_inputs/data/inline_ignore"},
+		InputTypes: []loader.InputType{loader.Auto},
+	})()
+	assert.Nil(t, err)
+
+	dir := "test_inputs/data/inline_ignore"
+	testCases := []struct {
+		path       string
+		resourceID string
+		expected   []loader.InlineIgnore
+	}{
+		{
+			path:       dir + "/main.tf",
+			resourceID: "aws_s3_bucket.ignored",
+			expected: []loader.InlineIgnore{{
+				Rules:    []string{"FG_R00100", "FG_R00101"},
+				Reason:   "Logs are shipped elsewhere",
+				Location: loader.Location{Path: dir + "/main.tf", Line: 1, Col: 3},
+			}},
+		},
+		{
+			path:       dir + "/main.tf",
+			resourceID: "aws_s3_bucket.trailing",
+			expected: []loader.InlineIgnore{{
+				Rules:    []string{"FG_R00229"},
+				Location: loader.Location{Path: dir + "/main.tf", Line: 6, Col: 41},
+			}},
+		},
+		{
+			path:       dir + "/main.tf",
+			resourceID: "aws_s3_bucket.separated",
+			expected:   []loader.InlineIgnore{},
+		},
+		{
+			path:       dir + "/cfn.yaml",
+			resourceID: "Bucket",
+			expected: []loader.InlineIgnore{{
+				Rules:    []string{"FG_R00229"},
+				Reason:   `Public "website"`,
+				Location: loader.Location{Path: dir + "/cfn.yaml", Line: 4, Col: 5},
+			}},
+		},
+		{
+			path:       dir + "/arm.json",
+			resourceID: "Microsoft.Network/virtualNetworks/vnet/subnets/subnet",
+			expected: []loader.InlineIgnore{
+				{
+					Rules:    []string{"FG_R00274"},
+					Location: loader.Location{Path: dir + "/arm.json"},
+				},
+				{
+					Rules:    []string{"tf_azurerm_rule"},
+					Reason:   "Internal only",
+					Location: loader.Location{Path: dir + "/arm.json"},
+				},
+			},
+		},
+		{
+			path:       dir + "/arm.json",
+			resourceID: "Microsoft.Network/virtualNetworks/vnet",
+			expected:   nil,
+		},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, loadedConfigs.InlineIgnores(tc.path, tc.resourceID), tc.resourceID)
+	}
+
+	ignore := loader.InlineIgnore{Rules: []string{"FG_R00229", "my_rule"}}
+	assert.True(t, ignore.Matches("FG_R00229", "tf_aws_s3_block_public_access"))
+	assert.True(t, ignore.Matches("", "my_rule"))
+	assert.False(t, ignore.Matches("FG_R00100", "tf_aws_s3_logging"))
+}
@@ -18,7 +18,10 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 
+	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 )
 
@@ -27,11 +30,11 @@ var validK8sExts map[string]bool = map[string]bool{
 	".yml":  true,
 }
 
-func splitYAML(data []byte) ([]map[string]interface{}, error) {
+func splitYAML(data []byte) ([]interface{}, error) {
 	dec := yaml.NewDecoder(bytes.NewReader(data))
-	var documents []map[string]interface{}
+	var documents []interface{}
 	for {
-		var value map[string]interface{}
+		var value interface{}
 		err := dec.Decode(&value)
 		if err == io.EOF {
 			break
@@ -54,13 +57,6 @@ func (c *KubernetesDetector) DetectFile(i InputFile, opts DetectOptions) (IACCon
 	if err != nil {
 		return nil, err
 	}
-	documents, err := splitYAML(contents)
-	if err != nil {
-		return nil, err
-	}
-	if len(documents) == 0 {
-		return nil, fmt.Errorf("no valid yaml documents found in %v", i.Path())
-	}
 
 	// Model each YAML document as a resource
 	resources := map[string]interface{}{}
@@ -70,42 +66,177 @@ func (c *KubernetesDetector) DetectFile(i InputFile, opts DetectOptions) (IACCon
 	}
 
 	sources := map[string]SourceInfoNode{}
+	resourceIds, err := loadK8sDocuments(i.Path(), contents, NewK8sResourceIDs(), resources, sources)
+	if err != nil {
+		return nil, err
+	}
+	if len(resourceIds) == 0 {
+		return nil, fmt.Errorf("no valid yaml documents found in %v", i.Path())
+	}
+
+	return &k8sConfiguration{
+		path:    i.Path(),
+		content: content,
+		sources: sources,
+	}, nil
+}
+
+// loadK8sDocuments adds the resources in the YAML documents of a manifest to
+// resources and sources, and returns their IDs in order.  Empty documents are
+// skipped, and so are documents that aren't Kubernetes objects as long as the
+// manifest contains some resources.
+func loadK8sDocuments(
+	path string,
+	contents []byte,
+	ids *K8sResourceIDs,
+	resources map[string]interface{},
+	sources map[string]SourceInfoNode,
+) ([]string, error) {
+	documents, err := splitYAML(contents)
+	if err != nil {
+		return nil, err
+	}
+	if len(documents) == 0 {
+		return nil, nil
+	}
 	documentSources, err := LoadMultiSourceInfoNode(contents)
 	if err != nil {
 		return nil, err
 	}
 
+	resourceIds := []string{}
+	skipped := []string{}
 	for documentIdx, document := range documents {
-		var name, namespace string
-		kind, ok := document["kind"]
-		if !ok {
-			return nil, fmt.Errorf("input file does not define a kind: %v", i.Path())
-		}
-		if metadata, ok := document["metadata"].(map[string]interface{}); ok {
-			name, _ = metadata["name"].(string)
-			namespace, _ = metadata["namespace"].(string)
+		if document == nil {
+			continue
 		}
-		if name == "" {
-			return nil, fmt.Errorf("input file does not define a name: %v", i.Path())
+		objects, reasons := ids.Objects(document, fmt.Sprintf("document %d", documentIdx+1))
+		for _, object := range objects {
+			source, _ := documentSources[documentIdx].GetPath(object.Path)
+			resources[object.ID] = object.Object
+			sources[object.ID] = *source
+			resourceIds = append(resourceIds, object.ID)
 		}
-		if namespace == "" {
-			namespace = "default"
+		skipped = append(skipped, reasons...)
+	}
+	if len(resourceIds) == 0 && len(skipped) > 0 {
+		return nil, fmt.Errorf("%v: %s", path, skipped[0])
+	}
+	for _, reason := range skipped {
+		logrus.Warnf("%v: %s, skipping", path, reason)
+	}
+	return resourceIds, nil
+}
+
+// K8sResourceIDs builds the IDs of Kubernetes objects, which are
+// `<kind>.<namespace>.<name>`.  The name of an object that only has a
+// generateName is only known once it is created, so these objects are
+// numbered in order, e.g. `Pod.default.worker-[0]`.  The same K8sResourceIDs
+// must therefore be used for all manifests of a configuration.
+type K8sResourceIDs struct {
+	// Number of resources using each generateName so far.
+	generated map[string]int
+}
+
+func NewK8sResourceIDs() *K8sResourceIDs {
+	return &K8sResourceIDs{generated: map[string]int{}}
+}
+
+// K8sObject is a Kubernetes object in a YAML document.
+type K8sObject struct {
+	ID     string
+	Object map[string]interface{}
+	// Path is the path of the object in its document, which is only
+	// non-empty for the items of a List, e.g. ["items", "0"].
+	Path []string
+}
+
+// Objects returns the Kubernetes objects in a decoded YAML document, or the
+// items of a List, in order.  It also returns why values that aren't
+// Kubernetes objects were skipped, relative to the description of the
+// document.
+func (ids *K8sResourceIDs) Objects(document interface{}, description string) ([]K8sObject, []string) {
+	objects := []K8sObject{}
+	skipped := []string{}
+	ids.objects(document, []string{}, description, &objects, &skipped)
+	return objects, skipped
+}
+
+func (ids *K8sResourceIDs) objects(
+	document interface{},
+	path []string,
+	description string,
+	objects *[]K8sObject,
+	skipped *[]string,
+) {
+	object, ok := document.(map[string]interface{})
+	if !ok {
+		*skipped = append(*skipped, fmt.Sprintf("%s is not a mapping", description))
+		return
+	}
+	kind, _ := object["kind"].(string)
+	apiVersion, _ := object["apiVersion"].(string)
+
+	// Both `kind: List` and typed lists such as `kind: PodList`, whose items
+	// may leave out their kind.
+	if items, ok := object["items"].([]interface{}); ok && strings.HasSuffix(kind, "List") {
+		itemKind := strings.TrimSuffix(kind, "List")
+		for itemIdx, item := range items {
+			if itemObject, ok := item.(map[string]interface{}); ok && itemKind != "" {
+				if _, ok := itemObject["kind"]; !ok {
+					itemObject["kind"] = itemKind
+				}
+				if _, ok := itemObject["apiVersion"]; !ok && apiVersion != "" {
+					itemObject["apiVersion"] = apiVersion
+				}
+			}
+			itemPath := append(append([]string{}, path...), "items", strconv.Itoa(itemIdx))
+			itemDescription := fmt.Sprintf("item %d of %s", itemIdx+1, description)
+			ids.objects(item, itemPath, itemDescription, objects, skipped)
 		}
+		return
+	}
 
-		resourceId := fmt.Sprintf("%s.%s.%s", kind, namespace, name)
-		resources[resourceId] = document
-		sources[resourceId] = documentSources[documentIdx]
+	if kind == "" || apiVersion == "" {
+		*skipped = append(*skipped, fmt.Sprintf("%s does not define a kind and apiVersion", description))
+		return
+	}
+	var name, generateName, namespace string
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		name, _ = metadata["name"].(string)
+		generateName, _ = metadata["generateName"].(string)
+		namespace, _ = metadata["namespace"].(string)
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	if name == "" {
+		if generateName == "" {
+			*skipped = append(*skipped, fmt.Sprintf("%s does not define a name", description))
+			return
+		}
+		// Brackets can't appear in actual names.
+		prefix := fmt.Sprintf("%s.%s.%s", kind, namespace, generateName)
+		name = fmt.Sprintf("%s[%d]", generateName, ids.generated[prefix])
+		ids.generated[prefix] += 1
 	}
 
-	return &k8sConfiguration{
-		path:    i.Path(),
-		content: content,
-		sources: sources,
-	}, nil
+	*objects = append(*objects, K8sObject{
+		ID:     fmt.Sprintf("%s.%s.%s", kind, namespace, name),
+		Object: object,
+		Path:   path,
+	})
 }
 
 func (c *KubernetesDetector) DetectDirectory(i InputDirectory, opts DetectOptions) (IACConfiguration, error) {
-	return nil, nil
+	if opts.IgnoreDirs {
+		return nil, nil
+	}
+	kustomization := kustomizationFile(i)
+	if kustomization == "" {
+		return nil, nil
+	}
+	return loadKustomization(i.Path(), kustomization)
 }
 
 type k8sConfiguration struct {
@@ -0,0 +1,65 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader_test
+
+import (
+	"testing"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestK8sListsAndGenerateName(t *testing.T) {
+	path := "test_inputs/data/k8s_list.yaml"
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{path},
+		InputTypes: []loader.InputType{loader.K8s},
+	})()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, loadedConfigs.Count())
+
+	content := loadedConfigs.RegulaInput()[0]["content"].(map[string]interface{})
+	resources := content["resources"].(map[string]interface{})
+	assert.Len(t, resources, 5)
+	pod := resources["Pod.default.debug"].(map[string]interface{})
+	assert.Equal(t, "Pod", pod["kind"])
+	assert.Equal(t, "v1", pod["apiVersion"])
+
+	testCases := []struct {
+		resourceId string
+		line       int
+		col        int
+	}{
+		{resourceId: "ConfigMap.default.settings", line: 4, col: 3},
+		{resourceId: "Deployment.prod.web", line: 10, col: 3},
+		{resourceId: "Pod.default.debug", line: 21, col: 3},
+		{resourceId: "Job.default.migrate-[0]", line: 28, col: 1},
+		{resourceId: "Job.default.migrate-[1]", line: 34, col: 1},
+	}
+	for _, tc := range testCases {
+		assert.Contains(t, resources, tc.resourceId)
+		location, err := loadedConfigs.Location(path, []string{tc.resourceId})
+		assert.Nil(t, err)
+		assert.Equal(t, []loader.Location{{Path: path, Line: tc.line, Col: tc.col}}, location, tc.resourceId)
+	}
+}
+
+func TestK8sNoValidDocuments(t *testing.T) {
+	_, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{"test_inputs/data/k8s_invalid.yaml"},
+		InputTypes: []loader.InputType{loader.K8s},
+	})()
+	assert.NotNil(t, err)
+}
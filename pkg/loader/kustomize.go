@@ -0,0 +1,274 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// kustomizationFile returns the path of the kustomization file in a
+// directory, or an empty string if it is not a kustomization.
+func kustomizationFile(i InputDirectory) string {
+	for _, child := range i.Children() {
+		if f, ok := child.(InputFile); ok && isKustomizationFileName(f.Name()) {
+			return f.Path()
+		}
+	}
+	return ""
+}
+
+func isKustomizationFileName(name string) bool {
+	for _, recognized := range konfig.RecognizedKustomizationFileNames() {
+		if name == recognized {
+			return true
+		}
+	}
+	return false
+}
+
+// kustomizeFs records the files that kustomize reads while building a
+// kustomization, which are the bases, resources and patches it uses.  It
+// also turns on origin annotations in the kustomization we build, so we can
+// tell which file each resource came from.
+type kustomizeFs struct {
+	filesys.FileSystem
+	kustomization string
+	read          []string
+	injected      bool
+}
+
+func (fs *kustomizeFs) ReadFile(path string) ([]byte, error) {
+	contents, err := fs.FileSystem.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fs.read = append(fs.read, path)
+	if path == fs.kustomization {
+		return fs.withOriginAnnotations(contents)
+	}
+	return contents, nil
+}
+
+func (fs *kustomizeFs) withOriginAnnotations(contents []byte) ([]byte, error) {
+	kustomization := map[string]interface{}{}
+	if err := yaml.Unmarshal(contents, &kustomization); err != nil {
+		return nil, err
+	}
+	buildMetadata, _ := kustomization["buildMetadata"].([]interface{})
+	for _, option := range buildMetadata {
+		if option == types.OriginAnnotations {
+			return contents, nil
+		}
+	}
+	kustomization["buildMetadata"] = append(buildMetadata, types.OriginAnnotations)
+	fs.injected = true
+	return yaml.Marshal(kustomization)
+}
+
+// loadKustomization builds a kustomization like `kustomize build` does, and
+// loads the resulting resources like a Kubernetes manifest.
+func loadKustomization(dir string, kustomization string) (IACConfiguration, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	fs := &kustomizeFs{
+		FileSystem:    filesys.MakeFsOnDisk(),
+		kustomization: filepath.Join(absDir, filepath.Base(kustomization)),
+	}
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resMap, err := kustomizer.Run(fs, absDir)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to build kustomization %v: %v", dir, err)
+	}
+
+	// Resources are located in the file they originate from.  Their names
+	// may have been changed by the overlays, so we look for the definition
+	// with the same kind whose name is part of the new name.  Resources that
+	// don't come from a manifest, such as the output of generators, are
+	// located at the kustomization that produced them.
+	origins := map[string][]kustomizeOrigin{}
+	locations := []Location{}
+	for _, res := range resMap.Resources() {
+		location := Location{Path: kustomization, Line: 1, Col: 1}
+		if origin, err := res.GetOrigin(); err == nil && origin != nil && origin.Repo == "" && origin.Path != "" {
+			path := filepath.Join(dir, origin.Path)
+			if _, ok := origins[path]; !ok {
+				origins[path] = loadKustomizeOrigins(fs.FileSystem, filepath.Join(absDir, origin.Path), path)
+			}
+			location = Location{Path: path, Line: 1, Col: 1}
+			if found, ok := findKustomizeOrigin(origins[path], res.GetKind(), res.GetName()); ok {
+				location = found
+			}
+		}
+		locations = append(locations, location)
+	}
+	if fs.injected {
+		if err := resMap.RemoveOriginAnnotations(); err != nil {
+			return nil, fmt.Errorf("Failed to build kustomization %v: %v", dir, err)
+		}
+	}
+
+	manifest, err := resMap.AsYaml()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to build kustomization %v: %v", dir, err)
+	}
+	resources := map[string]interface{}{}
+	resourceIds, err := loadK8sDocuments(dir, manifest, NewK8sResourceIDs(), resources, map[string]SourceInfoNode{})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load kustomization %v: %v", dir, err)
+	}
+	if len(resourceIds) != len(locations) {
+		return nil, fmt.Errorf("Failed to load kustomization %v: unexpected number of resources", dir)
+	}
+	resourceLocations := map[string]Location{}
+	for idx, id := range resourceIds {
+		resourceLocations[id] = locations[idx]
+	}
+
+	// Paths are reported relative to the kustomization directory we were
+	// given, in the same form as the paths we walk.  The directories of the
+	// bases are included as well, so that a base is only evaluated as a part
+	// of the overlays that use it.
+	files := []string{}
+	seen := map[string]bool{}
+	addFile := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	for _, read := range fs.read {
+		rel, err := filepath.Rel(absDir, read)
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(dir, rel)
+		addFile(path)
+		if isKustomizationFileName(filepath.Base(path)) && filepath.Dir(path) != filepath.Clean(dir) {
+			addFile(filepath.Dir(path))
+		}
+	}
+
+	return &kustomizeConfiguration{
+		path: dir,
+		content: map[string]interface{}{
+			"k8s_resource_view_version": "0.0.1",
+			"resources":                 resources,
+		},
+		locations: resourceLocations,
+		files:     files,
+	}, nil
+}
+
+// kustomizeOrigin is a resource definition in a file used by a
+// kustomization.
+type kustomizeOrigin struct {
+	kind     string
+	name     string
+	location Location
+}
+
+func loadKustomizeOrigins(fs filesys.FileSystem, absPath string, path string) []kustomizeOrigin {
+	if !validK8sExts[filepath.Ext(path)] {
+		return nil
+	}
+	contents, err := fs.ReadFile(absPath)
+	if err != nil {
+		return nil
+	}
+	resources := map[string]interface{}{}
+	sources := map[string]SourceInfoNode{}
+	resourceIds, err := loadK8sDocuments(path, contents, NewK8sResourceIDs(), resources, sources)
+	if err != nil {
+		return nil
+	}
+	origins := []kustomizeOrigin{}
+	for _, id := range resourceIds {
+		document := resources[id].(map[string]interface{})
+		kind, _ := document["kind"].(string)
+		metadata, _ := document["metadata"].(map[string]interface{})
+		name, _ := metadata["name"].(string)
+		source := sources[id]
+		line, column := source.Location()
+		origins = append(origins, kustomizeOrigin{
+			kind:     kind,
+			name:     name,
+			location: Location{Path: path, Line: line, Col: column},
+		})
+	}
+	return origins
+}
+
+// findKustomizeOrigin picks the definition of a resource among the ones in
+// its file.  If no name matches, the only definition of that kind is used.
+func findKustomizeOrigin(origins []kustomizeOrigin, kind string, name string) (Location, bool) {
+	var found *kustomizeOrigin
+	var only *kustomizeOrigin
+	candidates := 0
+	for idx, origin := range origins {
+		if origin.kind != kind {
+			continue
+		}
+		candidates += 1
+		only = &origins[idx]
+		if strings.Contains(name, origin.name) && (found == nil || len(origin.name) > len(found.name)) {
+			found = &origins[idx]
+		}
+	}
+	if found == nil && candidates == 1 {
+		found = only
+	}
+	if found == nil {
+		return Location{}, false
+	}
+	return found.location, true
+}
+
+type kustomizeConfiguration struct {
+	path      string
+	content   map[string]interface{}
+	locations map[string]Location
+	files     []string
+}
+
+func (l *kustomizeConfiguration) RegulaInput() RegulaInput {
+	return RegulaInput{
+		"filepath": l.path,
+		"content":  l.content,
+	}
+}
+
+func (l *kustomizeConfiguration) Location(path []string) (LocationStack, error) {
+	if len(path) < 1 {
+		return nil, nil
+	}
+	if location, ok := l.locations[path[0]]; ok {
+		return []Location{location}, nil
+	}
+	return nil, nil
+}
+
+func (l *kustomizeConfiguration) LoadedFiles() []string {
+	return l.files
+}
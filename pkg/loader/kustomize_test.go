@@ -0,0 +1,88 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader_test
+
+import (
+	"testing"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKustomizeOverlay(t *testing.T) {
+	overlay := "kustomize_test/overlays/prod"
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{overlay},
+		InputTypes: []loader.InputType{loader.K8s},
+	})()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, loadedConfigs.Count())
+	for _, path := range []string{
+		overlay + "/kustomization.yaml",
+		overlay + "/privileged.yaml",
+		"kustomize_test/base",
+		"kustomize_test/base/kustomization.yaml",
+		"kustomize_test/base/deployment.yaml",
+		"kustomize_test/base/service.yaml",
+	} {
+		assert.True(t, loadedConfigs.AlreadyLoaded(path), path)
+	}
+
+	input := loadedConfigs.RegulaInput()[0]
+	assert.Equal(t, overlay, input["filepath"])
+	resources := input["content"].(map[string]interface{})["resources"].(map[string]interface{})
+	assert.Len(t, resources, 3)
+	assert.Contains(t, resources, "Service.prod.prod-web")
+	assert.Contains(t, resources, "ConfigMap.prod.prod-web-config-hf678c7m2b")
+	deployment := resources["Deployment.prod.prod-web"].(map[string]interface{})
+	metadata := deployment["metadata"].(map[string]interface{})
+	assert.NotContains(t, metadata, "annotations")
+	container := helmContainer(t, deployment)
+	assert.Equal(t, map[string]interface{}{"privileged": true}, container["securityContext"])
+
+	testCases := []struct {
+		resourceId string
+		location   loader.Location
+	}{
+		{
+			resourceId: "Deployment.prod.prod-web",
+			location:   loader.Location{Path: "kustomize_test/base/deployment.yaml", Line: 1, Col: 1},
+		},
+		{
+			resourceId: "ConfigMap.prod.prod-web-config-hf678c7m2b",
+			location:   loader.Location{Path: overlay + "/kustomization.yaml", Line: 1, Col: 1},
+		},
+	}
+	for _, tc := range testCases {
+		location, err := loadedConfigs.Location(overlay, []string{tc.resourceId, "spec"})
+		assert.Nil(t, err)
+		assert.Equal(t, []loader.Location{tc.location}, location)
+	}
+}
+
+func TestKustomizeAuto(t *testing.T) {
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{"kustomize_test"},
+		InputTypes: []loader.InputType{loader.Auto},
+	})()
+	assert.Nil(t, err)
+	filepaths := []string{}
+	for _, input := range loadedConfigs.RegulaInput() {
+		filepaths = append(filepaths, input["filepath"].(string))
+	}
+	// The base is only evaluated as a part of the overlay.
+	assert.Equal(t, []string{"kustomize_test/overlays/prod"}, filepaths)
+	assert.True(t, loadedConfigs.AlreadyLoaded("kustomize_test/base/deployment.yaml"))
+}
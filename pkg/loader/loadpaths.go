@@ -18,8 +18,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 
@@ -32,6 +34,9 @@ type LoadPathsOptions struct {
 	NoGitIgnore bool
 	IgnoreDirs  bool
 	VarFiles    []string
+	// Jobs is the maximum number of configurations that are detected and
+	// parsed concurrently.  Defaults to the number of CPUs.
+	Jobs int
 }
 
 type NoLoadableConfigsError struct {
@@ -44,7 +49,6 @@ func (e *NoLoadableConfigsError) Error() string {
 
 func LocalConfigurationLoader(options LoadPathsOptions) ConfigurationLoader {
 	return func() (LoadedConfigurations, error) {
-		configurations := newLoadedConfigurations()
 		detector, err := DetectorByInputTypes(options.InputTypes)
 		// We want to ignore file extension mismatches when 'auto' is not present in
 		// the selected input types and there is only one input type selected.
@@ -59,48 +63,24 @@ func LocalConfigurationLoader(options LoadPathsOptions) ConfigurationLoader {
 		if err != nil {
 			return nil, err
 		}
-		walkFunc := func(i InputPath) (skip bool, err error) {
-			if configurations.AlreadyLoaded(i.Path()) {
-				skip = true
-				return
-			}
-			// Ignore errors when we're recursing
-			loader, _ := i.DetectType(detector, DetectOptions{
-				IgnoreExt:  false,
-				IgnoreDirs: options.IgnoreDirs,
-				VarFiles:   options.VarFiles,
-			})
-			if loader != nil {
-				configurations.AddConfiguration(i.Path(), loader)
-			}
-			return
-		}
+
+		// Lay out all input paths in the order a serial walk would visit them.
+		plan := &loadPlan{}
 		gitRepoFinder := git.NewRepoFinder(options.Paths)
 		for _, path := range options.Paths {
 			if path == "-" {
-				path = stdIn
-			} else {
-				path = filepath.Clean(path)
-			}
-			if configurations.AlreadyLoaded(path) {
-				continue
-			}
-			if path == stdIn {
-				i := newFile(stdIn, stdIn)
-				loader, err := i.DetectType(detector, DetectOptions{
-					IgnoreExt: true,
-					VarFiles:  options.VarFiles,
+				plan.add(&loadEntry{
+					input:    newFile(stdIn, stdIn),
+					parent:   -1,
+					topLevel: true,
+					opts: DetectOptions{
+						IgnoreExt: true,
+						VarFiles:  options.VarFiles,
+					},
 				})
-				if err != nil {
-					return nil, err
-				}
-				if loader != nil {
-					configurations.AddConfiguration(stdIn, loader)
-				} else {
-					return nil, fmt.Errorf("Unable to detect input type of stdin")
-				}
 				continue
 			}
+			path = filepath.Clean(path)
 			name := filepath.Base(path)
 			info, err := os.Stat(path)
 			if err != nil {
@@ -124,36 +104,42 @@ func LocalConfigurationLoader(options LoadPathsOptions) ConfigurationLoader {
 				if err != nil {
 					return nil, err
 				}
-				loader, err := i.DetectType(detector, DetectOptions{
-					IgnoreExt:  ignoreFileExtension,
+				parent := plan.add(&loadEntry{
+					input:    i,
+					parent:   -1,
+					topLevel: true,
+					opts: DetectOptions{
+						IgnoreExt:  ignoreFileExtension,
+						IgnoreDirs: options.IgnoreDirs,
+						VarFiles:   options.VarFiles,
+					},
+				})
+				plan.addChildren(i, parent, DetectOptions{
+					IgnoreExt:  false,
 					IgnoreDirs: options.IgnoreDirs,
 					VarFiles:   options.VarFiles,
 				})
-				if err != nil {
-					return nil, err
-				}
-				if loader != nil {
-					configurations.AddConfiguration(path, loader)
-				}
-				if err := i.Walk(walkFunc); err != nil {
-					return nil, err
-				}
 			} else {
-				i := newFile(path, name)
-				loader, err := i.DetectType(detector, DetectOptions{
-					IgnoreExt: ignoreFileExtension,
-					VarFiles:  options.VarFiles,
+				plan.add(&loadEntry{
+					input:    newFile(path, name),
+					parent:   -1,
+					topLevel: true,
+					opts: DetectOptions{
+						IgnoreExt: ignoreFileExtension,
+						VarFiles:  options.VarFiles,
+					},
 				})
-				if err != nil {
-					return nil, err
-				}
-				if loader != nil {
-					configurations.AddConfiguration(path, loader)
-				} else {
-					return nil, fmt.Errorf("Unable to detect input type of file %v", i.Path())
-				}
 			}
 		}
+
+		jobs := options.Jobs
+		if jobs < 1 {
+			jobs = runtime.NumCPU()
+		}
+		configurations, err := plan.run(detector, jobs)
+		if err != nil {
+			return nil, err
+		}
 		if configurations.Count() < 1 {
 			return nil, &NoLoadableConfigsError{options.Paths}
 		}
@@ -162,12 +148,192 @@ func LocalConfigurationLoader(options LoadPathsOptions) ConfigurationLoader {
 	}
 }
 
+// loadEntry is a single input path that may be detected as a configuration.
+type loadEntry struct {
+	input InputPath
+	opts  DetectOptions
+	// Index of the directory entry this was found in, or -1 for input paths
+	// given by the user.
+	parent int
+	// Index just past the last descendant of this entry.
+	end int
+	// Errors are only reported for input paths given by the user.  Errors
+	// are ignored when we're recursing.
+	topLevel bool
+
+	started  bool
+	finished bool
+	skipped  bool
+	config   IACConfiguration
+	err      error
+}
+
+// loadPlan detects configurations using a pool of workers while producing
+// exactly the same result as walking the inputs serially.  Entries are laid
+// out in walk order.  Workers speculatively detect entries once their parent
+// directory has been committed, and results are committed strictly in walk
+// order, so the AlreadyLoaded deduplication sees the same state it would in a
+// serial walk.  Waiting on the parent avoids most wasted work, since the
+// paths covered by a configuration are usually descendants of it (e.g. the
+// `.terraform` directory of a Terraform root module).
+type loadPlan struct {
+	entries []*loadEntry
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	cursor int
+	failed bool
+	// Paths of the configurations found while recursing into directories.
+	recursed map[string]bool
+}
+
+func (p *loadPlan) add(e *loadEntry) int {
+	p.entries = append(p.entries, e)
+	e.end = len(p.entries)
+	return len(p.entries) - 1
+}
+
+func (p *loadPlan) addChildren(dir InputDirectory, parent int, opts DetectOptions) {
+	for _, child := range dir.Children() {
+		idx := p.add(&loadEntry{
+			input:  child,
+			parent: parent,
+			opts:   opts,
+		})
+		if d, ok := child.(InputDirectory); ok {
+			p.addChildren(d, idx, opts)
+		}
+		p.entries[parent].end = len(p.entries)
+	}
+}
+
+func (p *loadPlan) run(detector ConfigurationDetector, jobs int) (*loadedConfigurations, error) {
+	configurations := newLoadedConfigurations()
+	p.cond = sync.NewCond(&p.mu)
+	p.recursed = map[string]bool{}
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(detector, configurations)
+		}()
+	}
+	defer func() {
+		p.mu.Lock()
+		p.failed = true
+		p.cond.Broadcast()
+		p.mu.Unlock()
+		wg.Wait()
+	}()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.cursor < len(p.entries) {
+		e := p.entries[p.cursor]
+		if e.skipped || configurations.AlreadyLoaded(e.input.Path()) {
+			// Skip this path and everything below it.
+			for i := p.cursor; i < e.end; i++ {
+				p.entries[i].skipped = true
+			}
+			p.cursor = e.end
+			p.cond.Broadcast()
+			continue
+		}
+		for !e.finished {
+			p.cond.Wait()
+		}
+		if e.topLevel {
+			if e.err != nil {
+				return nil, e.err
+			}
+			if e.config == nil && !e.input.IsDir() {
+				if e.input.Path() == stdIn {
+					return nil, fmt.Errorf("Unable to detect input type of stdin")
+				}
+				return nil, fmt.Errorf("Unable to detect input type of file %v", e.input.Path())
+			}
+		}
+		if e.config != nil {
+			// A configuration may include files that were loaded on their own
+			// earlier in the walk, such as a nested CloudFormation stack or a
+			// linked ARM template in a sibling directory.  Those are only
+			// evaluated as part of the configuration that includes them.
+			for _, f := range e.config.LoadedFiles() {
+				if f != e.input.Path() && p.recursed[f] {
+					configurations.removeConfiguration(f)
+					delete(p.recursed, f)
+				}
+			}
+			configurations.AddConfiguration(e.input.Path(), e.config)
+			if !e.topLevel {
+				p.recursed[e.input.Path()] = true
+			}
+		}
+		p.cursor++
+		p.cond.Broadcast()
+	}
+	return configurations, nil
+}
+
+// next returns the index of the next entry a worker should detect, or -1 when
+// there is no more work.  Must be called with p.mu held.
+func (p *loadPlan) next(configurations *loadedConfigurations) int {
+	for {
+		if p.failed || p.cursor >= len(p.entries) {
+			return -1
+		}
+		for i := p.cursor; i < len(p.entries); i++ {
+			e := p.entries[i]
+			if e.started || e.skipped {
+				continue
+			}
+			if e.parent >= p.cursor {
+				// Parent is not committed yet, and neither is the rest of this
+				// subtree.
+				i = p.entries[e.parent].end - 1
+				continue
+			}
+			if configurations.AlreadyLoaded(e.input.Path()) {
+				continue
+			}
+			return i
+		}
+		p.cond.Wait()
+	}
+}
+
+func (p *loadPlan) work(detector ConfigurationDetector, configurations *loadedConfigurations) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		i := p.next(configurations)
+		if i < 0 {
+			return
+		}
+		e := p.entries[i]
+		e.started = true
+		p.mu.Unlock()
+		config, err := e.input.DetectType(detector, e.opts)
+		p.mu.Lock()
+		e.config = config
+		e.err = err
+		e.finished = true
+		p.cond.Broadcast()
+	}
+}
+
 type cachedLocation struct {
 	LocationStack LocationStack
 	Error         error
 }
 
 type loadedConfigurations struct {
+	// Guards all of the maps below, so configurations can be added and
+	// queried from several goroutines.
+	mu sync.RWMutex
+
 	configurations map[string]IACConfiguration
 
 	// The corresponding key in configurations for every loaded path.
@@ -179,6 +345,9 @@ type loadedConfigurations struct {
 	loadedPaths map[string]string
 
 	locationCache map[string]map[string]cachedLocation
+
+	// Lines of the source files that were searched for inline ignores.
+	sourceLines map[string][]string
 }
 
 func newLoadedConfigurations() *loadedConfigurations {
@@ -186,10 +355,13 @@ func newLoadedConfigurations() *loadedConfigurations {
 		configurations: map[string]IACConfiguration{},
 		loadedPaths:    map[string]string{},
 		locationCache:  map[string]map[string]cachedLocation{},
+		sourceLines:    map[string][]string{},
 	}
 }
 
 func (l *loadedConfigurations) AddConfiguration(path string, config IACConfiguration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	l.configurations[path] = config
 	l.loadedPaths[path] = path
 	for _, f := range config.LoadedFiles() {
@@ -198,7 +370,23 @@ func (l *loadedConfigurations) AddConfiguration(path string, config IACConfigura
 	}
 }
 
+// removeConfiguration removes a configuration along with all the paths it
+// loaded.
+func (l *loadedConfigurations) removeConfiguration(path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.configurations, path)
+	delete(l.locationCache, path)
+	for f, canonical := range l.loadedPaths {
+		if canonical == path {
+			delete(l.loadedPaths, f)
+		}
+	}
+}
+
 func (l *loadedConfigurations) ConfigurationPath(path string) *string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 	if fp, ok := l.loadedPaths[path]; ok {
 		return &fp
 	} else {
@@ -211,19 +399,33 @@ func (l *loadedConfigurations) AlreadyLoaded(path string) bool {
 }
 
 func (l *loadedConfigurations) RegulaInput() []RegulaInput {
+	input := []RegulaInput{}
+	for _, k := range l.Paths() {
+		input = append(input, l.Configuration(k).RegulaInput())
+	}
+	return input
+}
+
+func (l *loadedConfigurations) Paths() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 	keys := []string{}
 	for k := range l.configurations {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
-	input := []RegulaInput{}
-	for _, k := range keys {
-		input = append(input, l.configurations[k].RegulaInput())
-	}
-	return input
+	return keys
+}
+
+func (l *loadedConfigurations) Configuration(path string) IACConfiguration {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.configurations[path]
 }
 
 func (l *loadedConfigurations) locationFromCache(path string, joinedAttributePath string) (LocationStack, error, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 	pathCache, ok := l.locationCache[path]
 	if !ok {
 		return nil, nil, false
@@ -236,6 +438,8 @@ func (l *loadedConfigurations) locationFromCache(path string, joinedAttributePat
 }
 
 func (l *loadedConfigurations) cacheLocation(path string, joinedAttributePath string, loc LocationStack, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	pathCache, ok := l.locationCache[path]
 	if !ok {
 		pathCache = map[string]cachedLocation{}
@@ -253,17 +457,62 @@ func (l *loadedConfigurations) Location(path string, attributePath []string) (Lo
 		return loc, err
 	}
 
+	l.mu.RLock()
 	canonical, ok := l.loadedPaths[path]
+	loader := l.configurations[canonical]
+	l.mu.RUnlock()
 	if !ok {
 		return nil, fmt.Errorf("Unable to determine location for given path %v and attribute path %v", path, attributePath)
 	}
-	loader, _ := l.configurations[canonical]
 	loc, err := loader.Location(attributePath)
 	l.cacheLocation(path, joinedAttributePath, loc, err)
 	return loc, err
 }
 
+func (l *loadedConfigurations) InlineIgnores(path string, resourceID string) []InlineIgnore {
+	l.mu.RLock()
+	canonical, ok := l.loadedPaths[path]
+	config := l.configurations[canonical]
+	l.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	if provider, ok := config.(inlineIgnoreProvider); ok {
+		return provider.InlineIgnores(resourceID)
+	}
+
+	// Otherwise look for comments next to where the resource is defined.
+	loc, err := l.Location(path, []string{resourceID})
+	if err != nil || len(loc) < 1 {
+		return nil
+	}
+	lines, err := l.linesOf(loc[0].Path)
+	if err != nil {
+		return nil
+	}
+	return commentInlineIgnores(lines, loc[0])
+}
+
+func (l *loadedConfigurations) linesOf(path string) ([]string, error) {
+	l.mu.RLock()
+	lines, ok := l.sourceLines[path]
+	l.mu.RUnlock()
+	if ok {
+		return lines, nil
+	}
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	l.sourceLines[path] = lines
+	l.mu.Unlock()
+	return lines, nil
+}
+
 func (l *loadedConfigurations) Count() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 	return len(l.configurations)
 }
 
@@ -272,9 +521,12 @@ func detectorByInputType(inputType InputType) (ConfigurationDetector, error) {
 	case Auto:
 		return NewAutoDetector(
 			&CfnDetector{},
+			&CdkDetector{},
 			&TfPlanDetector{},
 			&TfDetector{},
+			&HelmDetector{},
 			&KubernetesDetector{},
+			&PulumiDetector{},
 			&ArmDetector{},
 		), nil
 	case Cfn:
@@ -287,6 +539,12 @@ func detectorByInputType(inputType InputType) (ConfigurationDetector, error) {
 		return &KubernetesDetector{}, nil
 	case Arm:
 		return &ArmDetector{}, nil
+	case Pulumi:
+		return &PulumiDetector{}, nil
+	case Helm:
+		return &HelmDetector{}, nil
+	case Cdk:
+		return &CdkDetector{}, nil
 	default:
 		return nil, fmt.Errorf("Unsupported input type: %v", inputType)
 	}
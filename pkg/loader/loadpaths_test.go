@@ -15,6 +15,7 @@
 package loader_test
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/fugue/regula/v3/pkg/loader"
@@ -59,3 +60,49 @@ func TestLoadPathsDirWithType(t *testing.T) {
 	assert.True(t, loadedConfigs.AlreadyLoaded("test_inputs/data/tfplan.0.15.json"))
 	assert.False(t, loadedConfigs.AlreadyLoaded("test_inputs/data/cfn.yaml"))
 }
+
+func TestLoadPathsJobsDeterministic(t *testing.T) {
+	load := func(jobs int) loader.LoadedConfigurations {
+		loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+			Paths:      []string{"test_inputs/data"},
+			InputTypes: []loader.InputType{loader.Auto},
+			Jobs:       jobs,
+		})()
+		assert.Nil(t, err)
+		return loadedConfigs
+	}
+	serial := load(1)
+	parallel := load(8)
+	assert.Equal(t, serial.Count(), parallel.Count())
+	assert.Equal(t, serial.RegulaInput(), parallel.RegulaInput())
+	for _, path := range []string{
+		"test_inputs/data/cfn.yaml",
+		"test_inputs/data/tfplan_modules/main.tf",
+		"test_inputs/data/tfplan_modules/child/main.tf",
+	} {
+		assert.Equal(t, serial.ConfigurationPath(path), parallel.ConfigurationPath(path))
+	}
+	// The child module is part of the Terraform configuration in the parent
+	// directory, so it should not have been loaded on its own.
+	assert.Equal(t, "test_inputs/data/tfplan_modules", *parallel.ConfigurationPath("test_inputs/data/tfplan_modules/child"))
+}
+
+func TestChangedConfigurations(t *testing.T) {
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{"test_inputs/data"},
+		InputTypes: []loader.InputType{loader.Auto},
+	})()
+	assert.Nil(t, err)
+	childModule, err := filepath.Abs("test_inputs/data/tfplan_modules/child/main.tf")
+	assert.Nil(t, err)
+	changed := loader.ChangedConfigurations(loadedConfigs, []string{
+		// A changed module file maps to the root module that loads it.
+		childModule,
+		// Deleted files map to the configuration loading their directory.
+		"test_inputs/data/tfplan_modules/child/deleted/variables.tf",
+		// Files that are not part of any configuration are ignored.
+		"test_inputs/data/text.txt",
+	})
+	assert.Equal(t, []string{"test_inputs/data/tfplan_modules"}, changed.Paths())
+	assert.False(t, changed.AlreadyLoaded("test_inputs/data/cfn.yaml"))
+}
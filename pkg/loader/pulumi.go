@@ -0,0 +1,618 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+var validPulumiExts map[string]bool = map[string]bool{
+	".json": true,
+}
+
+// Special values that Pulumi uses in its JSON output.
+const (
+	pulumiSignatureKey = "4dabf18193072939515e22adb298388d"
+	pulumiSecretSig    = "1b47061264138c4ac30d75fd1eb44270"
+	pulumiUnknownValue = "04da6b54-80e4-46f7-96ec-b56ff0331ba9"
+)
+
+// pulumiProviders maps Pulumi packages to the Terraform providers they are
+// bridged from.
+var pulumiProviders = map[string]string{
+	"aws":   "aws",
+	"gcp":   "google",
+	"azure": "azurerm",
+}
+
+// pulumiModules maps Pulumi modules to the prefix that the Terraform resource
+// types in that module use.  An empty prefix means that the Terraform types
+// don't include the module.
+var pulumiModules = map[string]string{
+	"aws:apigateway":            "api_gateway",
+	"aws:cfg":                   "config",
+	"aws:cloudhsmv2":            "cloudhsm_v2",
+	"aws:directoryservice":      "directory_service",
+	"aws:ec2":                   "",
+	"aws:ec2clientvpn":          "ec2_client_vpn",
+	"aws:ec2transitgateway":     "ec2_transit_gateway",
+	"aws:rds":                   "db",
+	"gcp:organizations":         "organization",
+	"gcp:projects":              "project",
+	"azure:authorization":       "",
+	"azure:compute":             "",
+	"azure:core":                "",
+	"azure:keyvault":            "key_vault",
+	"azure:monitoring":          "monitor",
+	"azure:network":             "",
+	"azure:appservice":          "app_service",
+	"azure:operationalinsights": "log_analytics",
+	"azure:securitycenter":      "security_center",
+}
+
+// pulumiResourceTypes lists the Pulumi types that don't follow the naming
+// conventions of their module.
+var pulumiResourceTypes = map[string]string{
+	"aws:alb/loadBalancer:LoadBalancer":                          "aws_alb",
+	"aws:cfg/recorder:Recorder":                                  "aws_config_configuration_recorder",
+	"aws:cfg/rule:Rule":                                          "aws_config_config_rule",
+	"aws:cloudtrail/trail:Trail":                                 "aws_cloudtrail",
+	"aws:elb/loadBalancer:LoadBalancer":                          "aws_elb",
+	"aws:lb/loadBalancer:LoadBalancer":                           "aws_lb",
+	"aws:rds/cluster:Cluster":                                    "aws_rds_cluster",
+	"aws:rds/clusterEndpoint:ClusterEndpoint":                    "aws_rds_cluster_endpoint",
+	"aws:rds/clusterInstance:ClusterInstance":                    "aws_rds_cluster_instance",
+	"aws:rds/clusterParameterGroup:ClusterParameterGroup":        "aws_rds_cluster_parameter_group",
+	"aws:rds/globalCluster:GlobalCluster":                        "aws_rds_global_cluster",
+	"gcp:organizations/project:Project":                          "google_project",
+	"gcp:serviceAccount/account:Account":                         "google_service_account",
+	"azure:appservice/functionApp:FunctionApp":                   "azurerm_function_app",
+	"azure:appservice/linuxWebApp:LinuxWebApp":                   "azurerm_linux_web_app",
+	"azure:appservice/windowsWebApp:WindowsWebApp":               "azurerm_windows_web_app",
+	"azure:containerservice/kubernetesCluster:KubernetesCluster": "azurerm_kubernetes_cluster",
+	"azure:containerservice/registry:Registry":                   "azurerm_container_registry",
+	"azure:compute/dataDiskAttachment:DataDiskAttachment":        "azurerm_virtual_machine_data_disk_attachment",
+	"azure:lb/loadBalancer:LoadBalancer":                         "azurerm_lb",
+}
+
+// pulumiAttributes lists the top-level properties that Pulumi renames, usually
+// because it pluralizes repeated blocks, by Terraform resource type.
+var pulumiAttributes = map[string]map[string]string{
+	"aws_cloudfront_distribution": {
+		"orderedCacheBehaviors": "ordered_cache_behavior",
+		"origins":               "origin",
+	},
+	"aws_cloudtrail": {
+		"eventSelectors": "event_selector",
+	},
+	"aws_elb": {
+		"listeners": "listener",
+	},
+	"aws_instance": {
+		"ebsBlockDevices":       "ebs_block_device",
+		"ephemeralBlockDevices": "ephemeral_block_device",
+		"networkInterfaces":     "network_interface",
+	},
+	"aws_s3_bucket": {
+		"corsRules":      "cors_rule",
+		"grants":         "grant",
+		"lifecycleRules": "lifecycle_rule",
+		"loggings":       "logging",
+	},
+	"aws_wafv2_web_acl": {
+		"rules": "rule",
+	},
+	"google_compute_firewall": {
+		"allows": "allow",
+		"denies": "deny",
+	},
+	"google_compute_instance": {
+		"attachedDisks":     "attached_disk",
+		"networkInterfaces": "network_interface",
+		"scratchDisks":      "scratch_disk",
+	},
+	"azurerm_key_vault": {
+		"accessPolicies": "access_policy",
+	},
+	"azurerm_network_security_group": {
+		"securityRules": "security_rule",
+	},
+}
+
+// pulumiMapAttributes are properties that are maps rather than nested blocks.
+// Their keys are kept as-is.
+var pulumiMapAttributes = map[string]bool{
+	"annotations":    true,
+	"labels":         true,
+	"metadata":       true,
+	"resourceLabels": true,
+	"tags":           true,
+	"tagsAll":        true,
+	"userLabels":     true,
+	"variables":      true,
+}
+
+type PulumiDetector struct{}
+
+func (c *PulumiDetector) DetectFile(i InputFile, opts DetectOptions) (IACConfiguration, error) {
+	if !opts.IgnoreExt && !validPulumiExts[i.Ext()] {
+		return nil, fmt.Errorf("File does not have .json extension: %v", i.Path())
+	}
+	contents, err := i.Contents()
+	if err != nil {
+		return nil, err
+	}
+
+	document := map[string]interface{}{}
+	if err := json.Unmarshal(contents, &document); err != nil {
+		return nil, fmt.Errorf("Failed to parse file as JSON %v: %v", i.Path(), err)
+	}
+	states, err := pulumiResourceStates(document)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", err, i.Path())
+	}
+
+	path := i.Path()
+	resources := map[string]interface{}{}
+	sources := map[string]pulumiResourceSource{}
+	physicalIds := map[string]string{}
+	dependencies := map[string]map[string]interface{}{}
+	for _, state := range states {
+		urn, _ := state.value["urn"].(string)
+		token, _ := state.value["type"].(string)
+		if urn == "" || !pulumiIsCustomResource(state.value) {
+			continue
+		}
+		resourceType, provider := pulumiResourceType(token)
+		properties := pulumiProperties(state.value)
+		resource := pulumiAttributeMap(properties, pulumiAttributes[resourceType])
+		resource["id"] = urn
+		resource["_type"] = resourceType
+		resource["_filepath"] = path
+		resource["_provider"] = provider
+		tf_populateTags(resource)
+		resources[urn] = resource
+		sources[urn] = pulumiResourceSource{
+			path:         state.path,
+			resourceType: resourceType,
+		}
+		if id, ok := state.value["id"].(string); ok && id != "" {
+			physicalIds[id] = urn
+		} else if id, ok := properties["id"].(string); ok && id != "" {
+			physicalIds[id] = urn
+		}
+		if deps, ok := state.value["propertyDependencies"].(map[string]interface{}); ok {
+			dependencies[urn] = deps
+		}
+	}
+	pulumiResolveReferences(resources, sources, physicalIds, dependencies)
+
+	source, err := LoadSourceInfoNode(contents)
+	if err != nil {
+		source = nil
+	}
+
+	return &pulumiConfiguration{
+		path: path,
+		content: map[string]interface{}{
+			"pulumi_resource_view_version": "0.0.1",
+			"resources":                    resources,
+		},
+		sources: sources,
+		source:  source,
+	}, nil
+}
+
+func (c *PulumiDetector) DetectDirectory(i InputDirectory, opts DetectOptions) (IACConfiguration, error) {
+	return nil, nil
+}
+
+// pulumiResourceState is a resource state in the input, together with its
+// path in the JSON document.
+type pulumiResourceState struct {
+	path  []string
+	value map[string]interface{}
+}
+
+// pulumiResourceStates finds the resources in `pulumi stack export` output,
+// a Pulumi checkpoint file or `pulumi preview --json` output.  Since these are
+// detected among other JSON files, a `steps` array or `deployment` object is
+// not enough: previews must have steps with an `op` and a resource, and
+// deployments must have a `manifest` or be versioned.
+func pulumiResourceStates(document map[string]interface{}) ([]pulumiResourceState, error) {
+	states := []pulumiResourceState{}
+	if steps, ok := document["steps"].([]interface{}); ok {
+		isPreview := false
+		for idx, s := range steps {
+			step, _ := s.(map[string]interface{})
+			if _, ok := step["op"].(string); !ok {
+				continue
+			}
+			newState, hasNewState := step["newState"].(map[string]interface{})
+			if _, hasUrn := step["urn"].(string); hasUrn || hasNewState {
+				isPreview = true
+			}
+			if hasNewState {
+				states = append(states, pulumiResourceState{
+					path:  []string{"steps", strconv.Itoa(idx), "newState"},
+					value: newState,
+				})
+			}
+		}
+		if !isPreview {
+			return nil, fmt.Errorf("Input file is not a Pulumi preview")
+		}
+		return states, nil
+	}
+
+	prefix := []string{"deployment"}
+	deployment, ok := document["deployment"].(map[string]interface{})
+	if !ok {
+		checkpoint, _ := document["checkpoint"].(map[string]interface{})
+		deployment, ok = checkpoint["latest"].(map[string]interface{})
+		prefix = []string{"checkpoint", "latest"}
+	}
+	if ok {
+		_, hasManifest := deployment["manifest"].(map[string]interface{})
+		_, hasVersion := document["version"].(float64)
+		ok = hasManifest || hasVersion
+	}
+	if !ok {
+		return nil, fmt.Errorf("Input file is not a Pulumi stack export or preview")
+	}
+	resources, _ := deployment["resources"].([]interface{})
+	for idx, r := range resources {
+		if resource, ok := r.(map[string]interface{}); ok {
+			path := append(append([]string{}, prefix...), "resources", strconv.Itoa(idx))
+			states = append(states, pulumiResourceState{path: path, value: resource})
+		}
+	}
+	return states, nil
+}
+
+// pulumiIsCustomResource excludes component resources, providers, the stack
+// and resources that are pending deletion.
+func pulumiIsCustomResource(state map[string]interface{}) bool {
+	if custom, ok := state["custom"].(bool); ok && !custom {
+		return false
+	}
+	if deleted, ok := state["delete"].(bool); ok && deleted {
+		return false
+	}
+	token, _ := state["type"].(string)
+	return token != "" && !strings.HasPrefix(token, "pulumi:")
+}
+
+// pulumiResourceType maps a Pulumi type token such as `aws:s3/bucket:Bucket`
+// to the equivalent Terraform resource type and provider.  Pulumi types that
+// are bridged from Terraform providers are named after the Terraform types,
+// with the exceptions listed in pulumiModules and pulumiResourceTypes.
+func pulumiResourceType(token string) (string, string) {
+	parts := strings.Split(token, ":")
+	if len(parts) != 3 {
+		return token, ""
+	}
+	pkg, module, name := parts[0], parts[1], pulumiSnakeCase(parts[2])
+	provider, ok := pulumiProviders[pkg]
+	if !ok {
+		provider = pkg
+	}
+	if resourceType, ok := pulumiResourceTypes[token]; ok {
+		return resourceType, provider
+	}
+
+	module = strings.SplitN(module, "/", 2)[0]
+	if prefix, ok := pulumiModules[pkg+":"+module]; ok {
+		module = prefix
+	} else if module == "index" {
+		module = ""
+	} else {
+		module = pulumiSnakeCase(module)
+	}
+	if pkg == "aws" && module == "s3" {
+		// The S3 resources of AWS provider v4 are suffixed with V2.
+		name = strings.TrimSuffix(name, "_v2")
+	}
+
+	squash := func(s string) string { return strings.ReplaceAll(s, "_", "") }
+	if module == "" || strings.HasPrefix(squash(name), squash(module)) {
+		return provider + "_" + name, provider
+	}
+	return provider + "_" + module + "_" + name, provider
+}
+
+// pulumiSnakeCase converts camelCase and PascalCase names to snake_case,
+// keeping acronyms together, e.g. `BucketIAMMember` becomes
+// `bucket_iam_member`.
+func pulumiSnakeCase(name string) string {
+	runes := []rune(name)
+	builder := strings.Builder{}
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+					(unicode.IsUpper(prev) && nextLower) {
+					builder.WriteRune('_')
+				}
+			}
+			builder.WriteRune(unicode.ToLower(r))
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// pulumiProperties merges the inputs and known outputs of a resource.
+func pulumiProperties(state map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	if inputs, ok := state["inputs"].(map[string]interface{}); ok {
+		for k, v := range inputs {
+			properties[k] = v
+		}
+	}
+	if outputs, ok := state["outputs"].(map[string]interface{}); ok {
+		for k, v := range outputs {
+			if v == pulumiUnknownValue {
+				continue
+			}
+			properties[k] = v
+		}
+	}
+	return properties
+}
+
+// pulumiReveal replaces unknown values with nil and secrets with their
+// plaintext, if the input contains it.
+func pulumiReveal(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if v == pulumiUnknownValue {
+			return nil
+		}
+	case map[string]interface{}:
+		if v[pulumiSignatureKey] == pulumiSecretSig {
+			plaintext, ok := v["plaintext"].(string)
+			if !ok {
+				return nil
+			}
+			var revealed interface{}
+			if err := json.Unmarshal([]byte(plaintext), &revealed); err != nil {
+				return nil
+			}
+			return pulumiReveal(revealed)
+		}
+	}
+	return value
+}
+
+// pulumiAttributeMap converts an object of Pulumi properties to Terraform
+// attributes.
+func pulumiAttributeMap(properties map[string]interface{}, renames map[string]string) map[string]interface{} {
+	attributes := map[string]interface{}{}
+	for k, v := range properties {
+		if strings.HasPrefix(k, "__") {
+			continue
+		}
+		name, ok := renames[k]
+		if !ok {
+			name = pulumiSnakeCase(k)
+		}
+		if pulumiMapAttributes[k] {
+			attributes[name] = pulumiMap(v)
+		} else {
+			attributes[name] = pulumiBlock(v)
+		}
+	}
+	return attributes
+}
+
+// pulumiBlock converts a property value.  Pulumi flattens nested blocks that
+// can only occur once into objects, whereas the Terraform resource view
+// always represents blocks as lists.
+func pulumiBlock(value interface{}) interface{} {
+	switch v := pulumiReveal(value).(type) {
+	case map[string]interface{}:
+		return []interface{}{pulumiAttributeMap(v, nil)}
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, elem := range v {
+			if obj, ok := pulumiReveal(elem).(map[string]interface{}); ok {
+				arr[i] = pulumiAttributeMap(obj, nil)
+			} else {
+				arr[i] = pulumiBlock(elem)
+			}
+		}
+		return arr
+	default:
+		return v
+	}
+}
+
+// pulumiMap converts a map property such as tags, keeping the keys.
+func pulumiMap(value interface{}) interface{} {
+	obj, ok := pulumiReveal(value).(map[string]interface{})
+	if !ok {
+		return pulumiReveal(value)
+	}
+	m := map[string]interface{}{}
+	for k, v := range obj {
+		m[k] = pulumiReveal(v)
+	}
+	return m
+}
+
+// pulumiResolveReferences replaces references to other resources with their
+// URNs, like the Terraform HCL loader does with resource addresses, so that
+// rules can join resources on their ids.  Physical ids are known in stack
+// exports, whereas previews only record the dependencies of unknown values.
+func pulumiResolveReferences(
+	resources map[string]interface{},
+	sources map[string]pulumiResourceSource,
+	physicalIds map[string]string,
+	dependencies map[string]map[string]interface{},
+) {
+	var resolve func(urn string, value interface{}) interface{}
+	resolve = func(urn string, value interface{}) interface{} {
+		switch v := value.(type) {
+		case string:
+			if target, ok := physicalIds[v]; ok && target != urn {
+				return target
+			}
+		case []interface{}:
+			for i := range v {
+				v[i] = resolve(urn, v[i])
+			}
+		case map[string]interface{}:
+			for k := range v {
+				v[k] = resolve(urn, v[k])
+			}
+		}
+		return value
+	}
+
+	for urn, r := range resources {
+		resource := r.(map[string]interface{})
+		for k, v := range resource {
+			if k == "id" || strings.HasPrefix(k, "_") {
+				continue
+			}
+			resource[k] = resolve(urn, v)
+		}
+
+		renames := pulumiAttributes[sources[urn].resourceType]
+		for property, deps := range dependencies[urn] {
+			targets, _ := deps.([]interface{})
+			if len(targets) != 1 {
+				continue
+			}
+			target, _ := targets[0].(string)
+			if _, ok := resources[target]; !ok {
+				continue
+			}
+			name, ok := renames[property]
+			if !ok {
+				name = pulumiSnakeCase(property)
+			}
+			if resource[name] == nil {
+				resource[name] = target
+			}
+		}
+	}
+}
+
+type pulumiResourceSource struct {
+	path         []string
+	resourceType string
+}
+
+type pulumiConfiguration struct {
+	path    string
+	content map[string]interface{}
+	sources map[string]pulumiResourceSource
+	source  *SourceInfoNode
+}
+
+func (l *pulumiConfiguration) RegulaInput() RegulaInput {
+	return RegulaInput{
+		"filepath": l.path,
+		"content":  l.content,
+	}
+}
+
+func (l *pulumiConfiguration) Location(path []string) (LocationStack, error) {
+	if l.source == nil || len(path) < 1 {
+		return nil, nil
+	}
+	resourceSource, ok := l.sources[path[0]]
+	if !ok {
+		return nil, nil
+	}
+	resource, err := l.source.GetPath(resourceSource.path)
+	if err != nil {
+		return nil, nil
+	}
+
+	node := resource
+	if len(path) > 1 {
+		renames := pulumiAttributes[resourceSource.resourceType]
+		for _, properties := range []string{"inputs", "outputs"} {
+			if props, err := resource.GetKey(properties); err == nil {
+				if attribute, ok := pulumiPropertyNode(props, path[1:], renames); ok {
+					node = attribute
+					break
+				}
+			}
+		}
+	}
+	line, column := node.Location()
+	return []Location{{Path: l.path, Line: line, Col: column}}, nil
+}
+
+// pulumiPropertyNode finds the property that corresponds to a Terraform
+// attribute path, as far as possible.  It returns false if the first
+// attribute is not found.
+func pulumiPropertyNode(node *SourceInfoNode, path []string, renames map[string]string) (*SourceInfoNode, bool) {
+	found := false
+	for _, part := range path {
+		switch node.body.Kind {
+		case yaml.MappingNode:
+			var child *SourceInfoNode
+			for i := 0; i+1 < len(node.body.Content); i += 2 {
+				key := node.body.Content[i].Value
+				name, ok := renames[key]
+				if !ok {
+					name = pulumiSnakeCase(key)
+				}
+				if name == part || key == part {
+					child = &SourceInfoNode{key: node.body.Content[i], body: node.body.Content[i+1]}
+					break
+				}
+			}
+			if child == nil {
+				// Blocks that Pulumi flattened into objects are addressed
+				// with an index in the resource view.
+				if _, err := strconv.Atoi(part); err == nil {
+					continue
+				}
+				return node, found
+			}
+			node = child
+		case yaml.SequenceNode:
+			child, err := node.GetPath([]string{part})
+			if err != nil {
+				return node, found
+			}
+			node = child
+		default:
+			return node, found
+		}
+		renames = nil
+		found = true
+	}
+	return node, found
+}
+
+func (l *pulumiConfiguration) LoadedFiles() []string {
+	return []string{l.path}
+}
@@ -0,0 +1,180 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	inputs "github.com/fugue/regula/v3/pkg/loader/test_inputs"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func pulumiResources(t *testing.T, config loader.IACConfiguration) map[string]interface{} {
+	content, ok := config.RegulaInput()["content"].(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, "0.0.1", content["pulumi_resource_view_version"])
+	resources, ok := content["resources"].(map[string]interface{})
+	assert.True(t, ok)
+	return resources
+}
+
+func TestPulumiDetectorStackExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	detector := &loader.PulumiDetector{}
+	f := makeMockFile(ctrl, "stack.json", ".json", inputs.Contents(t, "pulumi_stack.json"))
+	config, err := detector.DetectFile(f, loader.DetectOptions{})
+	assert.Nil(t, err)
+	assert.NotNil(t, config)
+	assert.Equal(t, []string{"stack.json"}, config.LoadedFiles())
+
+	bucketUrn := "urn:pulumi:dev::infra::aws:s3/bucket:Bucket::logs"
+	vpcUrn := "urn:pulumi:dev::infra::aws:ec2/vpc:Vpc::main"
+	flowLogUrn := "urn:pulumi:dev::infra::aws:ec2/flowLog:FlowLog::main"
+	dbUrn := "urn:pulumi:dev::infra::aws:rds/instance:Instance::db"
+	resources := pulumiResources(t, config)
+	assert.Len(t, resources, 4)
+	assert.Equal(t, map[string]interface{}{
+		"id":        bucketUrn,
+		"_type":     "aws_s3_bucket",
+		"_provider": "aws",
+		"_filepath": "stack.json",
+		"_tags":     map[string]interface{}{"Environment": "dev"},
+		"arn":       "arn:aws:s3:::logs-3f2a1b",
+		"bucket":    "logs-3f2a1b",
+		"logging":   []interface{}{},
+		"server_side_encryption_configuration": []interface{}{
+			map[string]interface{}{
+				"rule": []interface{}{
+					map[string]interface{}{
+						"apply_server_side_encryption_by_default": []interface{}{
+							map[string]interface{}{"sse_algorithm": "aws:kms"},
+						},
+						"bucket_key_enabled": false,
+					},
+				},
+			},
+		},
+		"tags": map[string]interface{}{"Environment": "dev"},
+		"versioning": []interface{}{
+			map[string]interface{}{"enabled": true, "mfa_delete": false},
+		},
+	}, resources[bucketUrn])
+
+	vpc := resources[vpcUrn].(map[string]interface{})
+	assert.Equal(t, "aws_vpc", vpc["_type"])
+	assert.Equal(t, vpcUrn, vpc["id"])
+	flowLog := resources[flowLogUrn].(map[string]interface{})
+	assert.Equal(t, "aws_flow_log", flowLog["_type"])
+	assert.Equal(t, vpcUrn, flowLog["vpc_id"])
+	db := resources[dbUrn].(map[string]interface{})
+	assert.Equal(t, "aws_db_instance", db["_type"])
+	assert.Nil(t, db["password"])
+	assert.Equal(t, true, db["storage_encrypted"])
+
+	location, err := config.Location([]string{bucketUrn})
+	assert.Nil(t, err)
+	assert.Equal(t, []loader.Location{{Path: "stack.json", Line: 24, Col: 7}}, location)
+	location, err = config.Location([]string{bucketUrn, "tags", "Environment"})
+	assert.Nil(t, err)
+	assert.Equal(t, []loader.Location{{Path: "stack.json", Line: 33, Col: 13}}, location)
+	location, err = config.Location([]string{
+		bucketUrn, "server_side_encryption_configuration", "0", "rule", "0", "bucket_key_enabled",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []loader.Location{{Path: "stack.json", Line: 46, Col: 15}}, location)
+}
+
+func TestPulumiDetectorPreview(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	detector := &loader.PulumiDetector{}
+	f := makeMockFile(ctrl, "preview.json", ".json", inputs.Contents(t, "pulumi_preview.json"))
+	config, err := detector.DetectFile(f, loader.DetectOptions{})
+	assert.Nil(t, err)
+	assert.NotNil(t, config)
+
+	firewallUrn := "urn:pulumi:dev::infra::gcp:compute/firewall:Firewall::ssh"
+	bucketUrn := "urn:pulumi:dev::infra::gcp:storage/bucket:Bucket::assets"
+	memberUrn := "urn:pulumi:dev::infra::gcp:storage/bucketIAMMember:BucketIAMMember::public"
+	resources := pulumiResources(t, config)
+	assert.Len(t, resources, 4)
+	assert.Equal(t, map[string]interface{}{
+		"id":        firewallUrn,
+		"_type":     "google_compute_firewall",
+		"_provider": "google",
+		"_filepath": "preview.json",
+		"_tags":     map[string]interface{}{},
+		"allow": []interface{}{
+			map[string]interface{}{
+				"ports":    []interface{}{"22"},
+				"protocol": "tcp",
+			},
+		},
+		"network":       "main",
+		"source_ranges": []interface{}{"0.0.0.0/0"},
+	}, resources[firewallUrn])
+	bucket := resources[bucketUrn].(map[string]interface{})
+	assert.Equal(t, "google_storage_bucket", bucket["_type"])
+	assert.Equal(t, map[string]interface{}{"team": "web"}, bucket["_tags"])
+	member := resources[memberUrn].(map[string]interface{})
+	assert.Equal(t, "google_storage_bucket_iam_member", member["_type"])
+	assert.Equal(t, bucketUrn, member["bucket"])
+
+	location, err := config.Location([]string{firewallUrn, "allow", "0", "ports"})
+	assert.Nil(t, err)
+	assert.Equal(t, []loader.Location{{Path: "preview.json", Line: 39, Col: 15}}, location)
+}
+
+func TestPulumiDetectorNotPulumiContents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	detector := &loader.PulumiDetector{}
+	f := makeMockFile(ctrl, "other.json", ".json", inputs.Contents(t, "other.json"))
+	config, err := detector.DetectFile(f, loader.DetectOptions{})
+	assert.NotNil(t, err)
+	assert.Nil(t, config)
+}
+
+func TestPulumiDetectorNotPulumiJSON(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	detector := &loader.PulumiDetector{}
+	for _, contents := range []string{
+		`{"steps": [{"run": "make"}]}`,
+		`{"deployment": {"replicas": 3}}`,
+		`{"checkpoint": {"latest": {"resources": []}}}`,
+	} {
+		f := makeMockFile(ctrl, "other.json", ".json", []byte(contents))
+		config, err := detector.DetectFile(f, loader.DetectOptions{})
+		assert.NotNil(t, err)
+		assert.Nil(t, config)
+	}
+}
+
+func TestPulumiAutoNotPulumiJSON(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"workflow.json":   `{"steps": [{"run": "make"}]}`,
+		"deployment.json": `{"deployment": {"replicas": 3}}`,
+	} {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+	}
+	_, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{dir},
+		InputTypes: []loader.InputType{loader.Auto},
+	})()
+	assert.IsType(t, &loader.NoLoadableConfigsError{}, err)
+}
@@ -0,0 +1,723 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file implements the parts of the AWS::Serverless-2016-10-31 transform
+// that matter for evaluating rules: SAM resources are replaced by the Lambda,
+// IAM, API Gateway and other resources that CloudFormation would create for
+// them.  Properties that don't end up in a resource, such as deployment
+// preferences, are dropped.
+
+package loader
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const samTransform = "AWS::Serverless-2016-10-31"
+
+// Resource attributes that are carried over to the main generated resource.
+var samResourceAttributes = []string{
+	"Condition",
+	"DeletionPolicy",
+	"DependsOn",
+	"Metadata",
+	"UpdateReplacePolicy",
+}
+
+// Properties that are copied as-is for each SAM resource type.
+var samFunctionProperties = []string{
+	"Architectures",
+	"CodeSigningConfigArn",
+	"Description",
+	"Environment",
+	"EphemeralStorage",
+	"FileSystemConfigs",
+	"FunctionName",
+	"Handler",
+	"ImageConfig",
+	"KmsKeyArn",
+	"Layers",
+	"MemorySize",
+	"PackageType",
+	"ReservedConcurrentExecutions",
+	"Role",
+	"Runtime",
+	"Timeout",
+	"VpcConfig",
+}
+
+var samApiProperties = []string{
+	"ApiKeySourceType",
+	"BinaryMediaTypes",
+	"Description",
+	"DisableExecuteApiEndpoint",
+	"MinimumCompressionSize",
+	"Mode",
+	"Name",
+}
+
+var samStageProperties = []string{
+	"AccessLogSetting",
+	"CacheClusterEnabled",
+	"CacheClusterSize",
+	"CanarySetting",
+	"MethodSettings",
+	"TracingEnabled",
+	"Variables",
+}
+
+var samHttpApiStageProperties = map[string]string{
+	"AccessLogSettings":    "AccessLogSettings",
+	"DefaultRouteSettings": "DefaultRouteSettings",
+	"RouteSettings":        "RouteSettings",
+	"StageVariables":       "StageVariables",
+}
+
+// Sections of `Globals` and the SAM resource types they apply to.
+var samGlobals = map[string]string{
+	"AWS::Serverless::Api":         "Api",
+	"AWS::Serverless::Function":    "Function",
+	"AWS::Serverless::HttpApi":     "HttpApi",
+	"AWS::Serverless::SimpleTable": "SimpleTable",
+}
+
+func hasSamTransform(template map[string]interface{}) bool {
+	switch transform := template["Transform"].(type) {
+	case string:
+		return transform == samTransform
+	case []interface{}:
+		for _, t := range transform {
+			if t == samTransform {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// samTransformer expands the SAM resources of a template.  Generated
+// resources are tracked in origins, which maps their logical IDs to the
+// logical ID of the SAM resource that they were generated from.
+type samTransformer struct {
+	globals   map[string]interface{}
+	resources map[string]interface{}
+	origins   map[string]string
+}
+
+// applySamTransform returns the resources of a template after the SAM
+// transform, and the origins of the generated resources.
+func applySamTransform(template map[string]interface{}) (map[string]interface{}, map[string]string) {
+	resources, _ := template["Resources"].(map[string]interface{})
+	if !hasSamTransform(template) {
+		return resources, map[string]string{}
+	}
+	globals, _ := template["Globals"].(map[string]interface{})
+	t := samTransformer{
+		globals:   globals,
+		resources: map[string]interface{}{},
+		origins:   map[string]string{},
+	}
+
+	// Resources that are not generated keep their logical IDs, so these
+	// are added first, and generated resources never replace them.
+	ids := []string{}
+	for id, resource := range resources {
+		ids = append(ids, id)
+		if !isSamResource(resource) {
+			t.resources[id] = resource
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if resource, ok := resources[id].(map[string]interface{}); ok && isSamResource(resource) {
+			t.transform(id, resource)
+		}
+	}
+	return t.resources, t.origins
+}
+
+func isSamResource(resource interface{}) bool {
+	if r, ok := resource.(map[string]interface{}); ok {
+		resourceType, _ := r["Type"].(string)
+		return strings.HasPrefix(resourceType, "AWS::Serverless::")
+	}
+	return false
+}
+
+func (t *samTransformer) add(origin string, id string, resourceType string, properties map[string]interface{}) {
+	if _, ok := t.resources[id]; ok {
+		return
+	}
+	t.resources[id] = map[string]interface{}{
+		"Type":       resourceType,
+		"Properties": properties,
+	}
+	if id != origin {
+		t.origins[id] = origin
+	}
+}
+
+func (t *samTransformer) transform(id string, resource map[string]interface{}) {
+	resourceType := resource["Type"].(string)
+	properties, _ := resource["Properties"].(map[string]interface{})
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	if section, ok := samGlobals[resourceType]; ok {
+		if globals, ok := t.globals[section].(map[string]interface{}); ok {
+			properties = samMergeGlobals(globals, properties)
+		}
+	}
+
+	switch resourceType {
+	case "AWS::Serverless::Function":
+		t.function(id, properties)
+	case "AWS::Serverless::Api":
+		t.api(id, properties)
+	case "AWS::Serverless::HttpApi":
+		t.httpApi(id, properties)
+	case "AWS::Serverless::SimpleTable":
+		t.simpleTable(id, properties)
+	case "AWS::Serverless::LayerVersion":
+		t.layerVersion(id, properties)
+	case "AWS::Serverless::Application":
+		t.application(id, properties)
+	default:
+		// Unsupported resource types are kept as they are.
+		t.resources[id] = resource
+		return
+	}
+
+	if generated, ok := t.resources[id].(map[string]interface{}); ok {
+		for _, attribute := range samResourceAttributes {
+			if value, ok := resource[attribute]; ok {
+				generated[attribute] = value
+			}
+		}
+	}
+}
+
+func (t *samTransformer) function(id string, properties map[string]interface{}) {
+	function := samCopy(properties, samFunctionProperties)
+	if code := samFunctionCode(properties); code != nil {
+		function["Code"] = code
+	}
+	if tracing, ok := properties["Tracing"]; ok {
+		function["TracingConfig"] = map[string]interface{}{"Mode": tracing}
+	}
+	if dlq, ok := properties["DeadLetterQueue"].(map[string]interface{}); ok {
+		function["DeadLetterConfig"] = map[string]interface{}{"TargetArn": dlq["TargetArn"]}
+	}
+	tags := samTags(properties["Tags"], map[string]interface{}{"lambda:createdBy": "SAM"})
+	function["Tags"] = tags
+
+	if _, ok := properties["Role"]; !ok {
+		roleId := id + "Role"
+		t.add(id, roleId, "AWS::IAM::Role", samFunctionRole(id, properties, tags))
+		function["Role"] = map[string]interface{}{"Fn::GetAtt": []interface{}{roleId, "Arn"}}
+	}
+	t.add(id, id, "AWS::Lambda::Function", function)
+
+	events, _ := properties["Events"].(map[string]interface{})
+	eventIds := []string{}
+	for eventId := range events {
+		eventIds = append(eventIds, eventId)
+	}
+	sort.Strings(eventIds)
+	for _, eventId := range eventIds {
+		if event, ok := events[eventId].(map[string]interface{}); ok {
+			t.functionEvent(id, eventId, event)
+		}
+	}
+}
+
+func samFunctionCode(properties map[string]interface{}) interface{} {
+	if inline, ok := properties["InlineCode"]; ok {
+		return map[string]interface{}{"ZipFile": inline}
+	}
+	if image, ok := properties["ImageUri"]; ok {
+		return map[string]interface{}{"ImageUri": image}
+	}
+	if uri, ok := properties["CodeUri"]; ok {
+		return samS3Location(uri, "S3Bucket", "S3Key", "S3ObjectVersion")
+	}
+	return nil
+}
+
+func samFunctionRole(id string, properties map[string]interface{}, tags interface{}) map[string]interface{} {
+	managed := []interface{}{"arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"}
+	if _, ok := properties["VpcConfig"]; ok {
+		managed = append(managed, "arn:aws:iam::aws:policy/service-role/AWSLambdaVPCAccessExecutionRole")
+	}
+	if properties["Tracing"] == "Active" {
+		managed = append(managed, "arn:aws:iam::aws:policy/AWSXrayWriteOnlyAccess")
+	}
+	inline := []interface{}{}
+	policies, ok := properties["Policies"].([]interface{})
+	if !ok && properties["Policies"] != nil {
+		policies = []interface{}{properties["Policies"]}
+	}
+	for _, policy := range policies {
+		switch p := policy.(type) {
+		case string:
+			if !strings.HasPrefix(p, "arn:") {
+				p = "arn:aws:iam::aws:policy/" + p
+			}
+			managed = append(managed, p)
+		case map[string]interface{}:
+			if _, ok := p["Statement"]; ok {
+				inline = append(inline, map[string]interface{}{
+					"PolicyName":     fmt.Sprintf("%sRolePolicy%d", id, len(inline)),
+					"PolicyDocument": p,
+				})
+			} else if isCfnIntrinsic(p) {
+				managed = append(managed, p)
+			}
+			// SAM policy templates are not expanded.
+		}
+	}
+
+	role := map[string]interface{}{
+		"AssumeRolePolicyDocument": map[string]interface{}{
+			"Version": "2012-10-17",
+			"Statement": []interface{}{
+				map[string]interface{}{
+					"Action": []interface{}{"sts:AssumeRole"},
+					"Effect": "Allow",
+					"Principal": map[string]interface{}{
+						"Service": []interface{}{"lambda.amazonaws.com"},
+					},
+				},
+			},
+		},
+		"ManagedPolicyArns": managed,
+		"Tags":              tags,
+	}
+	if len(inline) > 0 {
+		role["Policies"] = inline
+	}
+	if boundary, ok := properties["PermissionsBoundary"]; ok {
+		role["PermissionsBoundary"] = boundary
+	}
+	return role
+}
+
+func (t *samTransformer) functionEvent(id string, eventId string, event map[string]interface{}) {
+	properties, _ := event["Properties"].(map[string]interface{})
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	functionRef := map[string]interface{}{"Ref": id}
+	functionArn := map[string]interface{}{"Fn::GetAtt": []interface{}{id, "Arn"}}
+	permission := func(principal string) {
+		t.add(id, id+eventId+"Permission", "AWS::Lambda::Permission", map[string]interface{}{
+			"Action":       "lambda:InvokeFunction",
+			"FunctionName": functionRef,
+			"Principal":    principal,
+		})
+	}
+
+	switch event["Type"] {
+	case "Api":
+		if _, ok := properties["RestApiId"]; !ok {
+			t.implicitApi(id, properties)
+		}
+		permission("apigateway.amazonaws.com")
+	case "HttpApi":
+		if _, ok := properties["ApiId"]; !ok {
+			t.implicitHttpApi(id, properties)
+		}
+		permission("apigateway.amazonaws.com")
+	case "Schedule":
+		rule := map[string]interface{}{
+			"ScheduleExpression": properties["Schedule"],
+			"State":              "ENABLED",
+			"Targets": []interface{}{
+				map[string]interface{}{"Arn": functionArn, "Id": id + eventId + "LambdaTarget"},
+			},
+		}
+		if enabled, ok := properties["Enabled"].(bool); ok && !enabled {
+			rule["State"] = "DISABLED"
+		}
+		samCopyInto(rule, properties, []string{"Description", "Name"})
+		t.add(id, id+eventId, "AWS::Events::Rule", rule)
+		permission("events.amazonaws.com")
+	case "SQS", "Kinesis", "DynamoDB":
+		source := map[string]interface{}{"FunctionName": functionRef}
+		if arn, ok := properties["Queue"]; ok {
+			source["EventSourceArn"] = arn
+		} else {
+			source["EventSourceArn"] = properties["Stream"]
+		}
+		samCopyInto(source, properties, []string{
+			"BatchSize",
+			"BisectBatchOnFunctionError",
+			"DestinationConfig",
+			"Enabled",
+			"FilterCriteria",
+			"MaximumBatchingWindowInSeconds",
+			"MaximumRecordAgeInSeconds",
+			"MaximumRetryAttempts",
+			"ParallelizationFactor",
+			"StartingPosition",
+		})
+		t.add(id, id+eventId, "AWS::Lambda::EventSourceMapping", source)
+	case "SNS":
+		subscription := map[string]interface{}{
+			"Endpoint": functionArn,
+			"Protocol": "lambda",
+			"TopicArn": properties["Topic"],
+		}
+		samCopyInto(subscription, properties, []string{"FilterPolicy", "Region"})
+		t.add(id, id+eventId, "AWS::SNS::Subscription", subscription)
+		permission("sns.amazonaws.com")
+	}
+}
+
+// implicitApi adds the API that SAM creates for Api events that don't refer
+// to an API, and adds the path of the event to it.
+func (t *samTransformer) implicitApi(id string, properties map[string]interface{}) {
+	apiId := "ServerlessRestApi"
+	if _, ok := t.resources[apiId]; !ok {
+		t.add(id, apiId, "AWS::ApiGateway::RestApi", map[string]interface{}{
+			"Body": map[string]interface{}{
+				"swagger": "2.0",
+				"info":    map[string]interface{}{"version": "1.0", "title": map[string]interface{}{"Ref": "AWS::StackName"}},
+				"paths":   map[string]interface{}{},
+			},
+		})
+		t.add(id, apiId+"Deployment", "AWS::ApiGateway::Deployment", map[string]interface{}{
+			"RestApiId": map[string]interface{}{"Ref": apiId},
+		})
+		t.add(id, apiId+"ProdStage", "AWS::ApiGateway::Stage", map[string]interface{}{
+			"DeploymentId": map[string]interface{}{"Ref": apiId + "Deployment"},
+			"RestApiId":    map[string]interface{}{"Ref": apiId},
+			"StageName":    "Prod",
+		})
+	}
+	samAddPath(t.resources[apiId], properties, id, "swagger")
+}
+
+func (t *samTransformer) implicitHttpApi(id string, properties map[string]interface{}) {
+	apiId := "ServerlessHttpApi"
+	if _, ok := t.resources[apiId]; !ok {
+		t.add(id, apiId, "AWS::ApiGatewayV2::Api", map[string]interface{}{
+			"Body": map[string]interface{}{
+				"openapi": "3.0.1",
+				"info":    map[string]interface{}{"version": "1.0", "title": map[string]interface{}{"Ref": "AWS::StackName"}},
+				"paths":   map[string]interface{}{},
+			},
+		})
+		t.add(id, apiId+"ApiGatewayDefaultStage", "AWS::ApiGatewayV2::Stage", map[string]interface{}{
+			"ApiId":      map[string]interface{}{"Ref": apiId},
+			"AutoDeploy": true,
+			"StageName":  "$default",
+		})
+	}
+	samAddPath(t.resources[apiId], properties, id, "openapi")
+}
+
+// samAddPath adds the path and method of an event to the definition of an
+// implicit API.
+func samAddPath(api interface{}, properties map[string]interface{}, functionId string, format string) {
+	path, _ := properties["Path"].(string)
+	method, _ := properties["Method"].(string)
+	if path == "" || method == "" {
+		return
+	}
+	body := api.(map[string]interface{})["Properties"].(map[string]interface{})["Body"].(map[string]interface{})
+	paths := body["paths"].(map[string]interface{})
+	methods, ok := paths[path].(map[string]interface{})
+	if !ok {
+		methods = map[string]interface{}{}
+		paths[path] = methods
+	}
+	if method == "any" {
+		method = "x-amazon-apigateway-any-method"
+	}
+	integration := map[string]interface{}{
+		"httpMethod": "POST",
+		"type":       "aws_proxy",
+		"uri": map[string]interface{}{
+			"Fn::Sub": fmt.Sprintf("arn:${AWS::Partition}:apigateway:${AWS::Region}:lambda:path/2015-03-31/functions/${%s.Arn}/invocations", functionId),
+		},
+	}
+	if format == "openapi" {
+		integration["payloadFormatVersion"] = "2.0"
+	}
+	methods[strings.ToLower(method)] = map[string]interface{}{
+		"x-amazon-apigateway-integration": integration,
+	}
+}
+
+func (t *samTransformer) api(id string, properties map[string]interface{}) {
+	api := samCopy(properties, samApiProperties)
+	if body, ok := properties["DefinitionBody"]; ok {
+		api["Body"] = body
+	}
+	if uri, ok := properties["DefinitionUri"]; ok {
+		api["BodyS3Location"] = samS3Location(uri, "Bucket", "Key", "Version")
+	}
+	endpointType := samEndpointType(properties["EndpointConfiguration"])
+	if endpointType != nil {
+		api["EndpointConfiguration"] = map[string]interface{}{"Types": []interface{}{endpointType}}
+	}
+	t.add(id, id, "AWS::ApiGateway::RestApi", api)
+
+	deploymentId := id + "Deployment"
+	t.add(id, deploymentId, "AWS::ApiGateway::Deployment", map[string]interface{}{
+		"RestApiId": map[string]interface{}{"Ref": id},
+	})
+	stage := samCopy(properties, samStageProperties)
+	stage["RestApiId"] = map[string]interface{}{"Ref": id}
+	stage["DeploymentId"] = map[string]interface{}{"Ref": deploymentId}
+	stage["StageName"] = properties["StageName"]
+	if tags, ok := properties["Tags"]; ok {
+		stage["Tags"] = samTags(tags, nil)
+	}
+	stageName, _ := properties["StageName"].(string)
+	t.add(id, id+stageName+"Stage", "AWS::ApiGateway::Stage", stage)
+
+	if domain, ok := properties["Domain"].(map[string]interface{}); ok {
+		domainName := samCopy(domain, []string{"DomainName", "MutualTlsAuthentication", "OwnershipVerificationCertificateArn", "SecurityPolicy"})
+		domainType := samEndpointType(domain["EndpointConfiguration"])
+		if domainType == nil {
+			domainType = "REGIONAL"
+		}
+		domainName["EndpointConfiguration"] = map[string]interface{}{"Types": []interface{}{domainType}}
+		if domainType == "EDGE" {
+			domainName["CertificateArn"] = domain["CertificateArn"]
+		} else {
+			domainName["RegionalCertificateArn"] = domain["CertificateArn"]
+		}
+		t.add(id, id+"DomainName", "AWS::ApiGateway::DomainName", domainName)
+	}
+}
+
+// samEndpointType returns the endpoint type in an endpoint configuration,
+// which can be a string or an object with a Type.
+func samEndpointType(configuration interface{}) interface{} {
+	if c, ok := configuration.(map[string]interface{}); ok && !isCfnIntrinsic(c) {
+		return c["Type"]
+	}
+	return configuration
+}
+
+func (t *samTransformer) httpApi(id string, properties map[string]interface{}) {
+	api := samCopy(properties, []string{"CorsConfiguration", "Description", "DisableExecuteApiEndpoint", "FailOnWarnings"})
+	if body, ok := properties["DefinitionBody"]; ok {
+		api["Body"] = body
+	} else if uri, ok := properties["DefinitionUri"]; ok {
+		api["BodyS3Location"] = samS3Location(uri, "Bucket", "Key", "Version")
+	} else {
+		api["Body"] = map[string]interface{}{
+			"openapi": "3.0.1",
+			"info":    map[string]interface{}{"version": "1.0", "title": map[string]interface{}{"Ref": "AWS::StackName"}},
+			"paths":   map[string]interface{}{},
+		}
+	}
+	if tags, ok := properties["Tags"]; ok {
+		api["Tags"] = tags
+	}
+	t.add(id, id, "AWS::ApiGatewayV2::Api", api)
+
+	stage := map[string]interface{}{
+		"ApiId":      map[string]interface{}{"Ref": id},
+		"AutoDeploy": true,
+		"StageName":  "$default",
+	}
+	if stageName, ok := properties["StageName"]; ok {
+		stage["StageName"] = stageName
+	}
+	for from, to := range samHttpApiStageProperties {
+		if value, ok := properties[from]; ok {
+			stage[to] = value
+		}
+	}
+	t.add(id, id+"ApiGatewayDefaultStage", "AWS::ApiGatewayV2::Stage", stage)
+
+	if domain, ok := properties["Domain"].(map[string]interface{}); ok {
+		configuration := samCopy(domain, []string{"CertificateArn", "OwnershipVerificationCertificateArn", "SecurityPolicy"})
+		endpointType := samEndpointType(domain["EndpointConfiguration"])
+		if endpointType == nil {
+			endpointType = "REGIONAL"
+		}
+		configuration["EndpointType"] = endpointType
+		domainName := samCopy(domain, []string{"DomainName", "MutualTlsAuthentication"})
+		domainName["DomainNameConfigurations"] = []interface{}{configuration}
+		t.add(id, id+"DomainName", "AWS::ApiGatewayV2::DomainName", domainName)
+	}
+}
+
+var samAttributeTypes = map[string]string{
+	"String": "S",
+	"Number": "N",
+	"Binary": "B",
+}
+
+func (t *samTransformer) simpleTable(id string, properties map[string]interface{}) {
+	name, attributeType := "id", "S"
+	if key, ok := properties["PrimaryKey"].(map[string]interface{}); ok {
+		if n, ok := key["Name"].(string); ok {
+			name = n
+		}
+		if at, ok := samAttributeTypes[fmt.Sprint(key["Type"])]; ok {
+			attributeType = at
+		}
+	}
+	table := samCopy(properties, []string{"ProvisionedThroughput", "SSESpecification", "TableName"})
+	table["AttributeDefinitions"] = []interface{}{
+		map[string]interface{}{"AttributeName": name, "AttributeType": attributeType},
+	}
+	table["KeySchema"] = []interface{}{
+		map[string]interface{}{"AttributeName": name, "KeyType": "HASH"},
+	}
+	if _, ok := properties["ProvisionedThroughput"]; !ok {
+		table["BillingMode"] = "PAY_PER_REQUEST"
+	}
+	if tags, ok := properties["Tags"]; ok {
+		table["Tags"] = samTags(tags, nil)
+	}
+	t.add(id, id, "AWS::DynamoDB::Table", table)
+}
+
+func (t *samTransformer) layerVersion(id string, properties map[string]interface{}) {
+	layer := samCopy(properties, []string{"CompatibleArchitectures", "CompatibleRuntimes", "Description", "LayerName", "LicenseInfo"})
+	if uri, ok := properties["ContentUri"]; ok {
+		layer["Content"] = samS3Location(uri, "S3Bucket", "S3Key", "S3ObjectVersion")
+	}
+	t.add(id, id, "AWS::Lambda::LayerVersion", layer)
+}
+
+// application turns a nested application into a nested stack, which is
+// expanded if the template is a local file.
+func (t *samTransformer) application(id string, properties map[string]interface{}) {
+	stack := samCopy(properties, []string{"NotificationARNs", "Parameters", "TimeoutInMinutes"})
+	if location, ok := properties["Location"].(string); ok {
+		stack["TemplateURL"] = location
+	}
+	if tags, ok := properties["Tags"]; ok {
+		stack["Tags"] = samTags(tags, nil)
+	}
+	t.add(id, id, "AWS::CloudFormation::Stack", stack)
+}
+
+// samMergeGlobals merges the properties of a resource into the globals for
+// its type.  Maps are merged, lists are concatenated, and otherwise the
+// resource's properties take precedence.
+func samMergeGlobals(globals map[string]interface{}, properties map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for k, v := range globals {
+		merged[k] = v
+	}
+	for k, v := range properties {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			if global, ok := merged[k].(map[string]interface{}); ok && !isCfnIntrinsic(value) && !isCfnIntrinsic(global) {
+				merged[k] = samMergeGlobals(global, value)
+				continue
+			}
+		case []interface{}:
+			if global, ok := merged[k].([]interface{}); ok {
+				merged[k] = append(append([]interface{}{}, global...), value...)
+				continue
+			}
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+func samCopy(properties map[string]interface{}, keys []string) map[string]interface{} {
+	copied := map[string]interface{}{}
+	samCopyInto(copied, properties, keys)
+	return copied
+}
+
+func samCopyInto(dst map[string]interface{}, properties map[string]interface{}, keys []string) {
+	for _, key := range keys {
+		if value, ok := properties[key]; ok {
+			dst[key] = value
+		}
+	}
+}
+
+// samTags converts SAM tags, which are a map, to a list of CloudFormation
+// tags.
+func samTags(tags interface{}, extra map[string]interface{}) interface{} {
+	m, ok := tags.(map[string]interface{})
+	if tags != nil && (!ok || isCfnIntrinsic(m)) {
+		return tags
+	}
+	all := map[string]interface{}{}
+	for k, v := range extra {
+		all[k] = v
+	}
+	for k, v := range m {
+		all[k] = v
+	}
+	keys := []string{}
+	for k := range all {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	list := []interface{}{}
+	for _, k := range keys {
+		list = append(list, map[string]interface{}{"Key": k, "Value": all[k]})
+	}
+	return list
+}
+
+// samS3Location converts an S3 URI or a SAM S3 location object to a
+// CloudFormation S3 location with the given keys.  Local paths are
+// converted to an empty location, since they are uploaded on deployment.
+func samS3Location(uri interface{}, bucketKey string, keyKey string, versionKey string) interface{} {
+	switch u := uri.(type) {
+	case string:
+		if strings.HasPrefix(u, "s3://") {
+			parts := strings.SplitN(strings.TrimPrefix(u, "s3://"), "/", 2)
+			location := map[string]interface{}{bucketKey: parts[0]}
+			if len(parts) > 1 {
+				location[keyKey] = parts[1]
+			}
+			return location
+		}
+		return map[string]interface{}{}
+	case map[string]interface{}:
+		if isCfnIntrinsic(u) {
+			return u
+		}
+		location := map[string]interface{}{}
+		for from, to := range map[string]string{"Bucket": bucketKey, "Key": keyKey, "Version": versionKey} {
+			if value, ok := u[from]; ok {
+				location[to] = value
+			}
+		}
+		return location
+	}
+	return uri
+}
+
+// isCfnIntrinsic returns true for objects that are intrinsic function calls.
+func isCfnIntrinsic(m map[string]interface{}) bool {
+	if len(m) != 1 {
+		return false
+	}
+	for k := range m {
+		return k == "Ref" || k == "Condition" || strings.HasPrefix(k, "Fn::")
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+# regula:ignore FG_R00100,FG_R00101 reason="Logs are shipped elsewhere"
+resource "aws_s3_bucket" "ignored" {
+  bucket = "ignored"
+}
+
+resource "aws_s3_bucket" "trailing" { # regula:ignore FG_R00229
+  bucket = "trailing"
+}
+
+# This comment is separated from the resource below.
+# regula:ignore FG_R00229
+
+resource "aws_s3_bucket" "separated" {
+  bucket = "separated"
+}
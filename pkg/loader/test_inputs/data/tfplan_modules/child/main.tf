@@ -0,0 +1,8 @@
+variable "name" {
+  type = string
+}
+
+resource "aws_s3_bucket" "child" {
+  count  = 2
+  bucket = "${var.name}-${count.index}"
+}
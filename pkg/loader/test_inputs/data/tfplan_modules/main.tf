@@ -0,0 +1,16 @@
+provider "aws" {
+  region = "us-east-1"
+}
+
+resource "aws_s3_bucket" "root" {
+  bucket = "root-bucket"
+
+  versioning {
+    enabled = true
+  }
+}
+
+module "child" {
+  source = "./child"
+  name   = "child-bucket"
+}
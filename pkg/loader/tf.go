@@ -46,7 +46,7 @@ func (t *TfDetector) DetectFile(i InputFile, opts DetectOptions) (IACConfigurati
 		}
 	}
 
-	moduleTree, err := hcl_interpreter.ParseFiles(nil, inputFs, false, dir, []string{i.Path()}, opts.VarFiles)
+	moduleTree, err := hcl_interpreter.ParseFiles(nil, inputFs, false, dir, []string{i.Path()}, tfVarFiles(opts.VarFiles))
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +81,7 @@ func (t *TfDetector) DetectDirectory(i InputDirectory, opts DetectOptions) (IACC
 
 	fs := &afero.OsFs{}
 	moduleRegister := hcl_interpreter.NewTerraformRegister(fs, i.Path())
-	moduleTree, err := hcl_interpreter.ParseDirectory(moduleRegister, fs, i.Path(), opts.VarFiles)
+	moduleTree, err := hcl_interpreter.ParseDirectory(moduleRegister, fs, i.Path(), tfVarFiles(opts.VarFiles))
 	if err != nil {
 		return nil, err
 	}
@@ -150,6 +150,18 @@ func (c *HclConfiguration) RegulaInput() RegulaInput {
 	}
 }
 
+// tfVarFiles leaves out the Helm values files, ARM parameter files and
+// CloudFormation parameter files from the --var-file paths.
+func tfVarFiles(varFiles []string) []string {
+	filtered := []string{}
+	for _, varFile := range varFiles {
+		if !isHelmValuesFile(varFile) && !isArmParameterFile(varFile) && !isCfnParameterFile(varFile) {
+			filtered = append(filtered, varFile)
+		}
+	}
+	return filtered
+}
+
 func hasTerraformExt(path string) bool {
 	return strings.HasSuffix(path, ".tf") || strings.HasSuffix(path, ".tf.json")
 }
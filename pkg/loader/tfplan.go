@@ -15,8 +15,16 @@
 package loader
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
 	"gopkg.in/yaml.v3"
 )
 
@@ -40,9 +48,19 @@ func (t *TfPlanDetector) DetectFile(i InputFile, opts DetectOptions) (IACConfigu
 		return nil, fmt.Errorf("Input file is not Terraform Plan JSON: %v", i.Path())
 	}
 
+	// The plan JSON does not contain source code locations, but its
+	// configuration section lets us find them in the .tf files that produced
+	// the plan.  We assume those live next to the plan file.
+	path := i.Path()
+	configDir := ""
+	if path != stdIn {
+		configDir = filepath.Dir(path)
+	}
+
 	return &tfPlanLoader{
-		path:    i.Path(),
-		content: j,
+		path:      path,
+		content:   j,
+		configDir: configDir,
 	}, nil
 }
 
@@ -51,8 +69,13 @@ func (t *TfPlanDetector) DetectDirectory(i InputDirectory, opts DetectOptions) (
 }
 
 type tfPlanLoader struct {
-	path    string
-	content *map[string]interface{}
+	path      string
+	content   *map[string]interface{}
+	configDir string
+
+	// Lazily parsed on the first call to Location.
+	sourceOnce sync.Once
+	source     *tfPlanSource
 }
 
 func (l *tfPlanLoader) RegulaInput() RegulaInput {
@@ -67,5 +90,284 @@ func (l *tfPlanLoader) LoadedFiles() []string {
 }
 
 func (l *tfPlanLoader) Location(attributePath []string) (LocationStack, error) {
-	return nil, nil
+	if l.configDir == "" || len(attributePath) < 1 {
+		return nil, nil
+	}
+	l.sourceOnce.Do(func() {
+		l.source = newTfPlanSource(l.configDir, l.content)
+	})
+	return l.source.location(attributePath[0], attributePath[1:]), nil
+}
+
+// tfPlanSource maps resource addresses from a plan back to the HCL blocks that
+// declared them.  Addresses are stored without instance keys, so both
+// `aws_s3_bucket.b[0]` and `aws_s3_bucket.b["x"]` resolve to the same block.
+type tfPlanSource struct {
+	// Resource blocks, keyed by their full address without instance keys,
+	// e.g. `module.child.aws_s3_bucket.b`.
+	resources map[string]*hcl.Block
+	// Module call blocks, keyed by their module path without instance keys,
+	// e.g. `module.child.module.grandchild`.
+	modules map[string]*hcl.Block
+}
+
+// tfPlanModuleConfig mirrors the parts of a module in the plan's configuration
+// section that we need.
+type tfPlanModuleConfig struct {
+	Resources []struct {
+		Address string `json:"address"`
+	} `json:"resources"`
+	ModuleCalls map[string]struct {
+		Source string             `json:"source"`
+		Module tfPlanModuleConfig `json:"module"`
+	} `json:"module_calls"`
+}
+
+func newTfPlanSource(configDir string, content *map[string]interface{}) *tfPlanSource {
+	source := &tfPlanSource{
+		resources: map[string]*hcl.Block{},
+		modules:   map[string]*hcl.Block{},
+	}
+
+	configuration, ok := (*content)["configuration"].(map[string]interface{})
+	if !ok {
+		return source
+	}
+	rootModule := tfPlanModuleConfig{}
+	// Round trip through JSON rather than picking the maps apart by hand.
+	if bytes, err := json.Marshal(configuration["root_module"]); err != nil {
+		return source
+	} else if err := json.Unmarshal(bytes, &rootModule); err != nil {
+		return source
+	}
+
+	source.addModule(
+		hclparse.NewParser(),
+		newTfModuleDirs(configDir),
+		configDir,
+		nil,
+		rootModule,
+	)
+	return source
+}
+
+func (s *tfPlanSource) addModule(
+	parser *hclparse.Parser,
+	dirs tfModuleDirs,
+	dir string,
+	modulePath []string,
+	config tfPlanModuleConfig,
+) {
+	prefix := ""
+	for _, name := range modulePath {
+		prefix += "module." + name + "."
+	}
+
+	blocks := parseTfBlocks(parser, dir)
+	for _, resource := range config.Resources {
+		if block, ok := blocks[resource.Address]; ok {
+			s.resources[prefix+resource.Address] = block
+		}
+	}
+
+	for name, call := range config.ModuleCalls {
+		if block, ok := blocks["module."+name]; ok {
+			s.modules[prefix+"module."+name] = block
+		}
+		childPath := append(append([]string{}, modulePath...), name)
+		if childDir := dirs.resolve(dir, childPath, call.Source); childDir != "" {
+			s.addModule(parser, dirs, childDir, childPath, call.Module)
+		}
+	}
+}
+
+func (s *tfPlanSource) location(address string, attributePath []string) LocationStack {
+	address = stripInstanceKeys(address)
+	block, ok := s.resources[address]
+	if !ok {
+		return nil
+	}
+
+	location := block.DefRange
+	if len(attributePath) > 0 {
+		location = hclBodyRange(block.Body, block.DefRange, attributePath)
+	}
+
+	// Construct stack with modules, innermost first.
+	locs := LocationStack{hclRangeToLocation(location)}
+	parts := strings.Split(address, ".")
+	depth := 0
+	for depth+1 < len(parts) && parts[depth] == "module" {
+		depth += 2
+	}
+	for ; depth >= 2; depth -= 2 {
+		if module, ok := s.modules[strings.Join(parts[:depth], ".")]; ok {
+			locs = append(locs, hclRangeToLocation(module.DefRange))
+		}
+	}
+	return locs
+}
+
+// tfModuleDirs resolves module calls to directories on disk.  Local module
+// sources are resolved relative to the calling module.  For remote sources we
+// rely on the `.terraform/modules/modules.json` file written by
+// `terraform init`.
+type tfModuleDirs struct {
+	rootDir string
+	byKey   map[string]string
+}
+
+func newTfModuleDirs(rootDir string) tfModuleDirs {
+	dirs := tfModuleDirs{
+		rootDir: rootDir,
+		byKey:   map[string]string{},
+	}
+	bytes, err := os.ReadFile(filepath.Join(rootDir, ".terraform", "modules", "modules.json"))
+	if err != nil {
+		return dirs
+	}
+	register := struct {
+		Modules []struct {
+			Key string `json:"Key"`
+			Dir string `json:"Dir"`
+		} `json:"Modules"`
+	}{}
+	if err := json.Unmarshal(bytes, &register); err != nil {
+		return dirs
+	}
+	for _, m := range register.Modules {
+		dirs.byKey[m.Key] = m.Dir
+	}
+	return dirs
+}
+
+func (d tfModuleDirs) resolve(parentDir string, modulePath []string, source string) string {
+	if dir, ok := d.byKey[strings.Join(modulePath, ".")]; ok {
+		return filepath.Join(d.rootDir, dir)
+	}
+	if strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+		return filepath.Join(parentDir, source)
+	}
+	return ""
+}
+
+// parseTfBlocks parses the .tf and .tf.json files in a single directory and
+// returns the resource, data and module blocks by their local address.
+func parseTfBlocks(parser *hclparse.Parser, dir string) map[string]*hcl.Block {
+	blocks := map[string]*hcl.Block{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return blocks
+	}
+
+	schema := &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "resource", LabelNames: []string{"type", "name"}},
+			{Type: "data", LabelNames: []string{"type", "name"}},
+			{Type: "module", LabelNames: []string{"name"}},
+		},
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		var file *hcl.File
+		if entry.IsDir() {
+			continue
+		} else if strings.HasSuffix(path, ".tf") {
+			file, _ = parser.ParseHCLFile(path)
+		} else if strings.HasSuffix(path, ".tf.json") {
+			file, _ = parser.ParseJSONFile(path)
+		}
+		if file == nil {
+			continue
+		}
+		content, _, _ := file.Body.PartialContent(schema)
+		if content == nil {
+			continue
+		}
+		for _, block := range content.Blocks {
+			switch block.Type {
+			case "resource":
+				blocks[block.Labels[0]+"."+block.Labels[1]] = block
+			case "data":
+				blocks["data."+block.Labels[0]+"."+block.Labels[1]] = block
+			case "module":
+				blocks["module."+block.Labels[0]] = block
+			}
+		}
+	}
+	return blocks
+}
+
+// hclBodyRange tries to find an attribute path inside a body as far as
+// possible, returning the range of the most specific match.
+func hclBodyRange(body hcl.Body, defRange hcl.Range, path []string) hcl.Range {
+	if len(path) == 0 || body == nil {
+		return defRange
+	}
+
+	key := path[0]
+	content, _, _ := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: key}},
+		Blocks:     []hcl.BlockHeaderSchema{{Type: key}},
+	})
+	if content == nil {
+		return defRange
+	}
+	if attribute, ok := content.Attributes[key]; ok {
+		return attribute.Range
+	}
+	blocks := content.Blocks.OfType(key)
+	if len(blocks) == 0 {
+		return defRange
+	}
+
+	// Nested blocks are addressed by index, e.g. `["rule", "0", "name"]`.
+	index := 0
+	rest := path[1:]
+	if len(rest) > 0 {
+		if i, err := strconv.Atoi(rest[0]); err == nil {
+			index = i
+			rest = rest[1:]
+		}
+	}
+	if index < 0 || index >= len(blocks) {
+		return blocks[0].DefRange
+	}
+	return hclBodyRange(blocks[index].Body, blocks[index].DefRange, rest)
+}
+
+func hclRangeToLocation(r hcl.Range) Location {
+	return Location{
+		Path: r.Filename,
+		Line: r.Start.Line,
+		Col:  r.Start.Column,
+	}
+}
+
+// stripInstanceKeys removes the `[0]` and `["key"]` parts of a resource
+// address, e.g. `module.a["x"].aws_s3_bucket.b[0]` becomes
+// `module.a.aws_s3_bucket.b`.
+func stripInstanceKeys(address string) string {
+	var sb strings.Builder
+	depth := 0
+	quoted := false
+	for i := 0; i < len(address); i++ {
+		c := address[i]
+		switch {
+		case quoted && c == '\\':
+			i++
+		case quoted && c == '"':
+			quoted = false
+		case quoted:
+		case depth > 0 && c == '"':
+			quoted = true
+		case c == '[':
+			depth++
+		case c == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
 }
@@ -15,6 +15,7 @@
 package loader_test
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/fugue/regula/v3/pkg/loader"
@@ -97,3 +98,46 @@ func TestTfPlanDetectorNotYAML(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Nil(t, loader)
 }
+
+func TestTfPlanLocation(t *testing.T) {
+	dir := filepath.Join("test_inputs", "data", "tfplan_modules")
+	planPath := filepath.Join(dir, "plan.json")
+	loadedConfigs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths:      []string{planPath},
+		InputTypes: []loader.InputType{loader.TfPlan},
+	})()
+	assert.Nil(t, err)
+	testInputs := []struct {
+		path     []string
+		expected loader.LocationStack
+	}{
+		{
+			path: []string{"aws_s3_bucket.root"},
+			expected: loader.LocationStack{
+				{Path: filepath.Join(dir, "main.tf"), Line: 5, Col: 1},
+			},
+		},
+		{
+			path: []string{"aws_s3_bucket.root", "versioning", "0", "enabled"},
+			expected: loader.LocationStack{
+				{Path: filepath.Join(dir, "main.tf"), Line: 9, Col: 5},
+			},
+		},
+		{
+			path: []string{"module.child.aws_s3_bucket.child[1]"},
+			expected: loader.LocationStack{
+				{Path: filepath.Join(dir, "child", "main.tf"), Line: 5, Col: 1},
+				{Path: filepath.Join(dir, "main.tf"), Line: 13, Col: 1},
+			},
+		},
+		{
+			path:     []string{"aws_s3_bucket.missing"},
+			expected: nil,
+		},
+	}
+	for _, i := range testInputs {
+		loc, err := loadedConfigs.Location(planPath, i.path)
+		assert.Nil(t, err)
+		assert.Equal(t, i.expected, loc)
+	}
+}
@@ -60,6 +60,20 @@ func (mr *MockLoadedConfigurationsMockRecorder) AlreadyLoaded(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AlreadyLoaded", reflect.TypeOf((*MockLoadedConfigurations)(nil).AlreadyLoaded), arg0)
 }
 
+// Configuration mocks base method.
+func (m *MockLoadedConfigurations) Configuration(arg0 string) loader.IACConfiguration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Configuration", arg0)
+	ret0, _ := ret[0].(loader.IACConfiguration)
+	return ret0
+}
+
+// Configuration indicates an expected call of Configuration.
+func (mr *MockLoadedConfigurationsMockRecorder) Configuration(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Configuration", reflect.TypeOf((*MockLoadedConfigurations)(nil).Configuration), arg0)
+}
+
 // ConfigurationPath mocks base method.
 func (m *MockLoadedConfigurations) ConfigurationPath(arg0 string) *string {
 	m.ctrl.T.Helper()
@@ -88,6 +102,20 @@ func (mr *MockLoadedConfigurationsMockRecorder) Count() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockLoadedConfigurations)(nil).Count))
 }
 
+// InlineIgnores mocks base method.
+func (m *MockLoadedConfigurations) InlineIgnores(arg0, arg1 string) []loader.InlineIgnore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InlineIgnores", arg0, arg1)
+	ret0, _ := ret[0].([]loader.InlineIgnore)
+	return ret0
+}
+
+// InlineIgnores indicates an expected call of InlineIgnores.
+func (mr *MockLoadedConfigurationsMockRecorder) InlineIgnores(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InlineIgnores", reflect.TypeOf((*MockLoadedConfigurations)(nil).InlineIgnores), arg0, arg1)
+}
+
 // Location mocks base method.
 func (m *MockLoadedConfigurations) Location(arg0 string, arg1 []string) ([]loader.Location, error) {
 	m.ctrl.T.Helper()
@@ -103,6 +131,20 @@ func (mr *MockLoadedConfigurationsMockRecorder) Location(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Location", reflect.TypeOf((*MockLoadedConfigurations)(nil).Location), arg0, arg1)
 }
 
+// Paths mocks base method.
+func (m *MockLoadedConfigurations) Paths() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Paths")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// Paths indicates an expected call of Paths.
+func (mr *MockLoadedConfigurationsMockRecorder) Paths() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Paths", reflect.TypeOf((*MockLoadedConfigurations)(nil).Paths))
+}
+
 // RegulaInput mocks base method.
 func (m *MockLoadedConfigurations) RegulaInput() []loader.RegulaInput {
 	m.ctrl.T.Helper()
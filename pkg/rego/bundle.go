@@ -0,0 +1,278 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rego
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// BundleManifestName is the name of the manifest file in a signed rule
+// bundle.  The manifest lists the SHA-256 digest of every rego file in the
+// directory that contains it.  Other files are not loaded and are not
+// verified.
+const BundleManifestName = "regula-manifest.json"
+
+// BundleSignatureName is the name of the file that contains the base64
+// encoded signature of the manifest, e.g. as written by `cosign sign-blob`.
+const BundleSignatureName = BundleManifestName + ".sig"
+
+// BundleManifest is the contents of a regula-manifest.json file.
+type BundleManifest struct {
+	Files []BundleManifestFile `json:"files"`
+}
+
+type BundleManifestFile struct {
+	// Path is relative to the directory that contains the manifest.
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// NewBundleManifest creates a manifest for the given files, which are
+// indexed by their path relative to the manifest.
+func NewBundleManifest(files map[string][]byte) *BundleManifest {
+	manifest := &BundleManifest{Files: []BundleManifestFile{}}
+	for p, contents := range files {
+		sum := sha256.Sum256(contents)
+		manifest.Files = append(manifest.Files, BundleManifestFile{
+			Path:   p,
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+	return manifest
+}
+
+func (m *BundleManifest) Marshal() ([]byte, error) {
+	contents, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(contents, '\n'), nil
+}
+
+// BundleVerificationError is returned when a rule bundle is not signed by
+// the expected key, or when one of its files doesn't match the manifest.
+type BundleVerificationError struct {
+	// File is the path of the file in the bundle that failed verification.
+	File   string
+	Reason string
+}
+
+func (e *BundleVerificationError) Error() string {
+	return fmt.Sprintf("Bundle verification failed for %s: %s", e.File, e.Reason)
+}
+
+// BundleKey is a public key that rule bundles must be signed with.
+// Supported keys are ed25519, ECDSA (the default for cosign) and RSA keys
+// in PEM encoded PKIX format.
+type BundleKey struct {
+	key crypto.PublicKey
+}
+
+// LoadBundleKey reads a PEM encoded public key.
+func LoadBundleKey(path string) (*BundleKey, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, fmt.Errorf("Invalid verify key %s: no PEM encoded public key found", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid verify key %s: %w", path, err)
+	}
+	switch key.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey, *rsa.PublicKey:
+		return &BundleKey{key: key}, nil
+	default:
+		return nil, fmt.Errorf("Invalid verify key %s: unsupported key type %T", path, key)
+	}
+}
+
+func (k *BundleKey) verifySignature(message []byte, signature []byte) bool {
+	digest := sha256.Sum256(message)
+	switch key := k.key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, message, signature)
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	}
+	return false
+}
+
+// bundleFile is a regular file in a rule bundle.
+type bundleFile struct {
+	path     string
+	contents []byte
+}
+
+// cleanBundlePath normalizes paths in tarballs, which are often prefixed
+// with "./".
+func cleanBundlePath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
+// verifyBundle checks that the bundle contains a manifest that is signed by
+// the key, and that every file in the bundle matches the manifest.
+func (k *BundleKey) verifyBundle(files []bundleFile) error {
+	byPath := map[string][]byte{}
+	manifestPath := ""
+	for _, f := range files {
+		p := cleanBundlePath(f.path)
+		byPath[p] = f.contents
+		if path.Base(p) == BundleManifestName {
+			if manifestPath != "" {
+				return &BundleVerificationError{
+					File:   p,
+					Reason: fmt.Sprintf("bundle contains more than one manifest, also found %s", manifestPath),
+				}
+			}
+			manifestPath = p
+		}
+	}
+	if manifestPath == "" {
+		return &BundleVerificationError{
+			File:   BundleManifestName,
+			Reason: "bundle is not signed, manifest is missing",
+		}
+	}
+	root := path.Dir(manifestPath)
+	signaturePath := path.Join(root, BundleSignatureName)
+
+	signature, ok := byPath[signaturePath]
+	if !ok {
+		return &BundleVerificationError{
+			File:   signaturePath,
+			Reason: "bundle is not signed, signature is missing",
+		}
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature))); err == nil {
+		signature = decoded
+	}
+	if !k.verifySignature(byPath[manifestPath], signature) {
+		return &BundleVerificationError{
+			File:   manifestPath,
+			Reason: "signature does not match the verify key",
+		}
+	}
+
+	manifest := &BundleManifest{}
+	if err := json.Unmarshal(byPath[manifestPath], manifest); err != nil {
+		return &BundleVerificationError{
+			File:   manifestPath,
+			Reason: fmt.Sprintf("invalid manifest: %s", err),
+		}
+	}
+	expected := map[string]string{}
+	for _, f := range manifest.Files {
+		if !opaExts[path.Ext(f.Path)] {
+			continue
+		}
+		expected[path.Join(root, cleanBundlePath(f.Path))] = strings.ToLower(f.SHA256)
+	}
+	for _, f := range files {
+		p := cleanBundlePath(f.path)
+		if !opaExts[path.Ext(p)] {
+			continue
+		}
+		digest, ok := expected[p]
+		if !ok {
+			return &BundleVerificationError{
+				File:   p,
+				Reason: "file is not in the manifest",
+			}
+		}
+		sum := sha256.Sum256(f.contents)
+		if actual := hex.EncodeToString(sum[:]); actual != digest {
+			return &BundleVerificationError{
+				File:   p,
+				Reason: fmt.Sprintf("expected sha256 %s but got %s", digest, actual),
+			}
+		}
+		delete(expected, p)
+	}
+	missing := []string{}
+	for p := range expected {
+		missing = append(missing, p)
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return &BundleVerificationError{
+			File:   missing[0],
+			Reason: "file in the manifest is missing from the bundle",
+		}
+	}
+	return nil
+}
+
+// SignBundleManifest signs a manifest with a PEM encoded ed25519, ECDSA or
+// RSA private key in PKCS #8 format, and returns the base64 encoded
+// signature.
+func SignBundleManifest(manifest []byte, privateKeyPath string) ([]byte, error) {
+	contents, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, fmt.Errorf("Invalid key %s: no PEM encoded private key found", privateKeyPath)
+	}
+	if block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("Invalid key %s: unsupported %s, use an unencrypted PKCS #8 key or sign the manifest with cosign", privateKeyPath, block.Type)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid key %s: %w", privateKeyPath, err)
+	}
+	digest := sha256.Sum256(manifest)
+	var signature []byte
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, manifest)
+	case *ecdsa.PrivateKey:
+		signature, err = ecdsa.SignASN1(rand.Reader, key, digest[:])
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	default:
+		return nil, fmt.Errorf("Invalid key %s: unsupported key type %T", privateKeyPath, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(signature) + "\n"
+	return []byte(encoded), nil
+}
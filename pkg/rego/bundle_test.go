@@ -0,0 +1,231 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rego_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fugue/regula/v3/pkg/rego"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeKeyPair writes a PEM encoded private and public key and returns their
+// paths.
+func writeKeyPair(t *testing.T, private crypto.Signer) (string, string) {
+	dir := t.TempDir()
+	privateBytes, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.NoError(t, err)
+	publicBytes, err := x509.MarshalPKIXPublicKey(private.Public())
+	assert.NoError(t, err)
+	privatePath := filepath.Join(dir, "private.pem")
+	publicPath := filepath.Join(dir, "public.pem")
+	assert.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: privateBytes,
+	}), 0600))
+	assert.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: publicBytes,
+	}), 0644))
+	return privatePath, publicPath
+}
+
+// signedFiles adds a manifest of the files, signed with the private key, in
+// the given directory of the bundle.
+func signedFiles(t *testing.T, privatePath string, dir string, files map[string]string) map[string]string {
+	contents := map[string][]byte{}
+	for p, c := range files {
+		contents[p] = []byte(c)
+	}
+	manifest, err := rego.NewBundleManifest(contents).Marshal()
+	assert.NoError(t, err)
+	signature, err := rego.SignBundleManifest(manifest, privatePath)
+	assert.NoError(t, err)
+	signed := map[string]string{
+		filepath.Join(dir, rego.BundleManifestName):  string(manifest),
+		filepath.Join(dir, rego.BundleSignatureName): string(signature),
+	}
+	for p, c := range files {
+		signed[filepath.Join(dir, p)] = c
+	}
+	return signed
+}
+
+func TestTarGzProviderVerification(t *testing.T) {
+	_, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	ecPrivate, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	edPrivatePath, edPublicPath := writeKeyPair(t, edPrivate)
+	ecPrivatePath, ecPublicPath := writeKeyPair(t, ecPrivate)
+	edKey, err := rego.LoadBundleKey(edPublicPath)
+	assert.NoError(t, err)
+	ecKey, err := rego.LoadBundleKey(ecPublicPath)
+	assert.NoError(t, err)
+
+	rules := map[string]string{
+		"a.rego":     "package rules.a",
+		"lib/b.rego": "package rules.b",
+	}
+	testCases := []struct {
+		name   string
+		key    *rego.BundleKey
+		files  map[string]string
+		paths  []string
+		file   string
+		reason string
+	}{
+		{
+			name:  "ed25519",
+			key:   edKey,
+			files: signedFiles(t, edPrivatePath, "", rules),
+			paths: []string{"a.rego", "lib/b.rego"},
+		},
+		{
+			name:  "ecdsa in a subdirectory",
+			key:   ecKey,
+			files: signedFiles(t, ecPrivatePath, "./rules", rules),
+			paths: []string{"rules/a.rego", "rules/lib/b.rego"},
+		},
+		{
+			name: "non-rego files are not verified",
+			key:  edKey,
+			files: func() map[string]string {
+				files := signedFiles(t, edPrivatePath, "", rules)
+				files["README.md"] = "# Rules"
+				return files
+			}(),
+			paths: []string{"a.rego", "lib/b.rego"},
+		},
+		{
+			name:  "no verify key",
+			files: rules,
+			paths: []string{"a.rego", "lib/b.rego"},
+		},
+		{
+			name:   "unsigned",
+			key:    edKey,
+			files:  rules,
+			file:   rego.BundleManifestName,
+			reason: "bundle is not signed, manifest is missing",
+		},
+		{
+			name: "missing signature",
+			key:  edKey,
+			files: func() map[string]string {
+				files := signedFiles(t, edPrivatePath, "", rules)
+				delete(files, rego.BundleSignatureName)
+				return files
+			}(),
+			file:   rego.BundleSignatureName,
+			reason: "bundle is not signed, signature is missing",
+		},
+		{
+			name:   "wrong key",
+			key:    ecKey,
+			files:  signedFiles(t, edPrivatePath, "", rules),
+			file:   rego.BundleManifestName,
+			reason: "signature does not match the verify key",
+		},
+		{
+			name: "tampered file",
+			key:  edKey,
+			files: func() map[string]string {
+				files := signedFiles(t, edPrivatePath, "", rules)
+				files["lib/b.rego"] = "package rules.evil"
+				return files
+			}(),
+			file: "lib/b.rego",
+		},
+		{
+			name: "added file",
+			key:  edKey,
+			files: func() map[string]string {
+				files := signedFiles(t, edPrivatePath, "", rules)
+				files["c.rego"] = "package rules.c"
+				return files
+			}(),
+			file:   "c.rego",
+			reason: "file is not in the manifest",
+		},
+		{
+			name: "file outside of the manifest directory",
+			key:  edKey,
+			files: func() map[string]string {
+				files := signedFiles(t, edPrivatePath, "rules", rules)
+				files["other/c.rego"] = "package rules.c"
+				return files
+			}(),
+			file:   "other/c.rego",
+			reason: "file is not in the manifest",
+		},
+		{
+			name: "removed file",
+			key:  edKey,
+			files: func() map[string]string {
+				files := signedFiles(t, edPrivatePath, "", rules)
+				delete(files, "a.rego")
+				return files
+			}(),
+			file:   "a.rego",
+			reason: "file in the manifest is missing from the bundle",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bundle := makeBundle(t, tc.files)
+			paths, err := loadPaths(rego.TarGzProvider(bytes.NewReader(bundle), tc.key))
+			if tc.file == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.paths, paths)
+				return
+			}
+			verificationErr := &rego.BundleVerificationError{}
+			if assert.True(t, errors.As(err, &verificationErr), "expected verification error, got %v", err) {
+				assert.Equal(t, tc.file, verificationErr.File)
+				if tc.reason != "" {
+					assert.Equal(t, tc.reason, verificationErr.Reason)
+				}
+			}
+			assert.Empty(t, paths)
+		})
+	}
+}
+
+func TestLoadBundleKey(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.pem")
+	assert.NoError(t, os.WriteFile(invalid, []byte("not a key"), 0644))
+	_, err := rego.LoadBundleKey(invalid)
+	assert.ErrorContains(t, err, "no PEM encoded public key found")
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	privatePath, _ := writeKeyPair(t, private)
+	_, err = rego.LoadBundleKey(privatePath)
+	assert.Error(t, err)
+}
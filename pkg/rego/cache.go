@@ -0,0 +1,111 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rego
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fugue/regula/v3/pkg/version"
+	"github.com/sirupsen/logrus"
+)
+
+// ResultCache is an on-disk cache of the query result for individual inputs.
+// Entries are addressed by a hash of the input's fingerprint (see
+// loader.Fingerprint), the rule set, the query and the Regula version, so
+// stale entries are never used and there is no need for invalidation.
+type ResultCache struct {
+	dir          string
+	fingerprints []string
+}
+
+// NewResultCache creates a cache that stores results under dir.  The
+// fingerprints must be in the same order as the inputs passed to RunRules.
+// An empty fingerprint disables caching for that input.
+func NewResultCache(dir string, fingerprints []string) *ResultCache {
+	return &ResultCache{
+		dir:          filepath.Join(dir, "results"),
+		fingerprints: fingerprints,
+	}
+}
+
+// key returns the cache key for the input at the given index, or "" if that
+// input can't be cached.
+func (c *ResultCache) key(query string, ruleSet string, index int) string {
+	if index >= len(c.fingerprints) || c.fingerprints[index] == "" {
+		return ""
+	}
+	hasher := sha256.New()
+	fmt.Fprintf(hasher, "version %q\n", version.Version)
+	fmt.Fprintf(hasher, "query %q\n", query)
+	fmt.Fprintf(hasher, "rules %s\n", ruleSet)
+	fmt.Fprintf(hasher, "input %s\n", c.fingerprints[index])
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func (c *ResultCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *ResultCache) get(key string) (interface{}, bool) {
+	contents, err := os.ReadFile(c.path(key))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logrus.Warnf("Unable to read cached result %s: %s", c.path(key), err)
+		}
+		return nil, false
+	}
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader(contents))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		logrus.Warnf("Ignoring corrupt cached result %s: %s", c.path(key), err)
+		return nil, false
+	}
+	logrus.Debugf("Using cached result %s", c.path(key))
+	return value, true
+}
+
+func (c *ResultCache) put(key string, value interface{}) error {
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so concurrent runs never see a
+	// partially written entry.
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(contents); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
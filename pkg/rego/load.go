@@ -139,41 +139,68 @@ func LocalProvider(paths []string) RegoProvider {
 	}
 }
 
-func TarGzProvider(reader io.Reader) RegoProvider {
+// TarGzProvider loads the rego files from a .tar.gz rule bundle.  If
+// verifyKey is not nil, the bundle must contain a manifest signed with that
+// key, and the provider fails with a BundleVerificationError before loading
+// any files if the bundle does not match it.
+func TarGzProvider(reader io.Reader, verifyKey *BundleKey) RegoProvider {
 	return func(ctx context.Context, p RegoProcessor) error {
-		gzf, err := gzip.NewReader(reader)
+		files, err := readTarGz(reader)
 		if err != nil {
 			return err
 		}
-
-		tarReader := tar.NewReader(gzf)
-		for true {
-			header, err := tarReader.Next()
-			if err == io.EOF {
-				break
-			} else if err != nil {
+		if verifyKey != nil {
+			if err := verifyKey.verifyBundle(files); err != nil {
+				return err
+			}
+		}
+		for _, f := range files {
+			if ext := filepath.Ext(f.path); !opaExts[ext] {
+				continue
+			}
+			if err := p(&regoFile{
+				path:     f.path,
+				contents: f.contents,
+			}); err != nil {
 				return err
 			}
+		}
+		return nil
+	}
+}
 
-			path := header.Name
+// readTarGz reads all regular files in a .tar.gz archive.
+func readTarGz(reader io.Reader) ([]bundleFile, error) {
+	gzf, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, err
+	}
 
-			switch header.Typeflag {
-			case tar.TypeReg:
-				if ext := filepath.Ext(path); !opaExts[ext] {
-					continue
-				}
-				buffer := bytes.NewBuffer([]byte{})
-				if _, err := io.Copy(buffer, tarReader); err != nil {
-					return fmt.Errorf("Error reading file %s in tar: %s", path, err)
-				}
-				p(&regoFile{
-					path:     path,
-					contents: buffer.Bytes(),
-				})
+	files := []bundleFile{}
+	tarReader := tar.NewReader(gzf)
+	for true {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		path := header.Name
+
+		switch header.Typeflag {
+		case tar.TypeReg:
+			buffer := bytes.NewBuffer([]byte{})
+			if _, err := io.Copy(buffer, tarReader); err != nil {
+				return nil, fmt.Errorf("Error reading file %s in tar: %s", path, err)
 			}
+			files = append(files, bundleFile{
+				path:     path,
+				contents: buffer.Bytes(),
+			})
 		}
-		return nil
 	}
+	return files, nil
 }
 
 func RegulaLibProvider() RegoProvider {
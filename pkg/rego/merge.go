@@ -0,0 +1,129 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains Go ports of the functions in regula.rego that combine
+// the results for several inputs, so inputs can be evaluated separately.
+
+package rego
+
+import (
+	"fmt"
+	"sort"
+)
+
+type mergeFunc func(values []interface{}) (interface{}, error)
+
+var mergeFuncs = map[string]mergeFunc{
+	REPORT_QUERY:    mergeReports,
+	SCAN_VIEW_QUERY: mergeScanViews,
+}
+
+// These mirror `report_summary` in regula.rego.
+var reportSeverities = []string{"Critical", "High", "Medium", "Low", "Informational", "Unknown"}
+var reportResultStrings = []string{"PASS", "FAIL", "WAIVED"}
+
+// mergeReports is the equivalent of `merge_reports` in regula.rego.
+func mergeReports(values []interface{}) (interface{}, error) {
+	ruleResults := []interface{}{}
+	for _, v := range values {
+		report, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Expected report to be an object but got %T", v)
+		}
+		rrs, ok := report["rule_results"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Expected rule_results to be an array but got %T", report["rule_results"])
+		}
+		ruleResults = append(ruleResults, rrs...)
+	}
+	return map[string]interface{}{
+		"rule_results": ruleResults,
+		"summary":      reportSummary(ruleResults),
+	}, nil
+}
+
+// mergeScanViews concatenates the inputs of several scan views and merges
+// their reports.
+func mergeScanViews(values []interface{}) (interface{}, error) {
+	inputs := []interface{}{}
+	reports := []interface{}{}
+	var scanViewVersion interface{}
+	for _, v := range values {
+		scanView, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Expected scan view to be an object but got %T", v)
+		}
+		if is, ok := scanView["inputs"].([]interface{}); ok {
+			inputs = append(inputs, is...)
+		}
+		reports = append(reports, scanView["report"])
+		scanViewVersion = scanView["scan_view_version"]
+	}
+	report, err := mergeReports(reports)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"scan_view_version": scanViewVersion,
+		"report":            report,
+		"inputs":            inputs,
+	}, nil
+}
+
+// reportSummary is the equivalent of `report_summary` in regula.rego.
+func reportSummary(ruleResults []interface{}) map[string]interface{} {
+	filepathSet := map[string]struct{}{}
+	resultCounts := map[string]interface{}{}
+	severityCounts := map[string]interface{}{}
+	results := map[string]int{}
+	severities := map[string]int{}
+	for _, v := range ruleResults {
+		rr, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if fp, ok := rr["filepath"].(string); ok {
+			filepathSet[fp] = struct{}{}
+		}
+		result, _ := rr["rule_result"].(string)
+		results[result] += 1
+		if result == "FAIL" {
+			severity, _ := rr["rule_severity"].(string)
+			severities[severity] += 1
+		}
+	}
+	for _, r := range reportResultStrings {
+		resultCounts[r] = results[r]
+	}
+	for _, s := range reportSeverities {
+		severityCounts[s] = severities[s]
+	}
+
+	// Rego sets are sorted, so the filepaths in the original summary are too.
+	filepaths := []string{}
+	for fp := range filepathSet {
+		filepaths = append(filepaths, fp)
+	}
+	sort.Strings(filepaths)
+	filepathValues := []interface{}{}
+	for _, fp := range filepaths {
+		filepathValues = append(filepathValues, fp)
+	}
+
+	return map[string]interface{}{
+		"filepaths":    filepathValues,
+		"rule_results": resultCounts,
+		"severities":   severityCounts,
+	}
+}
@@ -16,6 +16,7 @@ package rego_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -113,6 +114,86 @@ func TestRegulaExamples(t *testing.T) {
 	)
 }
 
+func TestRunRulesParallelMatchesSingleEvaluation(t *testing.T) {
+	rego.RegisterBuiltins()
+	ctx := context.Background()
+	configs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths: []string{
+			"tests/rules/cfn/s3/inputs/invalid_block_public_access_infra.yaml",
+			"tests/rules/tf/aws/s3/inputs/bucket_sse_infra.tf",
+			"tests/rules/tf/aws/s3/inputs/bucket_sse_infra.json",
+		},
+		InputTypes: []loader.InputType{loader.Auto},
+	})()
+	assert.Nil(t, err)
+	providers := []rego.RegoProvider{
+		rego.RegulaLibProvider(),
+		rego.RegulaRulesProvider(),
+	}
+
+	// Binding the report to a variable evaluates the whole input array at
+	// once, like regula did before inputs were evaluated in parallel.
+	single, err := rego.RunRules(ctx, &rego.RunRulesOptions{
+		Providers: providers,
+		Input:     configs.RegulaInput(),
+		Query:     "report = " + rego.REPORT_QUERY,
+	})
+	assert.Nil(t, err)
+	parallel, err := rego.RunRules(ctx, &rego.RunRulesOptions{
+		Providers: providers,
+		Input:     configs.RegulaInput(),
+		Query:     rego.REPORT_QUERY,
+		Jobs:      4,
+	})
+	assert.Nil(t, err)
+
+	expected, err := json.Marshal(single.Bindings["report"])
+	assert.Nil(t, err)
+	actual, err := json.Marshal(parallel.Expressions[0].Value)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(expected), string(actual))
+}
+
+func TestRunRulesCache(t *testing.T) {
+	rego.RegisterBuiltins()
+	ctx := context.Background()
+	configs, err := loader.LocalConfigurationLoader(loader.LoadPathsOptions{
+		Paths: []string{
+			"tests/rules/cfn/s3/inputs/invalid_block_public_access_infra.yaml",
+			"tests/rules/tf/aws/s3/inputs/bucket_sse_infra.tf",
+		},
+		InputTypes: []loader.InputType{loader.Auto},
+	})()
+	assert.Nil(t, err)
+	cacheDir := t.TempDir()
+	run := func() map[string]interface{} {
+		result, err := rego.RunRules(ctx, &rego.RunRulesOptions{
+			Providers: []rego.RegoProvider{
+				rego.RegulaLibProvider(),
+				rego.RegulaRulesProvider(),
+			},
+			Input: configs.RegulaInput(),
+			Query: rego.REPORT_QUERY,
+			Cache: rego.NewResultCache(cacheDir, loader.Fingerprints(configs, nil)),
+		})
+		assert.Nil(t, err)
+		return result.Expressions[0].Value.(map[string]interface{})
+	}
+
+	first := run()
+	assert.NotEmpty(t, first["rule_results"])
+	entries, err := filepath.Glob(filepath.Join(cacheDir, "results", "*.json"))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+
+	// Replace the cached results so we can tell that they are used.
+	for _, e := range entries {
+		assert.Nil(t, os.WriteFile(e, []byte(`{"rule_results": [], "summary": {}}`), 0644))
+	}
+	second := run()
+	assert.Empty(t, second["rule_results"])
+}
+
 // Trick to set the working directory to the rego directory
 func init() {
 	regoDir, err := filepath.Abs("../../rego")
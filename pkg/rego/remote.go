@@ -0,0 +1,399 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rego
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RemoteOptions configures how remote rule bundles are fetched.
+type RemoteOptions struct {
+	// CacheDir is the directory that fetched bundles are cached in.
+	CacheDir string
+	// Offline disables all network access.  Remote bundles are only loaded
+	// from the cache, and it is an error if they are not cached.
+	Offline bool
+	// VerifyKey is the key that bundles must be signed with, if any.  See
+	// TarGzProvider.
+	VerifyKey *BundleKey
+	// Client is the HTTP client used for HTTPS and OCI sources.  Defaults
+	// to http.DefaultClient.
+	Client *http.Client
+}
+
+func (o RemoteOptions) client() *http.Client {
+	if o.Client == nil {
+		return http.DefaultClient
+	}
+	return o.Client
+}
+
+// IsRemoteSource returns true if the given include refers to a remote rule
+// bundle rather than to a local file or directory.  Supported sources are:
+//
+//	oci://registry/repository:tag
+//	oci://registry/repository@sha256:...
+//	https://example.com/bundle.tar.gz?checksum=sha256:...
+//	git::https://example.com/repository.git//subdirectory?ref=v1.0.0
+//
+// Plain http:// sources are recognized so that they can be refused with a
+// clear error, see parseHTTPSource.
+func IsRemoteSource(path string) bool {
+	return strings.HasPrefix(path, "oci://") ||
+		strings.HasPrefix(path, "https://") ||
+		strings.HasPrefix(path, "http://") ||
+		strings.HasPrefix(path, "git::")
+}
+
+// IncludesProvider loads the rego files from the given includes, which are
+// either local paths (see LocalProvider) or remote sources (see
+// RemoteProvider).
+func IncludesProvider(includes []string, opts RemoteOptions) RegoProvider {
+	return func(ctx context.Context, p RegoProcessor) error {
+		for _, include := range includes {
+			var provider RegoProvider
+			if IsRemoteSource(include) {
+				provider = RemoteProvider(include, opts)
+			} else {
+				provider = LocalProvider([]string{include})
+			}
+			if err := provider(ctx, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// RemoteProvider loads the rego files from a remote rule bundle, which is a
+// .tar.gz archive or a git repository.  Fetched bundles are stored in the
+// cache together with their SHA-256 digest, which is checked every time the
+// bundle is used.  Bundles are only downloaded again when the source has
+// changed, and never when the source is pinned to a checksum, digest or
+// commit that matches the cache.
+func RemoteProvider(source string, opts RemoteOptions) RegoProvider {
+	return func(ctx context.Context, p RegoProcessor) error {
+		src, err := parseRemoteSource(source)
+		if err != nil {
+			return err
+		}
+		bundle, err := fetchRemoteBundle(ctx, src, opts)
+		if err != nil {
+			return fmt.Errorf("Unable to load rules from %s: %w", source, err)
+		}
+		if err := TarGzProvider(bytes.NewReader(bundle), opts.VerifyKey)(ctx, p); err != nil {
+			return fmt.Errorf("Unable to load rules from %s: %w", source, err)
+		}
+		return nil
+	}
+}
+
+type remoteKind int
+
+const (
+	httpSource remoteKind = iota
+	ociSource
+	gitSource
+)
+
+type remoteSource struct {
+	// raw is the source as specified by the user.
+	raw  string
+	kind remoteKind
+	// url is the location to fetch, without any of the options below.
+	url string
+	// checksum is the expected digest of the bundle of an HTTPS source, or
+	// of the manifest of an OCI source, e.g. "sha256:...".
+	checksum string
+	// ref is the tag of an OCI source, or the branch, tag or commit of a git
+	// source.
+	ref string
+	// subdir is the directory in a git source to load rules from.
+	subdir string
+}
+
+// pinned returns true if the source can't change without changing the
+// source itself.
+func (s *remoteSource) pinned() bool {
+	switch s.kind {
+	case gitSource:
+		return isCommitHash(s.ref)
+	default:
+		return s.checksum != ""
+	}
+}
+
+func parseRemoteSource(source string) (*remoteSource, error) {
+	switch {
+	case strings.HasPrefix(source, "oci://"):
+		return parseOCISource(source)
+	case strings.HasPrefix(source, "git::"):
+		return parseGitSource(source)
+	default:
+		return parseHTTPSource(source)
+	}
+}
+
+func parseHTTPSource(source string) (*remoteSource, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid remote include %s: %w", source, err)
+	}
+	if u.Scheme != "https" && !(u.Scheme == "http" && isLoopback(u.Hostname())) {
+		// Like for OCI sources, plain HTTP is only allowed for local servers.
+		return nil, fmt.Errorf("Invalid remote include %s, rule bundles must be fetched over https://", source)
+	}
+	query := u.Query()
+	checksum := strings.ToLower(query.Get("checksum"))
+	if checksum != "" {
+		if err := validateDigest(checksum); err != nil {
+			return nil, fmt.Errorf("Invalid checksum in remote include %s: %w", source, err)
+		}
+		query.Del("checksum")
+		u.RawQuery = query.Encode()
+	}
+	return &remoteSource{
+		raw:      source,
+		kind:     httpSource,
+		url:      u.String(),
+		checksum: checksum,
+	}, nil
+}
+
+// remoteMetadata describes a cached bundle.
+type remoteMetadata struct {
+	Source string `json:"source"`
+	// Digest is the digest of the cached bundle.
+	Digest string `json:"digest"`
+	// Revision identifies the version of the source that the bundle was
+	// fetched from: the ETag of an HTTPS source, the manifest digest of an
+	// OCI source or the commit of a git source.
+	Revision string `json:"revision,omitempty"`
+}
+
+// remoteCache stores the bundle of a single remote source.
+type remoteCache struct {
+	dir string
+}
+
+func newRemoteCache(cacheDir string, src *remoteSource) *remoteCache {
+	hasher := sha256.New()
+	hasher.Write([]byte(src.raw))
+	return &remoteCache{
+		dir: filepath.Join(cacheDir, "includes", hex.EncodeToString(hasher.Sum(nil))),
+	}
+}
+
+func (c *remoteCache) bundlePath() string {
+	return filepath.Join(c.dir, "bundle.tar.gz")
+}
+
+func (c *remoteCache) metadataPath() string {
+	return filepath.Join(c.dir, "metadata.json")
+}
+
+// load returns the cached bundle and its metadata.  It returns an error if
+// the cached bundle does not match its digest.
+func (c *remoteCache) load() ([]byte, *remoteMetadata, error) {
+	contents, err := os.ReadFile(c.metadataPath())
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata := &remoteMetadata{}
+	if err := json.Unmarshal(contents, metadata); err != nil {
+		return nil, nil, fmt.Errorf("Corrupt cache metadata %s: %w", c.metadataPath(), err)
+	}
+	bundle, err := os.ReadFile(c.bundlePath())
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := verifyDigest(bundle, metadata.Digest); err != nil {
+		return nil, nil, fmt.Errorf("Cached bundle %s: %w", c.bundlePath(), err)
+	}
+	return bundle, metadata, nil
+}
+
+func (c *remoteCache) store(bundle []byte, metadata *remoteMetadata) error {
+	metadata.Digest = digestOf(bundle)
+	contents, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(c.bundlePath(), bundle); err != nil {
+		return err
+	}
+	return writeFileAtomic(c.metadataPath(), contents)
+}
+
+func writeFileAtomic(path string, contents []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(contents); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// remoteFetcher fetches the bundle of a remote source.  cached is the
+// metadata of the cached bundle, if any.  Fetchers return nil bundle if
+// the cached bundle is still up to date.
+type remoteFetcher func(ctx context.Context, src *remoteSource, opts RemoteOptions, cached *remoteMetadata) ([]byte, *remoteMetadata, error)
+
+func fetchRemoteBundle(ctx context.Context, src *remoteSource, opts RemoteOptions) ([]byte, error) {
+	cache := newRemoteCache(opts.CacheDir, src)
+	cachedBundle, cached, err := cache.load()
+	if err != nil {
+		if opts.Offline {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("Bundle is not cached, run without --offline to fetch it")
+			}
+			return nil, err
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			logrus.Warnf("Ignoring cached bundle for %s: %s", src.raw, err)
+		}
+		cached = nil
+	}
+	if cached != nil && (opts.Offline || src.pinned() && upToDate(src, cached)) {
+		logrus.Infof("Using cached bundle for %s", src.raw)
+		return cachedBundle, nil
+	}
+
+	var fetch remoteFetcher
+	switch src.kind {
+	case ociSource:
+		fetch = fetchOCIBundle
+	case gitSource:
+		fetch = fetchGitBundle
+	default:
+		fetch = fetchHTTPBundle
+	}
+	logrus.Infof("Fetching %s", src.raw)
+	bundle, metadata, err := fetch(ctx, src, opts, cached)
+	if err != nil {
+		return nil, err
+	}
+	if bundle == nil {
+		logrus.Infof("Bundle for %s not modified, using cache", src.raw)
+		return cachedBundle, nil
+	}
+	metadata.Source = src.raw
+	if err := cache.store(bundle, metadata); err != nil {
+		return nil, fmt.Errorf("Unable to cache bundle: %w", err)
+	}
+	return bundle, nil
+}
+
+// upToDate checks if a cached bundle matches a pinned source.
+func upToDate(src *remoteSource, cached *remoteMetadata) bool {
+	switch src.kind {
+	case httpSource:
+		return cached.Digest == src.checksum
+	case ociSource:
+		return cached.Revision == src.checksum
+	default:
+		return cached.Revision == src.ref
+	}
+}
+
+func fetchHTTPBundle(ctx context.Context, src *remoteSource, opts RemoteOptions, cached *remoteMetadata) ([]byte, *remoteMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cached != nil && cached.Revision != "" {
+		req.Header.Set("If-None-Match", cached.Revision)
+	}
+	resp, err := opts.client().Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		if src.checksum != "" && cached.Digest != src.checksum {
+			return nil, nil, checksumError(src.checksum, cached.Digest)
+		}
+		return nil, nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("GET %s: %s", src.url, resp.Status)
+	}
+	bundle, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if src.checksum != "" {
+		if err := verifyDigest(bundle, src.checksum); err != nil {
+			return nil, nil, err
+		}
+	}
+	return bundle, &remoteMetadata{Revision: resp.Header.Get("ETag")}, nil
+}
+
+func digestOf(contents []byte) string {
+	hasher := sha256.New()
+	hasher.Write(contents)
+	return "sha256:" + hex.EncodeToString(hasher.Sum(nil))
+}
+
+// validateDigest checks that a digest is of the form "sha256:<hex>".
+func validateDigest(digest string) error {
+	algorithm, encoded, ok := strings.Cut(digest, ":")
+	if !ok || algorithm != "sha256" {
+		return fmt.Errorf("unsupported digest %s, expected sha256:<hex>", digest)
+	}
+	if decoded, err := hex.DecodeString(encoded); err != nil || len(decoded) != sha256.Size {
+		return fmt.Errorf("malformed digest %s", digest)
+	}
+	return nil
+}
+
+func verifyDigest(contents []byte, expected string) error {
+	if err := validateDigest(expected); err != nil {
+		return err
+	}
+	if actual := digestOf(contents); actual != strings.ToLower(expected) {
+		return checksumError(expected, actual)
+	}
+	return nil
+}
+
+func checksumError(expected string, actual string) error {
+	return fmt.Errorf("checksum mismatch, expected %s but got %s", expected, actual)
+}
@@ -0,0 +1,221 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rego
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// parseGitSource parses git::<url>//<subdir>?ref=<ref> sources, using the
+// same syntax as Terraform module sources.  The subdirectory and ref are
+// optional.
+func parseGitSource(source string) (*remoteSource, error) {
+	location := strings.TrimPrefix(source, "git::")
+	src := &remoteSource{
+		raw:  source,
+		kind: gitSource,
+	}
+	if base, query, ok := strings.Cut(location, "?"); ok {
+		for _, param := range strings.Split(query, "&") {
+			key, value, _ := strings.Cut(param, "=")
+			if key != "ref" {
+				return nil, fmt.Errorf("Invalid remote include %s, unsupported option %s", source, key)
+			}
+			src.ref = value
+		}
+		location = base
+	}
+	schemeEnd := 0
+	if i := strings.Index(location, "://"); i >= 0 {
+		schemeEnd = i + len("://")
+	}
+	if i := strings.Index(location[schemeEnd:], "//"); i >= 0 {
+		src.subdir = strings.Trim(location[schemeEnd+i+2:], "/")
+		location = location[:schemeEnd+i]
+	}
+	if location == "" {
+		return nil, fmt.Errorf("Invalid remote include %s, expected git::<url>", source)
+	}
+	if u, err := url.Parse(location); err == nil && (u.Scheme == "http" || u.Scheme == "git") {
+		// Branches and tags are not integrity-checked, so like for HTTPS
+		// sources, unencrypted transports are only allowed for local servers.
+		if !isLoopback(u.Hostname()) {
+			return nil, fmt.Errorf("Invalid remote include %s, git repositories must be fetched over https:// or ssh", source)
+		}
+	}
+	src.url = location
+	return src, nil
+}
+
+func isCommitHash(ref string) bool {
+	if len(ref) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(ref)
+	return err == nil
+}
+
+func fetchGitBundle(ctx context.Context, src *remoteSource, _ RemoteOptions, cached *remoteMetadata) ([]byte, *remoteMetadata, error) {
+	storage := memory.NewStorage()
+	cloneOptions := &gogit.CloneOptions{
+		URL:  src.url,
+		Tags: gogit.NoTags,
+	}
+	revision := src.ref
+	if !isCommitHash(src.ref) {
+		// Resolve the ref first so that we don't need to clone when the
+		// cached bundle is up to date.
+		ref, err := resolveGitRef(ctx, src)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cached != nil && cached.Revision == ref.Hash().String() {
+			return nil, nil, nil
+		}
+		cloneOptions.ReferenceName = ref.Name()
+		cloneOptions.SingleBranch = true
+		cloneOptions.Depth = 1
+		revision = ref.Name().String()
+	}
+	repo, err := gogit.CloneContext(ctx, storage, nil, cloneOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to resolve %s: %w", revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	bundle, err := gitTreeBundle(commit, src.subdir)
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata := &remoteMetadata{Revision: commit.Hash.String()}
+	if ref, err := repo.Reference(plumbing.ReferenceName(revision), false); err == nil {
+		// Record the hash that resolveGitRef returns for annotated tags.
+		metadata.Revision = ref.Hash().String()
+	}
+	return bundle, metadata, nil
+}
+
+// resolveGitRef finds the branch or tag that the source refers to, or the
+// default branch if there's no ref.
+func resolveGitRef(ctx context.Context, src *remoteSource) (*plumbing.Reference, error) {
+	remote := gogit.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{src.url},
+	})
+	refs, err := remote.ListContext(ctx, &gogit.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	candidates := []plumbing.ReferenceName{plumbing.HEAD}
+	if src.ref != "" {
+		candidates = []plumbing.ReferenceName{
+			plumbing.ReferenceName(src.ref),
+			plumbing.NewBranchReferenceName(src.ref),
+			plumbing.NewTagReferenceName(src.ref),
+		}
+	}
+	byName := map[plumbing.ReferenceName]*plumbing.Reference{}
+	for _, ref := range refs {
+		byName[ref.Name()] = ref
+	}
+	for _, name := range candidates {
+		ref, ok := byName[name]
+		if !ok {
+			continue
+		}
+		if ref.Type() == plumbing.SymbolicReference {
+			if target, ok := byName[ref.Target()]; ok {
+				return target, nil
+			}
+			continue
+		}
+		return ref, nil
+	}
+	if src.ref == "" {
+		return nil, fmt.Errorf("Unable to find the default branch of %s", src.url)
+	}
+	return nil, fmt.Errorf("Unable to find ref %s in %s", src.ref, src.url)
+}
+
+// gitTreeBundle packs the rego files of a commit into a .tar.gz bundle,
+// together with the bundle manifest and signature if there are any.
+func gitTreeBundle(commit *object.Commit, subdir string) ([]byte, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	if subdir != "" {
+		tree, err = tree.Tree(subdir)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to find %s in commit %s: %w", subdir, commit.Hash, err)
+		}
+	}
+	buffer := &bytes.Buffer{}
+	gzw := gzip.NewWriter(buffer)
+	tw := tar.NewWriter(gzw)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if base := path.Base(f.Name); !opaExts[filepath.Ext(f.Name)] &&
+			base != BundleManifestName && base != BundleSignatureName {
+			return nil
+		}
+		contents, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     path.Join(subdir, f.Name),
+			Mode:     0644,
+			Size:     int64(len(contents)),
+			ModTime:  commit.Committer.When,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err = tw.Write([]byte(contents))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzw.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,317 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rego
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Media types of OCI manifests that we accept.
+var ociManifestTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// parseOCISource parses oci://registry/repository:tag and
+// oci://registry/repository@sha256:... references.  The tag defaults to
+// "latest".
+func parseOCISource(source string) (*remoteSource, error) {
+	reference := strings.TrimPrefix(source, "oci://")
+	registry, repository, ok := strings.Cut(reference, "/")
+	if !ok || registry == "" || repository == "" {
+		return nil, fmt.Errorf("Invalid remote include %s, expected oci://registry/repository:tag", source)
+	}
+	src := &remoteSource{
+		raw:  source,
+		kind: ociSource,
+		ref:  "latest",
+	}
+	if name, digest, ok := strings.Cut(repository, "@"); ok {
+		digest = strings.ToLower(digest)
+		if err := validateDigest(digest); err != nil {
+			return nil, fmt.Errorf("Invalid digest in remote include %s: %w", source, err)
+		}
+		repository = name
+		src.ref = digest
+		src.checksum = digest
+	} else if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		src.ref = repository[i+1:]
+		repository = repository[:i]
+	}
+	scheme := "https"
+	if host, _, err := net.SplitHostPort(registry); err == nil && isLoopback(host) || isLoopback(registry) {
+		// Like docker, allow plain HTTP for local registries.
+		scheme = "http"
+	}
+	src.url = fmt.Sprintf("%s://%s/v2/%s", scheme, registry, repository)
+	return src, nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// bundleLayer returns the first layer that is a gzipped tarball, which is
+// where `oras push` and `opa build` put bundles.
+func (m *ociManifest) bundleLayer() (*ociDescriptor, error) {
+	for i, layer := range m.Layers {
+		if strings.HasSuffix(layer.MediaType, "tar+gzip") ||
+			strings.HasSuffix(layer.MediaType, "tar.gzip") {
+			return &m.Layers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("manifest does not contain a .tar.gz layer")
+}
+
+func fetchOCIBundle(ctx context.Context, src *remoteSource, opts RemoteOptions, cached *remoteMetadata) ([]byte, *remoteMetadata, error) {
+	client := &ociClient{client: opts.client()}
+	contents, err := client.get(ctx, src.url+"/manifests/"+src.ref, ociManifestTypes)
+	if err != nil {
+		return nil, nil, err
+	}
+	manifestDigest := digestOf(contents)
+	if src.checksum != "" && manifestDigest != src.checksum {
+		return nil, nil, checksumError(src.checksum, manifestDigest)
+	}
+	if cached != nil && cached.Revision == manifestDigest {
+		return nil, nil, nil
+	}
+	manifest := &ociManifest{}
+	if err := json.Unmarshal(contents, manifest); err != nil {
+		return nil, nil, fmt.Errorf("Invalid manifest: %w", err)
+	}
+	layer, err := manifest.bundleLayer()
+	if err != nil {
+		return nil, nil, err
+	}
+	bundle, err := client.get(ctx, src.url+"/blobs/"+layer.Digest, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := verifyDigest(bundle, layer.Digest); err != nil {
+		return nil, nil, fmt.Errorf("Layer %s: %w", layer.Digest, err)
+	}
+	return bundle, &remoteMetadata{Revision: manifestDigest}, nil
+}
+
+// ociClient is a minimal client for the OCI distribution API.  It supports
+// anonymous access and the basic credentials that `docker login` stores in
+// the docker configuration file.
+type ociClient struct {
+	client *http.Client
+	// authorization is the Authorization header to use after the registry
+	// has asked for credentials.
+	authorization string
+}
+
+func (c *ociClient) get(ctx context.Context, location string, accept []string) ([]byte, error) {
+	resp, err := c.do(ctx, location, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && c.authorization == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := c.authorize(ctx, location, challenge); err != nil {
+			return nil, err
+		}
+		resp, err = c.do(ctx, location, accept)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", location, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (c *ociClient) do(ctx context.Context, location string, accept []string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+	return c.client.Do(req)
+}
+
+// authorize answers a Basic or Bearer challenge from the registry.
+func (c *ociClient) authorize(ctx context.Context, location string, challenge string) error {
+	u, err := url.Parse(location)
+	if err != nil {
+		return err
+	}
+	credentials := dockerCredentials(u.Host)
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if credentials == "" {
+			return fmt.Errorf("GET %s: registry requires credentials, use docker login", location)
+		}
+		c.authorization = "Basic " + credentials
+		return nil
+	case "bearer":
+		token, err := c.token(ctx, params, credentials)
+		if err != nil {
+			return err
+		}
+		c.authorization = "Bearer " + token
+		return nil
+	default:
+		return fmt.Errorf("GET %s: unsupported authentication challenge %q", location, challenge)
+	}
+}
+
+// token requests a bearer token from the registry's token service.
+func (c *ociClient) token(ctx context.Context, params map[string]string, credentials string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("Invalid token realm %q", params["realm"])
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if value, ok := params[key]; ok {
+			query.Set(key, value)
+		}
+	}
+	realm.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if credentials != "" {
+		req.Header.Set("Authorization", "Basic "+credentials)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: %s", realm.Redacted(), resp.Status)
+	}
+	body := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// parseChallenge parses a WWW-Authenticate header such as
+// `Bearer realm="https://auth.example.com/token",service="example.com"`.
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var param string
+		rest = strings.TrimLeft(rest, " ,")
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				break
+			}
+			param, rest = value[1:end+1], value[end+2:]
+		} else {
+			param, rest, _ = strings.Cut(value, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = param
+	}
+	return strings.ToLower(scheme), params
+}
+
+// dockerCredentials returns the base64-encoded basic credentials that are
+// stored for the registry in the docker configuration file, if any.
+// Credential helpers are not supported.
+func dockerCredentials(registry string) string {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	contents, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return ""
+	}
+	config := struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}{}
+	if err := json.Unmarshal(contents, &config); err != nil {
+		return ""
+	}
+	for key, auth := range config.Auths {
+		host := key
+		if u, err := url.Parse(key); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		if host != registry {
+			continue
+		}
+		if auth.Auth != "" {
+			return auth.Auth
+		}
+		if auth.Username != "" {
+			return base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+		}
+	}
+	return ""
+}
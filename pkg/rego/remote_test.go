@@ -0,0 +1,305 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rego_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fugue/regula/v3/pkg/rego"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeBundle(t *testing.T, files map[string]string) []byte {
+	buffer := &bytes.Buffer{}
+	gzw := gzip.NewWriter(buffer)
+	tw := tar.NewWriter(gzw)
+	for name, contents := range files {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(contents)),
+		}))
+		_, err := tw.Write([]byte(contents))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gzw.Close())
+	return buffer.Bytes()
+}
+
+func digest(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func loadPaths(provider rego.RegoProvider) ([]string, error) {
+	paths := []string{}
+	err := provider(context.Background(), func(r rego.RegoFile) error {
+		paths = append(paths, r.Path())
+		return nil
+	})
+	sort.Strings(paths)
+	return paths, err
+}
+
+func TestIsRemoteSource(t *testing.T) {
+	assert.True(t, rego.IsRemoteSource("oci://ghcr.io/example/rules:v1"))
+	assert.True(t, rego.IsRemoteSource("https://example.com/rules.tar.gz"))
+	assert.True(t, rego.IsRemoteSource("git::https://example.com/rules.git"))
+	assert.False(t, rego.IsRemoteSource("rules/custom.rego"))
+	assert.False(t, rego.IsRemoteSource("/tmp/rules"))
+}
+
+func TestInsecureRemoteSource(t *testing.T) {
+	opts := rego.RemoteOptions{CacheDir: t.TempDir(), Offline: true}
+	for _, source := range []string{
+		"http://example.com/rules.tar.gz",
+		"http://example.com/rules.tar.gz?checksum=" + digest([]byte("rules")),
+	} {
+		_, err := loadPaths(rego.RemoteProvider(source, opts))
+		assert.ErrorContains(t, err, "must be fetched over https://")
+	}
+	for _, source := range []string{
+		"git::http://example.com/rules.git?ref=main",
+		"git::http://example.com/rules.git//rules",
+		"git::git://example.com/rules.git",
+	} {
+		_, err := loadPaths(rego.RemoteProvider(source, opts))
+		assert.ErrorContains(t, err, "must be fetched over https:// or ssh")
+	}
+}
+
+func TestHTTPRemoteProvider(t *testing.T) {
+	bundle := makeBundle(t, map[string]string{
+		"rules/a.rego": "package rules.a",
+		"rules/a.json": "{}",
+		"rules/b.rego": "package rules.b",
+	})
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(bundle)
+	}))
+	defer server.Close()
+	opts := rego.RemoteOptions{CacheDir: t.TempDir()}
+	expected := []string{"rules/a.rego", "rules/b.rego"}
+
+	// Sources without a checksum are revalidated.
+	source := server.URL + "/bundle.tar.gz"
+	paths, err := loadPaths(rego.RemoteProvider(source, opts))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, paths)
+	paths, err = loadPaths(rego.RemoteProvider(source, opts))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, paths)
+	assert.Equal(t, 2, requests)
+
+	// Pinned sources are only fetched once.
+	pinned := source + "?checksum=" + digest(bundle)
+	for i := 0; i < 2; i++ {
+		paths, err = loadPaths(rego.RemoteProvider(pinned, opts))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, paths)
+	}
+	assert.Equal(t, 3, requests)
+
+	// A wrong checksum is an error.
+	wrong := source + "?checksum=" + digest([]byte("wrong"))
+	_, err = loadPaths(rego.RemoteProvider(wrong, opts))
+	assert.ErrorContains(t, err, "checksum mismatch")
+
+	// Offline mode only uses the cache.
+	offline := rego.RemoteOptions{CacheDir: opts.CacheDir, Offline: true}
+	paths, err = loadPaths(rego.RemoteProvider(pinned, offline))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, paths)
+	_, err = loadPaths(rego.RemoteProvider(source+"?other", offline))
+	assert.ErrorContains(t, err, "not cached")
+	assert.Equal(t, 4, requests)
+}
+
+func TestRemoteCacheTampering(t *testing.T) {
+	bundle := makeBundle(t, map[string]string{"a.rego": "package rules.a"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bundle)
+	}))
+	defer server.Close()
+	cacheDir := t.TempDir()
+	source := server.URL + "/bundle.tar.gz"
+	_, err := loadPaths(rego.RemoteProvider(source, rego.RemoteOptions{CacheDir: cacheDir}))
+	assert.NoError(t, err)
+
+	cached, err := filepath.Glob(filepath.Join(cacheDir, "includes", "*", "bundle.tar.gz"))
+	assert.NoError(t, err)
+	assert.Len(t, cached, 1)
+	tampered := makeBundle(t, map[string]string{"evil.rego": "package rules.evil"})
+	assert.NoError(t, os.WriteFile(cached[0], tampered, 0644))
+
+	offline := rego.RemoteOptions{CacheDir: cacheDir, Offline: true}
+	_, err = loadPaths(rego.RemoteProvider(source, offline))
+	assert.ErrorContains(t, err, "checksum mismatch")
+
+	// Online, the bundle is fetched again.
+	paths, err := loadPaths(rego.RemoteProvider(source, rego.RemoteOptions{CacheDir: cacheDir}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.rego"}, paths)
+}
+
+func TestOCIRemoteProvider(t *testing.T) {
+	bundle := makeBundle(t, map[string]string{"policy/a.rego": "package rules.a"})
+	manifest := []byte(fmt.Sprintf(`{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.oci.image.manifest.v1+json",
+  "config": {"mediaType": "application/vnd.oci.image.config.v1+json", "digest": "%s", "size": 2},
+  "layers": [{"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip", "digest": "%s", "size": %d}]
+}`, digest([]byte("{}")), digest(bundle), len(bundle)))
+	blobRequests := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			assert.Equal(t, "repository:org/rules:pull", r.URL.Query().Get("scope"))
+			w.Write([]byte(`{"token": "secret"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+				`Bearer realm="%s/token",service="registry",scope="repository:org/rules:pull"`,
+				server.URL,
+			))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/org/rules/manifests/v1", "/v2/org/rules/manifests/" + digest(manifest):
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			w.Write(manifest)
+		case "/v2/org/rules/blobs/" + digest(bundle):
+			blobRequests++
+			w.Write(bundle)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	opts := rego.RemoteOptions{CacheDir: t.TempDir()}
+	registry := strings.TrimPrefix(server.URL, "http://")
+
+	source := "oci://" + registry + "/org/rules:v1"
+	for i := 0; i < 2; i++ {
+		paths, err := loadPaths(rego.RemoteProvider(source, opts))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"policy/a.rego"}, paths)
+	}
+	assert.Equal(t, 1, blobRequests)
+
+	pinned := "oci://" + registry + "/org/rules@" + digest(manifest)
+	paths, err := loadPaths(rego.RemoteProvider(pinned, opts))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"policy/a.rego"}, paths)
+
+	wrong := "oci://" + registry + "/org/rules@" + digest(bundle)
+	_, err = loadPaths(rego.RemoteProvider(wrong, opts))
+	assert.Error(t, err)
+}
+
+func TestGitRemoteProvider(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	assert.NoError(t, err)
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	commit := func(files map[string]string) string {
+		for name, contents := range files {
+			path := filepath.Join(dir, name)
+			assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			assert.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+			_, err := worktree.Add(name)
+			assert.NoError(t, err)
+		}
+		hash, err := worktree.Commit("Update rules", &gogit.CommitOptions{
+			Author: &object.Signature{Name: "Regula", When: time.Now()},
+		})
+		assert.NoError(t, err)
+		return hash.String()
+	}
+	first := commit(map[string]string{
+		"README.md":        "# Rules",
+		"rules/a.rego":     "package rules.a",
+		"rules/lib/b.rego": "package rules.b",
+	})
+	commit(map[string]string{
+		"rules/c.rego": "package rules.c",
+	})
+	opts := rego.RemoteOptions{CacheDir: t.TempDir()}
+
+	paths, err := loadPaths(rego.RemoteProvider("git::file://"+dir+"//rules", opts))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rules/a.rego", "rules/c.rego", "rules/lib/b.rego"}, paths)
+
+	pinned := "git::file://" + dir + "?ref=" + first
+	paths, err = loadPaths(rego.RemoteProvider(pinned, opts))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rules/a.rego", "rules/lib/b.rego"}, paths)
+
+	_, err = loadPaths(rego.RemoteProvider("git::file://"+dir+"?ref=missing", opts))
+	assert.ErrorContains(t, err, "Unable to find ref missing")
+
+	// The pinned commit is served from the cache, even if the repository
+	// disappears.
+	assert.NoError(t, os.RemoveAll(dir))
+	paths, err = loadPaths(rego.RemoteProvider(pinned, opts))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rules/a.rego", "rules/lib/b.rego"}, paths)
+}
+
+func TestIncludesProvider(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, "local.rego")
+	assert.NoError(t, os.WriteFile(local, []byte("package rules.local"), 0644))
+	bundle := makeBundle(t, map[string]string{"remote.rego": "package rules.remote"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bundle)
+	}))
+	defer server.Close()
+
+	paths, err := loadPaths(rego.IncludesProvider(
+		[]string{local, server.URL + "/bundle.tar.gz"},
+		rego.RemoteOptions{CacheDir: t.TempDir()},
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{local, "remote.rego"}, paths)
+}
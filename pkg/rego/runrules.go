@@ -16,7 +16,11 @@ package rego
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"runtime"
+	"sync"
 
 	"github.com/fugue/regula/v3/pkg/loader"
 	"github.com/open-policy-agent/opa/ast"
@@ -31,6 +35,11 @@ type RunRulesOptions struct {
 	Providers []RegoProvider
 	Input     []loader.RegulaInput
 	Query     string
+	// Jobs is the maximum number of inputs that are evaluated concurrently.
+	// Defaults to the number of CPUs.
+	Jobs int
+	// Cache, if set, is used to look up and store the result for each input.
+	Cache *ResultCache
 }
 
 func RunRules(ctx context.Context, options *RunRulesOptions) (RegoResult, error) {
@@ -42,8 +51,10 @@ func RunRules(ctx context.Context, options *RunRulesOptions) (RegoResult, error)
 		rego.Query(query),
 		rego.Runtime(RegulaRuntimeConfig()),
 	}
+	ruleSet := sha256.New()
 	cb := func(r RegoFile) error {
 		regoFuncs = append(regoFuncs, rego.Module(r.Path(), r.String()))
+		fmt.Fprintf(ruleSet, "%q %x\n", r.Path(), sha256.Sum256(r.Raw()))
 		return nil
 	}
 	for _, p := range options.Providers {
@@ -65,9 +76,93 @@ func RunRules(ctx context.Context, options *RunRulesOptions) (RegoResult, error)
 	if err != nil {
 		return nil, err
 	}
-	results, err := regoQuery.Eval(ctx, rego.EvalInput(options.Input))
+
+	// We don't know how to merge the results of arbitrary queries, and with a
+	// single uncached input there is nothing to gain from splitting it up.
+	merge, canMerge := mergeFuncs[query]
+	cache := options.Cache
+	if !canMerge {
+		cache = nil
+	}
+	if !canMerge || (len(options.Input) < 2 && cache == nil) {
+		results, err := regoQuery.Eval(ctx, rego.EvalInput(options.Input))
+		if err != nil {
+			return nil, err
+		}
+		return &results[0], nil
+	}
+
+	values := make([]interface{}, len(options.Input))
+	keys := make([]string, len(options.Input))
+	pending := []int{}
+	ruleSetHash := hex.EncodeToString(ruleSet.Sum(nil))
+	for i := range options.Input {
+		if cache != nil {
+			keys[i] = cache.key(query, ruleSetHash, i)
+			if keys[i] != "" {
+				if value, ok := cache.get(keys[i]); ok {
+					values[i] = value
+					continue
+				}
+			}
+		}
+		pending = append(pending, i)
+	}
+	if cache != nil {
+		logrus.Infof("Using cached results for %d of %d inputs", len(options.Input)-len(pending), len(options.Input))
+	}
+
+	// Evaluate every remaining input on its own, using the same compiled
+	// query.  Each evaluation sees a one-element input array, so the result is
+	// identical to evaluating that part of the full array.
+	jobs := options.Jobs
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+	errs := make([]error, len(options.Input))
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				rs, err := regoQuery.Eval(ctx, rego.EvalInput([]loader.RegulaInput{options.Input[i]}))
+				if err != nil {
+					errs[i] = err
+					continue
+				} else if len(rs) < 1 {
+					errs[i] = fmt.Errorf("Query %s returned no results", query)
+					continue
+				}
+				values[i] = rs[0].Expressions[0].Value
+				if keys[i] != "" {
+					if err := cache.put(keys[i], values[i]); err != nil {
+						logrus.Warnf("Unable to cache result: %s", err)
+					}
+				}
+			}
+		}()
+	}
+	for _, i := range pending {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	merged, err := merge(values)
 	if err != nil {
 		return nil, err
 	}
-	return &results[0], nil
+	return &rego.Result{
+		Expressions: []*rego.ExpressionValue{{
+			Value: merged,
+			Text:  query,
+		}},
+	}, nil
 }
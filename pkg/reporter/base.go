@@ -16,6 +16,8 @@ package reporter
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"sort"
@@ -85,17 +87,25 @@ const (
 	Text
 	Compact
 	Sarif
+	HTML
+	GitLab
+	SonarQube
+	OCSF
 )
 
 var FormatIDs = map[Format][]string{
-	JSON:    {"json"},
-	Table:   {"table"},
-	Junit:   {"junit"},
-	Tap:     {"tap"},
-	None:    {"none"},
-	Text:    {"text"},
-	Compact: {"compact"},
-	Sarif:   {"sarif"},
+	JSON:      {"json"},
+	Table:     {"table"},
+	Junit:     {"junit"},
+	Tap:       {"tap"},
+	None:      {"none"},
+	Text:      {"text"},
+	Compact:   {"compact"},
+	Sarif:     {"sarif"},
+	HTML:      {"html"},
+	GitLab:    {"gitlab"},
+	SonarQube: {"sonarqube"},
+	OCSF:      {"ocsf"},
 }
 
 var DefaultFormat = FormatIDs[Text][0]
@@ -119,18 +129,60 @@ func ValidateFormat(name string) error {
 	return nil
 }
 
+// Output is an additional destination for a report in a given format.
+type Output struct {
+	Format Format
+	Path   string
+}
+
+// OutputFromString parses an output in the format=path form, e.g.
+// sarif=regula.sarif.
+func OutputFromString(s string) (Output, error) {
+	name, path, ok := strings.Cut(s, "=")
+	if !ok || path == "" {
+		return Output{}, fmt.Errorf("Invalid output %v, expected format=path", s)
+	}
+	format, err := FormatFromString(name)
+	if err != nil {
+		return Output{}, fmt.Errorf("Invalid output %v, unrecognized format %v", s, name)
+	}
+	return Output{Format: format, Path: path}, nil
+}
+
+func OutputsFromStrings(outputs []string) ([]Output, error) {
+	parsed := make([]Output, len(outputs))
+	for i, o := range outputs {
+		output, err := OutputFromString(o)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = output
+	}
+	return parsed, nil
+}
+
+func ValidateOutputs(outputs []string) error {
+	if _, err := OutputsFromStrings(outputs); err != nil {
+		return err
+	}
+	return nil
+}
+
 type Result int
 
 const (
 	WAIVED Result = iota
 	PASS
+	// BASELINED is used for failures that are recorded in a baseline.
+	BASELINED
 	FAIL
 )
 
 var regulaResults map[string]Result = map[string]Result{
-	"WAIVED": WAIVED,
-	"PASS":   PASS,
-	"FAIL":   FAIL,
+	"WAIVED":    WAIVED,
+	"PASS":      PASS,
+	"BASELINED": BASELINED,
+	"FAIL":      FAIL,
 }
 
 type RegulaReport struct {
@@ -173,8 +225,11 @@ func (report *RegulaReport) RecomputeSummary() {
 	ruleResults := map[string]int{}
 	severities := map[string]int{}
 
-	for k := range regulaResults {
-		ruleResults[k] = 0
+	for k, r := range regulaResults {
+		// BASELINED is only part of the summary when a baseline is used.
+		if r != BASELINED {
+			ruleResults[k] = 0
+		}
 	}
 	for k := range regulaSeverities {
 		severities[k] = 0
@@ -182,7 +237,7 @@ func (report *RegulaReport) RecomputeSummary() {
 
 	for _, result := range report.RuleResults {
 		filepathSet[result.Filepath] = struct{}{}
-		if _, ok := ruleResults[result.RuleResult]; ok {
+		if _, ok := regulaResults[result.RuleResult]; ok {
 			ruleResults[result.RuleResult] += 1
 		}
 		if _, ok := severities[result.RuleSeverity]; ok {
@@ -369,6 +424,34 @@ type RuleResult struct {
 	// a call stack.
 	SourceLocation loader.LocationStack `json:"source_location,omitempty"`
 	ActiveWaivers  []string             `json:"active_waivers,omitempty"`
+	// Fixes suggested by the rule, if any.
+	RuleFixes []RuleFix `json:"rule_fixes,omitempty"`
+	// Edits to the source code that apply the rule fixes.  These are only
+	// known when the source code can be edited.
+	SourceEdits []SourceEdit `json:"-"`
+}
+
+// RuleFix sets the attribute at the given path, relative to the resource, to
+// the given value.
+type RuleFix struct {
+	Attribute []interface{} `json:"attribute"`
+	Value     interface{}   `json:"value"`
+}
+
+// SourceEdit replaces the text between Start (inclusive) and End (exclusive)
+// in a file.  An empty range inserts text.
+type SourceEdit struct {
+	Path  string
+	Start SourcePosition
+	End   SourcePosition
+	Text  string
+}
+
+type SourcePosition struct {
+	Line int
+	Col  int
+	// Byte is the offset in the file.
+	Byte int
 }
 
 func (r RuleResult) IsWaived() bool {
@@ -383,6 +466,10 @@ func (r RuleResult) IsFail() bool {
 	return r.RuleResult == "FAIL"
 }
 
+func (r RuleResult) IsBaselined() bool {
+	return r.RuleResult == "BASELINED"
+}
+
 func (r RuleResult) Message() string {
 	if r.RuleMessage != "" {
 		return r.RuleMessage
@@ -393,6 +480,30 @@ func (r RuleResult) Message() string {
 	return r.RuleDescription
 }
 
+// Fingerprint identifies the rule, resource and file of a rule result.  It does
+// not depend on line numbers, so it stays the same across unrelated edits.
+func (r RuleResult) Fingerprint() string {
+	rule := r.RuleID
+	if rule == "" {
+		rule = r.RuleName
+	}
+	h := sha256.New()
+	for _, s := range []string{rule, r.ResourceType, r.ResourceID, r.Filepath} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// sourceFile returns the path and line of the most specific source location,
+// falling back to the configuration path with an unknown (0) line.
+func (r RuleResult) sourceFile() (string, int) {
+	if len(r.SourceLocation) > 0 {
+		return r.SourceLocation[0].Path, r.SourceLocation[0].Line
+	}
+	return r.Filepath, 0
+}
+
 func (r *RuleResult) EnrichRuleResult(conf loader.LoadedConfigurations) {
 	filepath := r.Filepath
 	location, err := conf.Location(filepath, []string{r.ResourceID})
@@ -514,7 +625,7 @@ func SeverityCompare(sevA, sevB string) bool {
 	return int(regulaSeverities[sevA]) > int(regulaSeverities[sevB])
 }
 
-// ResultCompare orders "FAIL" > "PASS" > "WAIVED"
+// ResultCompare orders "FAIL" > "BASELINED" > "PASS" > "WAIVED"
 func ResultCompare(resA, resB string) bool {
 	return int(regulaResults[resA]) > int(regulaResults[resB])
 }
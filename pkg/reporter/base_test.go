@@ -180,3 +180,17 @@ func TestRecomputeSummary(t *testing.T) {
 	report.RecomputeSummary()
 	assert.Equal(t, original, report)
 }
+
+func TestOutputsFromStrings(t *testing.T) {
+	outputs, err := OutputsFromStrings([]string{"sarif=regula.sarif", "JUnit=out/report=1.xml"})
+	assert.Nil(t, err)
+	assert.Equal(t, []Output{
+		{Format: Sarif, Path: "regula.sarif"},
+		{Format: Junit, Path: "out/report=1.xml"},
+	}, outputs)
+
+	for _, invalid := range []string{"sarif", "sarif=", "nope=report.txt"} {
+		_, err := OutputsFromStrings([]string{invalid})
+		assert.NotNil(t, err, invalid)
+	}
+}
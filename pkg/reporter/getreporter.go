@@ -34,6 +34,14 @@ func GetReporter(format Format) (Reporter, error) {
 		return CompactReporter, nil
 	case Sarif:
 		return SarifReporter, nil
+	case HTML:
+		return HTMLReporter, nil
+	case GitLab:
+		return GitLabReporter, nil
+	case SonarQube:
+		return SonarQubeReporter, nil
+	case OCSF:
+		return OCSFReporter, nil
 	default:
 		return nil, fmt.Errorf("Unsupported or unrecognized reporter: %v", FormatIDs[format])
 	}
@@ -0,0 +1,86 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"encoding/json"
+)
+
+// gitLabIssue is an issue in the GitLab Code Quality report format, see
+// https://docs.gitlab.com/ee/ci/testing/code_quality.html#implement-a-custom-tool
+type gitLabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitLabLocation `json:"location"`
+}
+
+type gitLabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitLabLines `json:"lines"`
+}
+
+type gitLabLines struct {
+	Begin int `json:"begin"`
+}
+
+var gitLabSeverities = map[Severity]string{
+	Unknown:       "info",
+	Informational: "info",
+	Low:           "minor",
+	Medium:        "major",
+	High:          "critical",
+	Critical:      "blocker",
+}
+
+// GitLabReporter returns the failures in the report in the GitLab Code
+// Quality format.
+func GitLabReporter(o *RegulaReport) (string, error) {
+	issues := []gitLabIssue{}
+	for _, r := range o.RuleResults {
+		if !r.IsFail() {
+			continue
+		}
+		severity, err := SeverityFromString(r.RuleSeverity)
+		if err != nil {
+			severity = Unknown
+		}
+		path, line := r.sourceFile()
+		if line < 1 {
+			// GitLab requires a line number.
+			line = 1
+		}
+		checkName := r.RuleID
+		if checkName == "" {
+			checkName = r.RuleName
+		}
+		issues = append(issues, gitLabIssue{
+			Description: checkName + ": " + r.Message() + " (" + r.ResourceID + ")",
+			CheckName:   checkName,
+			Fingerprint: r.Fingerprint(),
+			Severity:    gitLabSeverities[severity],
+			Location: gitLabLocation{
+				Path:  path,
+				Lines: gitLabLines{Begin: line},
+			},
+		})
+	}
+	output, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitLabOutput(t *testing.T) {
+	o := testOutput()
+	o.RuleResults[2].SourceLocation = loader.LocationStack{
+		{Path: "src/infra/compute.yaml", Line: 12, Col: 3},
+	}
+	result, err := GitLabReporter(&o)
+	require.Nil(t, err)
+
+	issues := []map[string]interface{}{}
+	require.Nil(t, json.Unmarshal([]byte(result), &issues))
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"description": "RULE_002: checks databases (r2)",
+			"check_name":  "RULE_002",
+			"fingerprint": o.RuleResults[0].Fingerprint(),
+			"severity":    "major",
+			"location": map[string]interface{}{
+				"path":  "src/infra/database.yaml",
+				"lines": map[string]interface{}{"begin": 1.0},
+			},
+		},
+		{
+			"description": "RULE_001: checks tags (r1)",
+			"check_name":  "RULE_001",
+			"fingerprint": o.RuleResults[2].Fingerprint(),
+			"severity":    "critical",
+			"location": map[string]interface{}{
+				"path":  "src/infra/compute.yaml",
+				"lines": map[string]interface{}{"begin": 12.0},
+			},
+		},
+	}, issues)
+}
+
+func TestFingerprint(t *testing.T) {
+	o := testOutput()
+	moved := o.RuleResults[2]
+	moved.SourceLocation = loader.LocationStack{{Path: "src/infra/compute.yaml", Line: 40}}
+	assert.Equal(t, o.RuleResults[2].Fingerprint(), moved.Fingerprint())
+	assert.NotEqual(t, o.RuleResults[0].Fingerprint(), o.RuleResults[2].Fingerprint())
+	assert.NotEqual(t, o.RuleResults[1].Fingerprint(), o.RuleResults[2].Fingerprint())
+}
@@ -0,0 +1,161 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+
+	"github.com/fugue/regula/v3/pkg/version"
+)
+
+//go:embed html.tmpl
+var htmlTemplateDefinition string
+
+var htmlTemplate = template.Must(template.New("html").Funcs(template.FuncMap{
+	"lower": strings.ToLower,
+}).Parse(htmlTemplateDefinition))
+
+// The number of lines shown before and after a source location.
+const htmlSnippetContext = 2
+
+// htmlSeverities lists severities from most to least important.
+var htmlSeverities = []string{"Critical", "High", "Medium", "Low", "Informational", "Unknown"}
+
+type htmlCount struct {
+	Name  string
+	Count int
+}
+
+type htmlSnippetLine struct {
+	Number    int
+	Text      string
+	Highlight bool
+}
+
+type htmlResult struct {
+	*RuleResult
+	// Result is RuleResult.RuleResult, which the embedded field shadows.
+	Result   string
+	Location string
+	Snippet  []htmlSnippetLine
+}
+
+type htmlRule struct {
+	RuleResults
+	Results []htmlResult
+}
+
+type htmlReport struct {
+	Version     string
+	Results     []htmlCount
+	Severities  []htmlCount
+	Failures    []htmlRule
+	ByFilepath  ResultsByFilepath
+	Suppressed  []htmlResult
+	Filepaths   []string
+	TotalFailed int
+}
+
+// htmlSnippets reads source files at most once per report.
+type htmlSnippets struct {
+	files map[string][]string
+}
+
+func (s *htmlSnippets) lines(path string) []string {
+	if lines, ok := s.files[path]; ok {
+		return lines
+	}
+	lines := []string{}
+	if contents, err := os.ReadFile(path); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(contents))
+		scanner.Buffer(nil, len(contents)+1)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+	}
+	s.files[path] = lines
+	return lines
+}
+
+func (s *htmlSnippets) result(r *RuleResult) htmlResult {
+	result := htmlResult{RuleResult: r, Result: r.RuleResult}
+	if len(r.SourceLocation) < 1 {
+		result.Location = r.Filepath
+		return result
+	}
+	loc := r.SourceLocation[0]
+	result.Location = loc.String()
+	lines := s.lines(loc.Path)
+	if loc.Line < 1 || loc.Line > len(lines) {
+		return result
+	}
+	for n := loc.Line - htmlSnippetContext; n <= loc.Line+htmlSnippetContext; n++ {
+		if n < 1 || n > len(lines) {
+			continue
+		}
+		result.Snippet = append(result.Snippet, htmlSnippetLine{
+			Number:    n,
+			Text:      lines[n-1],
+			Highlight: n == loc.Line,
+		})
+	}
+	return result
+}
+
+// HTMLReporter returns the Regula report as a self-contained HTML page.
+func HTMLReporter(o *RegulaReport) (string, error) {
+	snippets := &htmlSnippets{files: map[string][]string{}}
+	report := htmlReport{
+		Version:     version.Version,
+		ByFilepath:  o.AggregateByFilepath(),
+		Filepaths:   o.Summary.Filepaths,
+		TotalFailed: o.Summary.RuleResults["FAIL"],
+	}
+	for _, name := range []string{"FAIL", "PASS", "WAIVED", "BASELINED"} {
+		if count, ok := o.Summary.RuleResults[name]; ok {
+			report.Results = append(report.Results, htmlCount{name, count})
+		}
+	}
+	for _, name := range htmlSeverities {
+		report.Severities = append(report.Severities, htmlCount{name, o.Summary.Severities[name]})
+	}
+	for _, rule := range o.FailuresByRule() {
+		if len(rule.Results) < 1 {
+			continue
+		}
+		htmlRule := htmlRule{RuleResults: rule}
+		for _, r := range rule.Results {
+			htmlRule.Results = append(htmlRule.Results, snippets.result(r))
+		}
+		report.Failures = append(report.Failures, htmlRule)
+	}
+	for i := range o.RuleResults {
+		if o.RuleResults[i].IsWaived() || o.RuleResults[i].IsBaselined() {
+			report.Suppressed = append(report.Suppressed, snippets.result(&o.RuleResults[i]))
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := htmlTemplate.Execute(buf, report); err != nil {
+		return "", fmt.Errorf("Unable to render HTML report: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLOutput(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "compute.yaml")
+	require.Nil(t, os.WriteFile(source, []byte("a: 1\nb: 2\nr1:\n  <tag>: x\nc: 3\nd: 4\n"), 0644))
+
+	o := testOutput()
+	o.RuleResults[2].RuleRemediationDoc = "https://example.com/RULE_001"
+	o.RuleResults[2].SourceLocation = loader.LocationStack{
+		{Path: source, Line: 3, Col: 1},
+	}
+	o.RuleResults = append(o.RuleResults, RuleResult{
+		Filepath:      "src/infra/storage.yaml",
+		ResourceID:    "r4",
+		ResourceType:  "t4",
+		RuleID:        "RULE_003",
+		RuleName:      "myrule3",
+		RuleResult:    "WAIVED",
+		RuleSeverity:  "Low",
+		RuleSummary:   "checks buckets",
+		ActiveWaivers: []string{"W_001: Public website"},
+	})
+	result, err := HTMLReporter(&o)
+	require.Nil(t, err)
+
+	assert.Contains(t, result, "Found 2 problems in 3 files.")
+	assert.Contains(t, result, "RULE_001: checks tags")
+	assert.Contains(t, result, `<a href="https://example.com/RULE_001">Remediation</a>`)
+	assert.Contains(t, result, source+":3:1")
+	assert.Contains(t, result, `<span class="highlight">   3  r1:</span>`)
+	assert.Contains(t, result, "   4    &lt;tag&gt;: x")
+	assert.NotContains(t, result, "   6  d: 4")
+	assert.Contains(t, result, "<code>src/infra/network.yaml</code>")
+	assert.Contains(t, result, "W_001: Public website")
+}
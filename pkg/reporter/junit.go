@@ -38,7 +38,7 @@ func (r ResourceResults) ToTestCase() JUnitTestCase {
 	skips := []JUnitSkipMessage{}
 	failures := []JUnitFailure{}
 	for _, result := range results {
-		if result.IsWaived() {
+		if result.IsWaived() || result.IsBaselined() {
 			skips = append(skips, JUnitSkipMessage{
 				Message: result.Message(),
 			})
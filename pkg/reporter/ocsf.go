@@ -0,0 +1,210 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/fugue/regula/v3/pkg/version"
+)
+
+// The OCSF schema version and Compliance Finding class that the ocsf format
+// produces, see https://schema.ocsf.io/1.1.0/classes/compliance_finding
+const (
+	ocsfVersion          = "1.1.0"
+	ocsfCategoryUID      = 2
+	ocsfClassUID         = 2003
+	ocsfActivityCreate   = 1
+	ocsfStatusNew        = 1
+	ocsfStatusSuppressed = 3
+	ocsfCompliancePass   = 1
+	ocsfComplianceFail   = 3
+)
+
+type ocsfFinding struct {
+	ActivityID   int              `json:"activity_id"`
+	ActivityName string           `json:"activity_name"`
+	CategoryUID  int              `json:"category_uid"`
+	CategoryName string           `json:"category_name"`
+	ClassUID     int              `json:"class_uid"`
+	ClassName    string           `json:"class_name"`
+	TypeUID      int              `json:"type_uid"`
+	TypeName     string           `json:"type_name"`
+	Time         int64            `json:"time"`
+	SeverityID   int              `json:"severity_id"`
+	Severity     string           `json:"severity"`
+	StatusID     int              `json:"status_id"`
+	Status       string           `json:"status"`
+	Message      string           `json:"message"`
+	Metadata     ocsfMetadata     `json:"metadata"`
+	FindingInfo  ocsfFindingInfo  `json:"finding_info"`
+	Compliance   ocsfCompliance   `json:"compliance"`
+	Resources    []ocsfResource   `json:"resources"`
+	Remediation  *ocsfRemediation `json:"remediation,omitempty"`
+	Unmapped     *ocsfUnmapped    `json:"unmapped,omitempty"`
+}
+
+type ocsfMetadata struct {
+	Version string      `json:"version"`
+	Product ocsfProduct `json:"product"`
+}
+
+type ocsfProduct struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+	Version    string `json:"version"`
+}
+
+type ocsfFindingInfo struct {
+	UID   string   `json:"uid"`
+	Title string   `json:"title"`
+	Desc  string   `json:"desc,omitempty"`
+	Types []string `json:"types,omitempty"`
+}
+
+type ocsfCompliance struct {
+	Control      string   `json:"control"`
+	Requirements []string `json:"requirements,omitempty"`
+	Standards    []string `json:"standards,omitempty"`
+	StatusID     int      `json:"status_id"`
+	Status       string   `json:"status"`
+	StatusDetail string   `json:"status_detail,omitempty"`
+}
+
+type ocsfResource struct {
+	UID    string                 `json:"uid"`
+	Type   string                 `json:"type"`
+	Labels []string               `json:"labels,omitempty"`
+	Data   map[string]interface{} `json:"data,omitempty"`
+}
+
+type ocsfRemediation struct {
+	Desc       string   `json:"desc"`
+	References []string `json:"references"`
+}
+
+type ocsfUnmapped struct {
+	Filepath       string               `json:"filepath"`
+	InputType      string               `json:"input_type"`
+	SourceLocation loader.LocationStack `json:"source_location,omitempty"`
+}
+
+// OCSFReporter returns every rule result in the report as an OCSF Compliance
+// Finding.
+func OCSFReporter(o *RegulaReport) (string, error) {
+	now := time.Now().UnixMilli()
+	findings := []ocsfFinding{}
+	for _, r := range o.RuleResults {
+		findings = append(findings, r.toOCSFFinding(now))
+	}
+	output, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+func (r RuleResult) toOCSFFinding(now int64) ocsfFinding {
+	// Regula's severities line up with OCSF severity IDs 0 (Unknown) through
+	// 5 (Critical).
+	severity, err := SeverityFromString(r.RuleSeverity)
+	if err != nil || severity == Off {
+		severity = Unknown
+	}
+	control := r.RuleID
+	if control == "" {
+		control = r.RuleName
+	}
+
+	finding := ocsfFinding{
+		ActivityID:   ocsfActivityCreate,
+		ActivityName: "Create",
+		CategoryUID:  ocsfCategoryUID,
+		CategoryName: "Findings",
+		ClassUID:     ocsfClassUID,
+		ClassName:    "Compliance Finding",
+		TypeUID:      ocsfClassUID*100 + ocsfActivityCreate,
+		TypeName:     "Compliance Finding: Create",
+		Time:         now,
+		SeverityID:   int(severity),
+		Severity:     severity.String(),
+		StatusID:     ocsfStatusNew,
+		Status:       "New",
+		Message:      r.Message(),
+		Metadata: ocsfMetadata{
+			Version: ocsfVersion,
+			Product: ocsfProduct{
+				Name:       "Regula",
+				VendorName: "Fugue",
+				Version:    version.Version,
+			},
+		},
+		FindingInfo: ocsfFindingInfo{
+			UID:   r.Fingerprint(),
+			Title: r.RuleSummary,
+			Desc:  r.RuleDescription,
+			Types: []string{r.RuleName},
+		},
+		Compliance: ocsfCompliance{
+			Control:      control,
+			Requirements: r.Controls,
+			Standards:    r.Families,
+			StatusID:     ocsfComplianceFail,
+			Status:       "Fail",
+		},
+		Resources: []ocsfResource{r.toOCSFResource()},
+		Unmapped: &ocsfUnmapped{
+			Filepath:       r.Filepath,
+			InputType:      r.InputType,
+			SourceLocation: r.SourceLocation,
+		},
+	}
+	// Waived results keep the outcome of the rule itself.
+	if r.IsPass() || (r.IsWaived() && r.RuleRawResult) {
+		finding.Compliance.StatusID = ocsfCompliancePass
+		finding.Compliance.Status = "Pass"
+	}
+	if r.IsWaived() || r.IsBaselined() {
+		finding.StatusID = ocsfStatusSuppressed
+		finding.Status = "Suppressed"
+		finding.Compliance.StatusDetail = r.RuleResult
+	}
+	if r.RuleRemediationDoc != "" {
+		finding.Remediation = &ocsfRemediation{
+			Desc:       fmt.Sprintf("See %s", r.RuleRemediationDoc),
+			References: []string{r.RuleRemediationDoc},
+		}
+	}
+	return finding
+}
+
+func (r RuleResult) toOCSFResource() ocsfResource {
+	resource := ocsfResource{
+		UID:  r.ResourceID,
+		Type: r.ResourceType,
+	}
+	if len(r.ResourceTags) > 0 {
+		for k, v := range r.ResourceTags {
+			resource.Labels = append(resource.Labels, fmt.Sprintf("%s=%v", k, v))
+		}
+		sort.Strings(resource.Labels)
+		resource.Data = map[string]interface{}{"tags": r.ResourceTags}
+	}
+	return resource
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOCSFOutput(t *testing.T) {
+	o := testOutput()
+	o.RuleResults[2].Controls = []string{"CIS-AWS_v1.4.0_1.2"}
+	o.RuleResults[2].Families = []string{"CIS-AWS_v1.4.0"}
+	o.RuleResults[2].ResourceTags = map[string]interface{}{"env": "prod"}
+	o.RuleResults[2].RuleRemediationDoc = "https://example.com/RULE_001"
+	o.RuleResults[2].SourceLocation = loader.LocationStack{
+		{Path: "src/infra/compute.yaml", Line: 12, Col: 3},
+	}
+	o.RuleResults[0].RuleResult = "WAIVED"
+	result, err := OCSFReporter(&o)
+	require.Nil(t, err)
+
+	findings := []ocsfFinding{}
+	require.Nil(t, json.Unmarshal([]byte(result), &findings))
+	require.Len(t, findings, 3)
+	for _, f := range findings {
+		assert.Equal(t, 2003, f.ClassUID)
+		assert.Equal(t, 200301, f.TypeUID)
+		assert.NotZero(t, f.Time)
+	}
+
+	waived := findings[0]
+	assert.Equal(t, 3, waived.SeverityID)
+	assert.Equal(t, "Suppressed", waived.Status)
+	assert.Equal(t, "Fail", waived.Compliance.Status)
+	assert.Equal(t, "WAIVED", waived.Compliance.StatusDetail)
+
+	passed := findings[1]
+	assert.Equal(t, "Pass", passed.Compliance.Status)
+	assert.Equal(t, "New", passed.Status)
+
+	failed := findings[2]
+	assert.Equal(t, 4, failed.SeverityID)
+	assert.Equal(t, "High", failed.Severity)
+	assert.Equal(t, o.RuleResults[2].Fingerprint(), failed.FindingInfo.UID)
+	assert.Equal(t, ocsfCompliance{
+		Control:      "RULE_001",
+		Requirements: []string{"CIS-AWS_v1.4.0_1.2"},
+		Standards:    []string{"CIS-AWS_v1.4.0"},
+		StatusID:     3,
+		Status:       "Fail",
+	}, failed.Compliance)
+	assert.Equal(t, []ocsfResource{{
+		UID:    "r1",
+		Type:   "t1",
+		Labels: []string{"env=prod"},
+		Data:   map[string]interface{}{"tags": map[string]interface{}{"env": "prod"}},
+	}}, failed.Resources)
+	assert.Equal(t, []string{"https://example.com/RULE_001"}, failed.Remediation.References)
+	assert.Equal(t, o.RuleResults[2].SourceLocation, failed.Unmapped.SourceLocation)
+}
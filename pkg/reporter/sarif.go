@@ -16,6 +16,7 @@ package reporter
 
 import (
 	"bytes"
+	"strings"
 
 	"github.com/fugue/regula/v3/pkg/loader"
 	"github.com/fugue/regula/v3/pkg/version"
@@ -82,9 +83,22 @@ func SarifReporter(o *RegulaReport) (string, error) {
 		}
 
 		if r.IsWaived() {
-			result = result.WithSuppression([]*sarif.Suppression{
-				sarif.NewSuppression("external"),
-			})
+			suppression := sarif.NewSuppression("external")
+			if len(r.ActiveWaivers) > 0 {
+				suppression = suppression.WithJustifcation(strings.Join(r.ActiveWaivers, "; "))
+			}
+			result = result.WithSuppression([]*sarif.Suppression{suppression})
+		}
+
+		if r.IsBaselined() {
+			result = result.WithBaselineState("unchanged").
+				WithSuppression([]*sarif.Suppression{
+					sarif.NewSuppression("external"),
+				})
+		}
+
+		if len(r.SourceEdits) > 0 {
+			result = result.WithFix([]*sarif.Fix{ToSarifFix(r)})
 		}
 
 		l := r.SourceLocation
@@ -127,6 +141,36 @@ func ToSarifLocation(l loader.Location) *sarif.Location {
 	)
 }
 
+// Turns the source edits of a rule result into a sarif fix
+func ToSarifFix(r RuleResult) *sarif.Fix {
+	rule := r.RuleID
+	if rule == "" {
+		rule = r.RuleName
+	}
+	fix := sarif.NewFix().WithDescriptionText("Apply the fix suggested by " + rule)
+	changes := map[string]*sarif.ArtifactChange{}
+	for _, e := range r.SourceEdits {
+		change, ok := changes[e.Path]
+		if !ok {
+			change = sarif.NewArtifactChange(sarif.NewSimpleArtifactLocation(e.Path))
+			changes[e.Path] = change
+			fix.AddArtifactChanges(change)
+		}
+		startLine, startCol, endLine, endCol := e.Start.Line, e.Start.Col, e.End.Line, e.End.Col
+		region := sarif.NewRegion()
+		region.StartLine = &startLine
+		region.StartColumn = &startCol
+		region.EndLine = &endLine
+		region.EndColumn = &endCol
+		text := e.Text
+		change.Replacements = append(change.Replacements, &sarif.Replacement{
+			DeletedRegion:   *region,
+			InsertedContent: &sarif.ArtifactContent{Text: &text},
+		})
+	}
+	return fix
+}
+
 // Constructs sarif level based on rule result and severity.
 func ToSarifLevel(r string, s string) string {
 	if result, ok := regulaResults[r]; ok {
@@ -0,0 +1,93 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"encoding/json"
+)
+
+// sonarQubeReport is the SonarQube Generic Issue Import format, see
+// https://docs.sonarqube.org/latest/analyzing-source-code/importing-external-issues/generic-issue-import-format/
+type sonarQubeReport struct {
+	Issues []sonarQubeIssue `json:"issues"`
+}
+
+type sonarQubeIssue struct {
+	EngineID        string            `json:"engineId"`
+	RuleID          string            `json:"ruleId"`
+	Severity        string            `json:"severity"`
+	Type            string            `json:"type"`
+	PrimaryLocation sonarQubeLocation `json:"primaryLocation"`
+}
+
+type sonarQubeLocation struct {
+	Message   string              `json:"message"`
+	FilePath  string              `json:"filePath"`
+	TextRange *sonarQubeTextRange `json:"textRange,omitempty"`
+}
+
+type sonarQubeTextRange struct {
+	StartLine int `json:"startLine"`
+}
+
+var sonarQubeSeverities = map[Severity]string{
+	Unknown:       "INFO",
+	Informational: "INFO",
+	Low:           "MINOR",
+	Medium:        "MAJOR",
+	High:          "CRITICAL",
+	Critical:      "BLOCKER",
+}
+
+// SonarQubeReporter returns the failures in the report in the SonarQube
+// Generic Issue Import format.  SonarQube has no field for a fingerprint and
+// tracks issues by rule, file and message instead, so the message identifies
+// the resource but leaves out the line number.
+func SonarQubeReporter(o *RegulaReport) (string, error) {
+	report := sonarQubeReport{Issues: []sonarQubeIssue{}}
+	for _, r := range o.RuleResults {
+		if !r.IsFail() {
+			continue
+		}
+		severity, err := SeverityFromString(r.RuleSeverity)
+		if err != nil {
+			severity = Unknown
+		}
+		ruleID := r.RuleID
+		if ruleID == "" {
+			ruleID = r.RuleName
+		}
+		path, line := r.sourceFile()
+		location := sonarQubeLocation{
+			Message:  r.Message() + " (" + r.ResourceID + ")",
+			FilePath: path,
+		}
+		if line > 0 {
+			location.TextRange = &sonarQubeTextRange{StartLine: line}
+		}
+		report.Issues = append(report.Issues, sonarQubeIssue{
+			EngineID:        "regula",
+			RuleID:          ruleID,
+			Severity:        sonarQubeSeverities[severity],
+			Type:            "VULNERABILITY",
+			PrimaryLocation: location,
+		})
+	}
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
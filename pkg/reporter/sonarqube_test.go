@@ -0,0 +1,62 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fugue/regula/v3/pkg/loader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSonarQubeOutput(t *testing.T) {
+	o := testOutput()
+	o.RuleResults[2].SourceLocation = loader.LocationStack{
+		{Path: "modules/compute/main.tf", Line: 7, Col: 1},
+		{Path: "src/infra/compute.yaml", Line: 12, Col: 3},
+	}
+	result, err := SonarQubeReporter(&o)
+	require.Nil(t, err)
+
+	report := sonarQubeReport{}
+	require.Nil(t, json.Unmarshal([]byte(result), &report))
+	assert.Equal(t, sonarQubeReport{
+		Issues: []sonarQubeIssue{
+			{
+				EngineID: "regula",
+				RuleID:   "RULE_002",
+				Severity: "MAJOR",
+				Type:     "VULNERABILITY",
+				PrimaryLocation: sonarQubeLocation{
+					Message:  "checks databases (r2)",
+					FilePath: "src/infra/database.yaml",
+				},
+			},
+			{
+				EngineID: "regula",
+				RuleID:   "RULE_001",
+				Severity: "CRITICAL",
+				Type:     "VULNERABILITY",
+				PrimaryLocation: sonarQubeLocation{
+					Message:   "checks tags (r1)",
+					FilePath:  "modules/compute/main.tf",
+					TextRange: &sonarQubeTextRange{StartLine: 7},
+				},
+			},
+		},
+	}, report)
+}
@@ -139,7 +139,7 @@ func colorizeResult(result string) string {
 }
 
 func colorizeSeverity(r RuleResult) string {
-	if r.RuleResult == "PASS" || r.RuleResult == "WAIVED" {
+	if r.RuleResult == "PASS" || r.RuleResult == "WAIVED" || r.RuleResult == "BASELINED" {
 		return r.RuleSeverity
 	}
 
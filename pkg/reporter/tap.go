@@ -58,6 +58,11 @@ func (r RuleResult) ToTapRow(idx int) TapRow {
 	directive := ""
 	if r.IsWaived() {
 		directive = " # SKIP: rule waived"
+		if len(r.ActiveWaivers) > 0 {
+			directive += " (" + strings.Join(r.ActiveWaivers, "; ") + ")"
+		}
+	} else if r.IsBaselined() {
+		directive = " # SKIP: in baseline"
 	}
 	return TapRow{
 		Ok:        ok,
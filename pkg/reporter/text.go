@@ -74,6 +74,9 @@ func createTextReporterTemplate(tmpl string) *template.Template {
 				return color.New(color.FgHiBlue).Sprint(items...)
 			},
 			"LinkedText": func(url string, text string) string {
+				if color.NoColor {
+					return text
+				}
 				return fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", url, text)
 			},
 			"Praise": func() string {
@@ -0,0 +1,121 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rule_waivers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// waiverFile is the format of local waiver files.  Since JSON is a subset of
+// YAML, these can be written in either.
+type waiverFile struct {
+	Waivers []waiverFileEntry `yaml:"waivers"`
+}
+
+type waiverFileEntry struct {
+	ID           string `yaml:"id"`
+	Filepath     string `yaml:"filepath"`
+	ResourceID   string `yaml:"resource_id"`
+	ResourceTag  string `yaml:"resource_tag"`
+	ResourceType string `yaml:"resource_type"`
+	RuleID       string `yaml:"rule_id"`
+	Expires      string `yaml:"expires"`
+	Owner        string `yaml:"owner"`
+	Reason       string `yaml:"reason"`
+}
+
+// Formats accepted for the expires field, in order of preference.
+var expiresFormats = []string{
+	time.RFC3339,
+	"2006-01-02",
+}
+
+func parseExpires(expires string) (time.Time, error) {
+	for _, format := range expiresFormats {
+		if t, err := time.Parse(format, expires); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Invalid expires value %q, expected a date like 2006-01-02", expires)
+}
+
+// LoadWaiverFile reads the waivers from a local YAML or JSON waiver file.
+// Attributes that are not specified default to "*".
+func LoadWaiverFile(path string) ([]RuleWaiver, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read waiver file: %w", err)
+	}
+	file := waiverFile{}
+	dec := yaml.NewDecoder(bytes.NewReader(contents))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("Unable to parse waiver file %s: %w", path, err)
+	}
+
+	emptyToWildcard := func(val string) string {
+		if val == "" {
+			return "*"
+		}
+		return val
+	}
+
+	waivers := []RuleWaiver{}
+	for _, entry := range file.Waivers {
+		waiver := RuleWaiver{
+			ID:               entry.ID,
+			ResourceID:       emptyToWildcard(entry.ResourceID),
+			ResourceProvider: emptyToWildcard(entry.Filepath),
+			ResourceTag:      emptyToWildcard(entry.ResourceTag),
+			ResourceType:     emptyToWildcard(entry.ResourceType),
+			RuleID:           emptyToWildcard(entry.RuleID),
+			Owner:            entry.Owner,
+			Reason:           entry.Reason,
+		}
+		if entry.Expires != "" {
+			expires, err := parseExpires(entry.Expires)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to parse waiver file %s: %w", path, err)
+			}
+			waiver.Expires = &expires
+		}
+		waivers = append(waivers, waiver)
+	}
+	return waivers, nil
+}
+
+// Unexpired returns the waivers that have not expired at the given time, and
+// logs a warning for each waiver that has.
+func Unexpired(waivers []RuleWaiver, now time.Time) []RuleWaiver {
+	unexpired := []RuleWaiver{}
+	for _, waiver := range waivers {
+		if waiver.Expires != nil && !now.Before(*waiver.Expires) {
+			logrus.Warnf(
+				"Waiver %s expired on %s and no longer applies",
+				waiver.String(),
+				waiver.Expires.Format("2006-01-02"),
+			)
+			continue
+		}
+		unexpired = append(unexpired, waiver)
+	}
+	return unexpired
+}
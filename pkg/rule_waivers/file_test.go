@@ -0,0 +1,116 @@
+// Copyright 2022 Fugue, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rule_waivers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fugue/regula/v3/pkg/mocks"
+	"github.com/fugue/regula/v3/pkg/reporter"
+)
+
+const testWaiverFile = `
+waivers:
+  - id: public-website
+    rule_id: FG_R00229
+    resource_id: aws_s3_bucket.website*
+    filepath: infra
+    owner: web-team
+    reason: The website bucket is public on purpose
+  - rule_id: FG_R00100
+    resource_tag: env:dev
+    expires: 2022-06-01
+    reason: Dev buckets don't need logging
+`
+
+func TestLoadWaiverFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "waivers.yaml")
+	require.Nil(t, os.WriteFile(path, []byte(testWaiverFile), 0644))
+	waivers, err := LoadWaiverFile(path)
+	require.Nil(t, err)
+	expires := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []RuleWaiver{
+		{
+			ID:               "public-website",
+			ResourceID:       "aws_s3_bucket.website*",
+			ResourceProvider: "infra",
+			ResourceTag:      "*",
+			ResourceType:     "*",
+			RuleID:           "FG_R00229",
+			Owner:            "web-team",
+			Reason:           "The website bucket is public on purpose",
+		},
+		{
+			ResourceID:       "*",
+			ResourceProvider: "*",
+			ResourceTag:      "env:dev",
+			ResourceType:     "*",
+			RuleID:           "FG_R00100",
+			Expires:          &expires,
+			Reason:           "Dev buckets don't need logging",
+		},
+	}, waivers)
+
+	assert.Len(t, Unexpired(waivers, expires.Add(-time.Hour)), 2)
+	assert.Len(t, Unexpired(waivers, expires), 1)
+
+	ctrl := gomock.NewController(t)
+	configs := mocks.NewMockLoadedConfigurations(ctrl)
+	infra := "infra"
+	configs.EXPECT().ConfigurationPath("infra").Return(&infra).AnyTimes()
+	report := &reporter.RegulaReport{
+		RuleResults: []reporter.RuleResult{
+			{
+				Filepath:     "infra",
+				ResourceID:   "aws_s3_bucket.website_assets",
+				ResourceType: "aws_s3_bucket",
+				RuleID:       "FG_R00229",
+				RuleResult:   "FAIL",
+			},
+			{
+				Filepath:     "infra",
+				ResourceID:   "aws_s3_bucket.logs",
+				ResourceType: "aws_s3_bucket",
+				ResourceTags: map[string]interface{}{"env": "dev"},
+				RuleID:       "FG_R00100",
+				RuleResult:   "FAIL",
+			},
+		},
+	}
+	ApplyRuleWaivers(configs, report, Unexpired(waivers, expires))
+	assert.Equal(t, "WAIVED", report.RuleResults[0].RuleResult)
+	assert.Equal(t, []string{
+		"public-website: The website bucket is public on purpose (owner: web-team)",
+	}, report.RuleResults[0].ActiveWaivers)
+	assert.Equal(t, "FAIL", report.RuleResults[1].RuleResult)
+}
+
+func TestLoadWaiverFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "waivers.json")
+	require.Nil(t, os.WriteFile(path, []byte(`{"waivers": [{"rule": "FG_R00229"}]}`), 0644))
+	_, err := LoadWaiverFile(path)
+	assert.NotNil(t, err)
+
+	require.Nil(t, os.WriteFile(path, []byte(`{"waivers": [{"expires": "soon"}]}`), 0644))
+	_, err = LoadWaiverFile(path)
+	assert.NotNil(t, err)
+}
@@ -15,7 +15,9 @@
 package rule_waivers
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/fugue/regula/v3/pkg/loader"
 	"github.com/fugue/regula/v3/pkg/reporter"
@@ -39,6 +41,39 @@ type RuleWaiver struct {
 	ResourceTag      string
 	ResourceType     string
 	RuleID           string
+	// The fields below are only set for waivers from local waiver files.
+	Expires *time.Time
+	Owner   string
+	Reason  string
+}
+
+// String identifies the waiver in log messages.
+func (waiver RuleWaiver) String() string {
+	if waiver.ID != "" {
+		return waiver.ID
+	}
+	return fmt.Sprintf(
+		"for rule %s on resource %s (%s) in %s",
+		waiver.RuleID,
+		waiver.ResourceID,
+		waiver.ResourceType,
+		waiver.ResourceProvider,
+	)
+}
+
+// activeWaiver describes the waiver in the active waivers of a rule result.
+func (waiver RuleWaiver) activeWaiver() string {
+	description := waiver.ID
+	if waiver.Reason != "" {
+		if description != "" {
+			description += ": "
+		}
+		description += waiver.Reason
+	}
+	if waiver.Owner != "" {
+		description = strings.TrimSpace(fmt.Sprintf("%s (owner: %s)", description, waiver.Owner))
+	}
+	return description
 }
 
 // TODO: Add an interface for results/resources so we can use this both at
@@ -97,10 +132,10 @@ func ApplyRuleWaivers(
 			if waiver.Match(configs, report.RuleResults[i]) {
 				report.RuleResults[i].RuleResult = "WAIVED"
 
-				if waiver.ID != "" {
+				if active := waiver.activeWaiver(); active != "" {
 					report.RuleResults[i].ActiveWaivers = append(
 						report.RuleResults[i].ActiveWaivers,
-						waiver.ID,
+						active,
 					)
 				}
 			}
@@ -109,3 +144,42 @@ func ApplyRuleWaivers(
 
 	report.RecomputeSummary()
 }
+
+// ApplyInlineIgnores waives rule results for resources that are annotated
+// with a matching `regula:ignore` comment in the IaC source code.
+func ApplyInlineIgnores(
+	configs loader.LoadedConfigurations,
+	report *reporter.RegulaReport,
+) {
+	type resourceKey struct {
+		filepath   string
+		resourceID string
+	}
+	cache := map[resourceKey][]loader.InlineIgnore{}
+
+	for i := range report.RuleResults {
+		result := &report.RuleResults[i]
+		key := resourceKey{result.Filepath, result.ResourceID}
+		ignores, ok := cache[key]
+		if !ok {
+			ignores = configs.InlineIgnores(result.Filepath, result.ResourceID)
+			cache[key] = ignores
+		}
+		for _, ignore := range ignores {
+			if !ignore.Matches(result.RuleID, result.RuleName) {
+				continue
+			}
+			result.RuleResult = "WAIVED"
+			active := "regula:ignore at " + ignore.Location.Path
+			if ignore.Location.Line > 0 {
+				active = "regula:ignore at " + ignore.Location.String()
+			}
+			if ignore.Reason != "" {
+				active += ": " + ignore.Reason
+			}
+			result.ActiveWaivers = append(result.ActiveWaivers, active)
+		}
+	}
+
+	report.RecomputeSummary()
+}
@@ -0,0 +1,29 @@
+# Copyright 2020-2022 Fugue, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# This helper rego code works for Azure App Service rules that check the
+# config child resources of web apps.
+package arm.app_service_library
+
+import data.fugue
+
+sites := fugue.resources("Microsoft.Web/sites")
+
+configs := fugue.resources("Microsoft.Web/sites/config")
+
+# Child resources are named after their parent, e.g. "mysite/web".
+config_name(c) = ret {
+	parts := split(c.name, "/")
+	ret := parts[count(parts) - 1]
+}
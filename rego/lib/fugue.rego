@@ -65,6 +65,10 @@ deny_resource_with_message(resource, message) = ret {
   ret := deny({"resource": resource, "message": message})
 }
 
+deny_resource_with_fixes(resource, fixes) = ret {
+  ret := deny({"resource": resource, "fixes": fixes})
+}
+
 deny(params) = ret {
   ret := {
     "valid": false,
@@ -72,6 +76,7 @@ deny(params) = ret {
     "type": params.resource._type,
     "message": object.get(params, "message", ""),
     "attribute": object.get(params, "attribute", null),
+    "fixes": object.get(params, "fixes", []),
     "provider": params.resource._provider,
     "filepath": object.get(params.resource, "_filepath", ""),
     "tags": object.get(params.resource, "_tags", {}),
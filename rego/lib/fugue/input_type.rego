@@ -23,6 +23,7 @@ package fugue.input_type_internal
 #  -  "cfn"
 #  -  "k8s"
 #  -  "arm"
+#  -  "pulumi"
 #
 # To check the current resource type, use `input_type`.
 # To check if a rule applies for this input type, use `compatibility`.
@@ -41,6 +42,8 @@ input_type = "tf" {
   _ = input.AWSTemplateFormatVersion
 } else = "k8s" {
   _ = input.k8s_resource_view_version
+} else = "pulumi" {
+  _ = input.pulumi_resource_view_version
 } else = "arm" {
   _ = input.contentVersion
 } else = "unknown" {
@@ -65,6 +68,10 @@ arm_input_type {
   input_type == "arm"
 }
 
+pulumi_input_type {
+  input_type == "pulumi"
+}
+
 rule_input_type(pkg) = ret {
   # This is a workaround for an issue in fregot, where the next line will fail
   # the typechecker when there isn't a single `input_type` defined, which is
@@ -75,14 +82,16 @@ rule_input_type(pkg) = ret {
   ret = "tf"
 }
 
-# Which rule input type is applicable for which input types?
+# Which rule input type is applicable for which input types?  Pulumi resources
+# are mapped to the equivalent Terraform resources, so Terraform rules apply.
 compatibility := {
-  "tf":             {"tf", "tf_plan", "tf_runtime"},
-  "terraform":      {"tf", "tf_plan", "tf_runtime"},  # Backwards-compatibility
+  "tf":             {"tf", "tf_plan", "tf_runtime", "pulumi"},
+  "terraform":      {"tf", "tf_plan", "tf_runtime", "pulumi"},  # Backwards-compatibility
   "tf_plan":        {"tf_plan"},
   "tf_runtime":     {"tf_runtime"},
   "cfn":            {"cfn"},
   "cloudformation": {"cfn"},  # Backwards-compatibility
   "k8s":            {"k8s"},
   "arm":            {"arm"},
+  "pulumi":         {"pulumi"},
 }
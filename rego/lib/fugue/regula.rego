@@ -99,6 +99,24 @@ evaluate_denies(pkg, resource) = ret {
   ret = [a | a = data["rules"][pkg]["deny"] with input as resource]
 }
 
+# Evaluate the optional `fixes` set of a single-resource rule.  Each fix is an
+# object of the shape `{"attribute": [path...], "value": value}`.
+evaluate_fixes(pkg, resource) = ret {
+  ret = [f | data["rules"][pkg]["fixes"][f] with input as resource]
+}
+
+# Attach the fixes of a single-resource rule to a failed judgement that does not
+# carry its own.
+judgement_with_fixes(pkg, resource, judgement) = ret {
+  judgement.valid == false
+  count(object.get(judgement, "fixes", [])) == 0
+  fixes := evaluate_fixes(pkg, resource)
+  count(fixes) > 0
+  ret := json.patch(judgement, [{"op": "add", "path": "fixes", "value": fixes}])
+} else = judgement {
+  true
+}
+
 # Evaluate the judgement for a simple rule.  This may return multiple
 # judgements.
 evaluate_rule_judgements(pkg, resource) = ret {
@@ -130,6 +148,7 @@ rule_resource_result(rule, judgement) = ret {
     "resource_type": judgement.type,
     "resource_tags": judgement.tags,
     "rule_message": judgement.message,
+    "rule_fixes": object.get(judgement, "fixes", []),
     "rule_result": result_string(judgement),
     "rule_raw_result": judgement.valid,
     "rule_name": rule["package"],
@@ -155,7 +174,8 @@ evaluate_rule(rule) = ret {
   judgements = {j |
     resource = resource_view.resource_view[_]
     resource._type == resource_type
-    j = evaluate_rule_judgements(pkg, resource)[_]
+    judgement = evaluate_rule_judgements(pkg, resource)[_]
+    j = judgement_with_fixes(pkg, resource, judgement)
   }
 
   ret = [r | r = rule_resource_result(rule, judgements[_])]
@@ -27,6 +27,9 @@ resource_view = ret {
   # If we are already given a resource view, just pass it through.
   _ = input.hcl_resource_view_version
   ret = input.resources
+} else = ret {
+  input_type_internal.pulumi_input_type
+  ret = input.resources
 } else = ret {
   input_type_internal.terraform_input_type
   ret = terraform.resource_view
@@ -44,6 +47,9 @@ resource_view = ret {
 resource_view_input = ret {
   _ = input.hcl_resource_view_version
   ret = {"resources": resource_view}
+} else = ret {
+  input_type_internal.pulumi_input_type
+  ret = {"resources": resource_view}
 } else = ret {
   input_type_internal.terraform_input_type
   ret = {"resources": resource_view, "_plan": input}
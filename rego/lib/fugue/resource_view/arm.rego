@@ -15,17 +15,6 @@ package fugue.resource_view.arm
 
 import data.fugue.resource_view.tags as tags_lib
 
-# Returns a list of all parent resources of the resource at the given path,
-# ending with the resource itself.
-parent_resources(top_level_resource, path) = ret {
-	indices := numbers.range(1, floor(count(path) / 2))
-	ret := array.concat([top_level_resource], [resource |
-		i := indices[_] * 2
-		parent_path := array.slice(path, 0, i)
-		resource := json.patch(top_level_resource, [{"op": "move", "path": [], "from": parent_path}])
-	])
-}
-
 # Construct a full name from the type name and names, e.g.:
 #
 #     Microsoft.Network/virtualNetworks + VNet1 =
@@ -74,35 +63,24 @@ parent_typed_name(typed_name) = ret {
 	ret := null
 }
 
-# Extract all children resources (including the parent itself) from a resource.
-extract_resources(top_level_resource) = ret {
-	ret := {id: resource |
-		# Walk over a top level resource to retrieve all children.
-		# The path will be of the shape ["resources", 0, "resources", 2].
-		[path, value] = walk(top_level_resource)
-		count(path) % 2 == 0
-		all([ok | k = path[i]; i % 2 == 0; ok := k == "resources"])
-
-		parents := parent_resources(top_level_resource, path)
-		name := concat("/", [r.name | r := parents[_]])
-		type := concat("/", [r.type | r := parents[_]])
-		typed_name := make_typed_name(type, name)
-
-		id := typed_name
-		resource := json.patch(value, [
-			{"op": "add", "path": ["id"], "value": typed_name},
-			{"op": "add", "path": ["_type"], "value": type},
-			{"op": "add", "path": ["_provider"], "value": "azurerm"},
-			{"op": "add", "path": ["_parent_id"], "value": parent_typed_name(typed_name)},
-		])
-	}
+# The loader flattens child resources, so every resource has a fully-qualified
+# type and name, e.g. Microsoft.Network/virtualNetworks/subnets and
+# VNet1/Subnet1.
+extract_resource(resource) = ret {
+	typed_name := make_typed_name(resource.type, resource.name)
+	ret := json.patch(resource, [
+		{"op": "add", "path": ["id"], "value": typed_name},
+		{"op": "add", "path": ["_type"], "value": resource.type},
+		{"op": "add", "path": ["_provider"], "value": "azurerm"},
+		{"op": "add", "path": ["_parent_id"], "value": parent_typed_name(typed_name)},
+	])
 }
 
 resource_view := ret {
 	# First pass on resources.
 	resources_0 := {id: resource |
-		top_level_resource := input.resources[_]
-		resource := extract_resources(top_level_resource)[id]
+		resource := extract_resource(input.resources[_])
+		id := resource.id
 	}
 
 	# Rewrite references.
@@ -76,7 +76,9 @@ attribute_references(attr_path, attr_value) = refs {
 }
 
 fn_sub_template_variables(template) = ret {
-  matches := regex.find_all_string_submatch_n(`\$\{([:\w]+)[.:\w]*\}`, template, -1)
+  # Resources of CDK cloud assemblies are identified by their construct path,
+  # e.g. "AppStack/Bucket".
+  matches := regex.find_all_string_submatch_n(`\$\{([:/\w]+)[.:\w]*\}`, template, -1)
   ret := [arr[1] | arr = matches[_]]
 }
 
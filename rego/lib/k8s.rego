@@ -110,11 +110,12 @@ default_service_accounts[id] = ret {
 #
 #     {
 #       "resource": <original resource>,
-#       "pod_template": <pod template>
+#       "pod_template": <pod template>,
+#       "pod_template_path": <attribute path of the pod template in the resource>
 #     }
 resources_with_pod_templates[id] = ret {
 	resource := fugue.resources("Pod")[id]
-	ret := {"resource": resource, "pod_template": resource}
+	ret := {"resource": resource, "pod_template": resource, "pod_template_path": []}
 }
 
 resources_with_pod_templates[id] = ret {
@@ -122,6 +123,7 @@ resources_with_pod_templates[id] = ret {
 	ret := {
 		"resource": resource,
 		"pod_template": resource.spec.jobTemplate.spec.template,
+		"pod_template_path": ["spec", "jobTemplate", "spec", "template"],
 	}
 }
 
@@ -139,5 +141,6 @@ resources_with_pod_templates[id] = ret {
 	ret := {
 		"resource": resource,
 		"pod_template": resource.spec.template,
+		"pod_template_path": ["spec", "template"],
 	}
 }
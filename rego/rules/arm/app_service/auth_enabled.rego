@@ -15,6 +15,7 @@
 package rules.arm_app_service_auth_enabled
 
 import data.fugue
+import data.arm.app_service_library as lib
 
 __rego__metadoc__ := {
   "custom": {
@@ -37,16 +38,16 @@ input_type := "arm"
 
 resource_type := "MULTIPLE"
 
-sites := fugue.resources("Microsoft.Web/sites")
-configs := fugue.resources("Microsoft.Web/sites/config")
+sites := lib.sites
+configs := lib.configs
 
 is_valid_authsettings(c) {
-	c.name == "authsettings"
+	lib.config_name(c) == "authsettings"
 	c.properties.enabled == true
 }
 
 is_valid_authsettings(c) {
-	c.name == "authsettingsv2"
+	lib.config_name(c) == "authsettingsv2"
 	c.properties.platform.enabled == true
 }
 
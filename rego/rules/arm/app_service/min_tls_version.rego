@@ -15,6 +15,7 @@
 package rules.arm_app_service_min_tls_version
 
 import data.fugue
+import data.arm.app_service_library as lib
 
 __rego__metadoc__ := {
   "custom": {
@@ -37,8 +38,8 @@ input_type := "arm"
 
 resource_type := "MULTIPLE"
 
-sites := fugue.resources("Microsoft.Web/sites")
-configs := fugue.resources("Microsoft.Web/sites/config")
+sites := lib.sites
+configs := lib.configs
 
 min_tls_version := parse_version("1.2")
 
@@ -48,7 +49,7 @@ parse_version(str) = ret {
 
 valid_via_config := {id |
 	c := configs[_]
-	c.name == "web"
+	lib.config_name(c) == "web"
 	parsed := parse_version(c.properties.minTlsVersion)
 	parsed >= min_tls_version
 	id := c._parent_id
@@ -49,16 +49,15 @@ is_subscription_scope(scope) {
 	re_match(`^/subscriptions/[^/]+/?$`, lower(scope))
 }
 
-# Examples:
-# * "[concat('/subscriptions/', subscription().subscriptionId)]"
-# * "[concat('/subscriptions/', '479a226b-4153-48f7-8943-3e8e388a93cb')]"
-# * "[concat('/subscriptions/', parameters('subscriptionId'))]"
+# Scopes that depend on the deployment are unknown, e.g.:
+# * {"_unknown": "[concat('/subscriptions/', subscription().subscriptionId)]"}
+# * {"_unknown": "[concat('/subscriptions/', parameters('subscriptionId'))]"}
 is_subscription_scope(scope) {
-	re_match(`^\[concat\('/subscriptions/',[^,]+\]$`, replace(lower(scope), " ", ""))
+	re_match(`^\[concat\('/subscriptions/',[^,]+\]$`, replace(lower(scope._unknown), " ", ""))
 }
 
 is_subscription_scope(scope) {
-	replace(lower(scope), " ", "") == "[subscription().id]"
+	replace(lower(scope._unknown), " ", "") == "[subscription().id]"
 }
 
 default deny = false
@@ -40,8 +40,10 @@ resource_type := "MULTIPLE"
 key_vaults := fugue.resources("Microsoft.KeyVault/vaults")
 diagnostic_settings := fugue.resources("Microsoft.Insights/diagnosticSettings")
 
+# Splits a scope into the names it refers to, whether it is written as an
+# expression or was evaluated to a resource ID.
 tokenize(str) = ret {
-	ret = [p | p := regex.split(`[\[\]()',[:space:]]+`, str)[_]; p != ""]
+	ret = [p | p := regex.split(`[\[\]()',/[:space:]]+`, str)[_]; p != ""]
 }
 
 retention_is_valid(retention) {
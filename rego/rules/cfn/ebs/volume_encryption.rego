@@ -35,3 +35,7 @@ default allow = false
 allow {
   input.Encrypted == true
 }
+
+fixes[{"attribute": ["Encrypted"], "value": true}] {
+  not allow
+}
@@ -41,9 +41,19 @@ policy[j] {
 	j = fugue.allow_resource(obj.resource)
 }
 
+fixes(obj) = ret {
+	ret := [fix |
+		obj.pod_template.spec.containers[i].securityContext.allowPrivilegeEscalation == true
+		fix := {
+			"attribute": array.concat(obj.pod_template_path, ["spec", "containers", i, "securityContext", "allowPrivilegeEscalation"]),
+			"value": false,
+		}
+	]
+}
+
 policy[j] {
 	obj := k8s.resources_with_pod_templates[_]
 	count(obj.pod_template.spec.containers) > 0
 	any_invalid_containers(obj.pod_template)
-	j = fugue.deny_resource(obj.resource)
+	j = fugue.deny_resource_with_fixes(obj.resource, fixes(obj))
 }
@@ -44,7 +44,9 @@ policy[j] {
 } {
   v = volumes[_]
   not v.encrypted
-  j = fugue.deny(
-    {"resource": v, "attribute": ["encrypted"]}
-  )
+  j = fugue.deny({
+    "resource": v,
+    "attribute": ["encrypted"],
+    "fixes": [{"attribute": ["encrypted"], "value": true}],
+  })
 }
@@ -60,6 +60,15 @@ bucket_valid(bucket) {
   bucket_has_lifecycle_policy(bucket)
 }
 
+# Versioning can only be enabled in place if the bucket isn't configured by a
+# separate aws_s3_bucket_versioning resource.
+bucket_fixes(bucket) = [{"attribute": ["versioning", 0, "enabled"], "value": true}] {
+  not bucket_versioning_enabled(bucket)
+  not bucket_versioning_configs[lib.bucket_name_or_id(bucket)]
+} else = [] {
+  true
+}
+
 policy[p] {
   bucket := buckets[_]
   bucket_valid(bucket)
@@ -67,5 +76,5 @@ policy[p] {
 } {
   bucket := buckets[_]
   not bucket_valid(bucket)
-  p := fugue.deny_resource(bucket)
+  p := fugue.deny_resource_with_fixes(bucket, bucket_fixes(bucket))
 }
@@ -0,0 +1,45 @@
+# Copyright 2022 Fugue, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+package fugue.input_type_internal
+
+import data.fugue.resource_view
+
+pulumi_input = {
+  "pulumi_resource_view_version": "0.0.1",
+  "resources": {
+    "urn:pulumi:dev::infra::aws:s3/bucket:Bucket::logs": {
+      "id": "urn:pulumi:dev::infra::aws:s3/bucket:Bucket::logs",
+      "_type": "aws_s3_bucket",
+      "_provider": "aws",
+      "_tags": {},
+      "bucket": "logs",
+    },
+  },
+}
+
+test_pulumi_input_type {
+  input_type == "pulumi" with input as pulumi_input
+  pulumi_input_type with input as pulumi_input
+  not terraform_input_type with input as pulumi_input
+}
+
+test_pulumi_compatibility {
+  compatibility["tf"]["pulumi"]
+  not compatibility["tf_plan"]["pulumi"]
+  not compatibility["cfn"]["pulumi"]
+}
+
+test_pulumi_resource_view {
+  resource_view.resource_view == pulumi_input.resources with input as pulumi_input
+}
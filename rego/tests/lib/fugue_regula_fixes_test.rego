@@ -0,0 +1,108 @@
+# Copyright 2020 Fugue, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Tests that the fixes suggested by simple and advanced rules end up in the
+# rule results.
+package fugue.regula_fixes_test
+
+import data.fugue
+import data.fugue.regula
+import data.tests.lib.inputs.volume_encrypted_infra_json
+
+mock_config = volume_encrypted_infra_json.mock_config
+
+encrypt_fix = {"attribute": ["encrypted"], "value": true}
+
+kms_fix = {"attribute": ["kms_key_id"], "value": "alias/aws/ebs"}
+
+bad_volume = {
+  "id": "aws_ebs_volume.bad",
+  "_type": "aws_ebs_volume",
+  "_provider": "aws",
+}
+
+good_volume = {
+  "id": "aws_ebs_volume.good",
+  "_type": "aws_ebs_volume",
+  "_provider": "aws",
+}
+
+mock_rules = {
+  # A simple rule with a `fixes` set, which is only used for failures.
+  "simple_fail": {
+    "resource_type": "aws_ebs_volume",
+    "deny": true,
+    "fixes": {encrypt_fix},
+  },
+  "simple_pass": {
+    "resource_type": "aws_ebs_volume",
+    "allow": true,
+    "fixes": {encrypt_fix},
+  },
+  # A simple rule whose `deny` objects carry their own fixes, which take
+  # precedence over the `fixes` set.
+  "simple_deny_objects": {
+    "resource_type": "aws_ebs_volume",
+    "deny": {{"message": "Use a KMS key", "fixes": [kms_fix]}},
+    "fixes": {encrypt_fix},
+  },
+  # An advanced rule that uses `deny_resource_with_fixes`.
+  "advanced": {
+    "resource_type": "MULTIPLE",
+    "policy": {
+      fugue.allow_resource(good_volume),
+      fugue.deny_resource_with_fixes(bad_volume, [encrypt_fix]),
+    },
+  },
+}
+
+report = ret {
+  ret = regula.report with input as mock_config with data.rules as mock_rules
+}
+
+rule_fixes(rule_name, resource_id) = ret {
+  r := report.rule_results[_]
+  r.rule_name == rule_name
+  r.resource_id == resource_id
+  ret := r.rule_fixes
+}
+
+test_deny_resource_with_fixes {
+  j := fugue.deny_resource_with_fixes(bad_volume, [encrypt_fix])
+  j.valid == false
+  j.id == "aws_ebs_volume.bad"
+  j.fixes == [encrypt_fix]
+}
+
+test_deny_resource_without_fixes {
+  fugue.deny_resource(bad_volume).fixes == []
+}
+
+test_simple_rule_fixes {
+  rule_fixes("simple_fail", "aws_ebs_volume.bad") == [encrypt_fix]
+  rule_fixes("simple_fail", "aws_ebs_volume.good") == [encrypt_fix]
+}
+
+test_simple_rule_fixes_only_for_failures {
+  rule_fixes("simple_pass", "aws_ebs_volume.bad") == []
+}
+
+test_simple_rule_deny_object_fixes {
+  rule_fixes("simple_deny_objects", "aws_ebs_volume.bad") == [kms_fix]
+}
+
+test_advanced_rule_fixes {
+  rule_fixes("advanced", "aws_ebs_volume.bad") == [encrypt_fix]
+  rule_fixes("advanced", "aws_ebs_volume.good") == []
+}
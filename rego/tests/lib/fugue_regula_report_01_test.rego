@@ -71,6 +71,7 @@ expected_report = {
       "resource_type": "aws_ebs_volume",
       "resource_tags": {},
       "rule_message": "",
+      "rule_fixes": [],
       "rule_result": "PASS",
       "rule_name": "always_pass",
       "input_type": "tf_plan",
@@ -90,6 +91,7 @@ expected_report = {
       "resource_type": "aws_ebs_volume",
       "resource_tags": {},
       "rule_message": "",
+      "rule_fixes": [],
       "rule_result": "PASS",
       "rule_name": "always_pass",
       "input_type": "tf_plan",
@@ -109,6 +111,7 @@ expected_report = {
       "resource_type": "aws_ebs_volume",
       "resource_tags": {},
       "rule_message": "",
+      "rule_fixes": [],
       "rule_result": "PASS",
       "rule_name": "always_pass",
       "input_type": "tf_plan",
@@ -128,6 +131,7 @@ expected_report = {
       "resource_type": "aws_ebs_volume",
       "resource_tags": {},
       "rule_message": "",
+      "rule_fixes": [],
       "rule_result": "FAIL",
       "rule_name": "always_fail",
       "input_type": "tf_plan",
@@ -147,6 +151,7 @@ expected_report = {
       "resource_type": "aws_ebs_volume",
       "resource_tags": {},
       "rule_message": "",
+      "rule_fixes": [],
       "rule_result": "FAIL",
       "rule_name": "always_fail",
       "input_type": "tf_plan",
@@ -166,6 +171,7 @@ expected_report = {
       "resource_type": "aws_ebs_volume",
       "resource_tags": {},
       "rule_message": "",
+      "rule_fixes": [],
       "rule_result": "FAIL",
       "rule_name": "always_fail",
       "input_type": "tf_plan",
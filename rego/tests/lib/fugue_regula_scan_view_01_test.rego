@@ -128,6 +128,7 @@ expected_scan_view = {
         "rule_description": "This rule always passes",
         "rule_id": "FG_R00001",
         "rule_message": "",
+        "rule_fixes": [],
         "rule_name": "always_pass",
         "rule_result": "PASS",
         "rule_severity": "High",
@@ -151,6 +152,7 @@ expected_scan_view = {
         "rule_description": "This rule always passes",
         "rule_id": "FG_R00001",
         "rule_message": "",
+        "rule_fixes": [],
         "rule_name": "always_pass",
         "rule_result": "PASS",
         "rule_severity": "High",
@@ -174,6 +176,7 @@ expected_scan_view = {
         "rule_description": "This rule always passes",
         "rule_id": "FG_R00001",
         "rule_message": "",
+        "rule_fixes": [],
         "rule_name": "always_pass",
         "rule_result": "PASS",
         "rule_severity": "High",
@@ -193,6 +196,7 @@ expected_scan_view = {
         "rule_description": "",
         "rule_id": "",
         "rule_message": "",
+        "rule_fixes": [],
         "rule_name": "always_fail",
         "rule_result": "FAIL",
         "rule_severity": "Unknown",
@@ -212,6 +216,7 @@ expected_scan_view = {
         "rule_description": "",
         "rule_id": "",
         "rule_message": "",
+        "rule_fixes": [],
         "rule_name": "always_fail",
         "rule_result": "FAIL",
         "rule_severity": "Unknown",
@@ -231,6 +236,7 @@ expected_scan_view = {
         "rule_description": "",
         "rule_id": "",
         "rule_message": "",
+        "rule_fixes": [],
         "rule_name": "always_fail",
         "rule_result": "FAIL",
         "rule_severity": "Unknown",
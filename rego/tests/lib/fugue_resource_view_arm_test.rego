@@ -24,13 +24,6 @@ expected = {
 		"_type": "Microsoft.Network/virtualNetworks",
 		"apiVersion": "2018-10-01",
 		"location": "switzerlandnorth",
-		"resources": [{
-			"apiVersion": "2018-10-01",
-			"dependsOn": ["VNet1"],
-			"name": "Subnet1",
-			"type": "subnets",
-			"properties": {"addressPrefix": "10.0.0.0/24"},
-		}],
 		"_parent_id": null,
 		"name": "VNet1",
 		"id": "Microsoft.Network/virtualNetworks/VNet1",
@@ -63,9 +56,9 @@ expected = {
 		"apiVersion": "2018-10-01",
 		"_parent_id": "Microsoft.Network/virtualNetworks/VNet1",
 		"dependsOn": ["VNet1"],
-		"name": "Subnet1",
+		"name": "VNet1/Subnet1",
 		"id": "Microsoft.Network/virtualNetworks/VNet1/subnets/Subnet1",
-		"type": "subnets",
+		"type": "Microsoft.Network/virtualNetworks/subnets",
 		"_provider": "azurerm",
 		"properties": {"addressPrefix": "10.0.0.0/24"},
 		"_tags": {},
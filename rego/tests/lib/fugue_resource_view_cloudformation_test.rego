@@ -170,6 +170,28 @@ Resources:
   }
 }
 
+test_resource_references_construct_paths {
+  cfn := yaml.unmarshal(`
+Resources:
+  AppStack/Bucket:
+    Type: AWS::S3::Bucket
+  AppStack/Bucket/Policy:
+    Type: AWS::S3::BucketPolicy
+    Properties:
+      Bucket: {Ref: AppStack/Bucket}
+      Resource:
+        Fn::Sub: "${AppStack/Bucket.Arn}/*"`)
+  rv := resource_view with input as cfn
+  rv["AppStack/Bucket/Policy"] == {
+    "_type": "AWS::S3::BucketPolicy",
+    "_provider": "aws",
+    "_tags": {},
+    "id": "AppStack/Bucket/Policy",
+    "Bucket": "AppStack/Bucket",
+    "Resource": "AppStack/Bucket",
+  }
+}
+
 test_resource_tags_01 {
   cfn := yaml.unmarshal(`
 Resources:
@@ -35,13 +35,13 @@ mock_config := {
 				"Dept": "Finance",
 				"Environment": "Production",
 			},
-			"resources": [{
-				"type": "subnets",
-				"apiVersion": "2018-10-01",
-				"name": "Subnet1",
-				"dependsOn": ["VNet1"],
-				"properties": {"addressPrefix": "10.0.0.0/24"},
-			}],
+		},
+		{
+			"type": "Microsoft.Network/virtualNetworks/subnets",
+			"apiVersion": "2018-10-01",
+			"name": "VNet1/Subnet1",
+			"dependsOn": ["VNet1"],
+			"properties": {"addressPrefix": "10.0.0.0/24"},
 		},
 		{
 			"type": "Microsoft.Network/virtualNetworks/subnets",
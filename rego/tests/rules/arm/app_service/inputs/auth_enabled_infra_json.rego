@@ -25,7 +25,9 @@ mock_config := {
   "resources": [
     {
       "apiVersion": "2021-02-01",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "appServicePlanPortal",
       "sku": {
         "name": "B1",
@@ -37,64 +39,66 @@ mock_config := {
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "withAuth",
       "properties": {
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal"
       },
-      "resources": [
-        {
-          "apiVersion": "2021-02-01",
-          "dependsOn": [
-            "[resourceId('Microsoft.Web/Sites', 'withAuth')]"
-          ],
-          "name": "authsettings",
-          "properties": {
-            "enabled": true
-          },
-          "type": "config"
-        }
-      ],
       "type": "Microsoft.Web/sites"
     },
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/sites/withAuth"
       ],
-      "location": "[resourceGroup().location]",
-      "name": "withAuthV2",
+      "name": "withAuth/authsettings",
       "properties": {
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "enabled": true
       },
-      "resources": [
-        {
-          "apiVersion": "2021-02-01",
-          "dependsOn": [
-            "[resourceId('Microsoft.Web/Sites', 'withAuthV2')]"
-          ],
-          "name": "authsettingsv2",
-          "properties": {
-            "platform": {
-              "enabled": true
-            }
-          },
-          "type": "config"
-        }
+      "type": "Microsoft.Web/sites/config"
+    },
+    {
+      "apiVersion": "2021-02-01",
+      "dependsOn": [
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
       ],
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
+      "name": "withAuthV2",
+      "properties": {
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal"
+      },
       "type": "Microsoft.Web/sites"
     },
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/sites/withAuthV2"
       ],
-      "location": "[resourceGroup().location]",
+      "name": "withAuthV2/authsettingsv2",
+      "properties": {
+        "platform": {
+          "enabled": true
+        }
+      },
+      "type": "Microsoft.Web/sites/config"
+    },
+    {
+      "apiVersion": "2021-02-01",
+      "dependsOn": [
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
+      ],
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "withoutAuth",
       "properties": {
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal"
       },
       "type": "Microsoft.Web/sites"
     }
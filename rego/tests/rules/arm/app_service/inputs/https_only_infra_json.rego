@@ -26,7 +26,9 @@ mock_config := {
   "resources": [
     {
       "apiVersion": "2021-02-01",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "appServicePlanPortal",
       "sku": {
         "name": "B1",
@@ -38,38 +40,44 @@ mock_config := {
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "enabled",
       "properties": {
         "httpsOnly": true,
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal"
       },
       "type": "Microsoft.Web/sites"
     },
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "disabled",
       "properties": {
         "httpsOnly": false,
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal"
       },
       "type": "Microsoft.Web/sites"
     },
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "default",
       "properties": {
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal"
       },
       "type": "Microsoft.Web/sites"
     }
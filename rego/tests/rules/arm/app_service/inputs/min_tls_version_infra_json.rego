@@ -26,7 +26,9 @@ mock_config := {
   "resources": [
     {
       "apiVersion": "2021-02-01",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "appServicePlanPortal",
       "sku": {
         "name": "B1",
@@ -38,12 +40,14 @@ mock_config := {
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "validViaProperty",
       "properties": {
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]",
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal",
         "siteConfig": {
           "minTlsVersion": "1.2"
         }
@@ -53,62 +57,64 @@ mock_config := {
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "validViaConfig",
       "properties": {
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal"
       },
-      "resources": [
-        {
-          "apiVersion": "2021-02-01",
-          "dependsOn": [
-            "[resourceId('Microsoft.Web/Sites', 'validViaConfig')]"
-          ],
-          "name": "web",
-          "properties": {
-            "minTlsVersion": "1.2"
-          },
-          "type": "config"
-        }
-      ],
       "type": "Microsoft.Web/sites"
     },
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/sites/validViaConfig"
       ],
-      "location": "[resourceGroup().location]",
-      "name": "invalidViaConfig",
+      "name": "validViaConfig/web",
       "properties": {
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "minTlsVersion": "1.2"
       },
-      "resources": [
-        {
-          "apiVersion": "2021-02-01",
-          "dependsOn": [
-            "[resourceId('Microsoft.Web/Sites', 'invalidViaConfig')]"
-          ],
-          "name": "web",
-          "properties": {
-            "minTlsVersion": "1.1"
-          },
-          "type": "config"
-        }
+      "type": "Microsoft.Web/sites/config"
+    },
+    {
+      "apiVersion": "2021-02-01",
+      "dependsOn": [
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
       ],
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
+      "name": "invalidViaConfig",
+      "properties": {
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal"
+      },
       "type": "Microsoft.Web/sites"
     },
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/sites/invalidViaConfig"
+      ],
+      "name": "invalidViaConfig/web",
+      "properties": {
+        "minTlsVersion": "1.1"
+      },
+      "type": "Microsoft.Web/sites/config"
+    },
+    {
+      "apiVersion": "2021-02-01",
+      "dependsOn": [
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalidViaProperty",
       "properties": {
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]",
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal",
         "siteConfig": {
           "minTlsVersion": "1.1"
         }
@@ -118,12 +124,14 @@ mock_config := {
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalidUnset",
       "properties": {
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal"
       },
       "type": "Microsoft.Web/sites"
     }
@@ -25,7 +25,9 @@ mock_config := {
   "resources": [
     {
       "apiVersion": "2021-02-01",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "appServicePlanPortal",
       "sku": {
         "name": "B1",
@@ -37,42 +39,48 @@ mock_config := {
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
       ],
       "identity": {
         "type": "SystemAssigned"
       },
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "valid",
       "properties": {
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal"
       },
       "type": "Microsoft.Web/sites"
     },
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
       ],
       "identity": {
         "type": "None"
       },
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalidType",
       "properties": {
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal"
       },
       "type": "Microsoft.Web/sites"
     },
     {
       "apiVersion": "2021-02-01",
       "dependsOn": [
-        "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "Microsoft.Web/serverfarms/appServicePlanPortal"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalidUnset",
       "properties": {
-        "serverFarmId": "[resourceId('Microsoft.Web/serverfarms', 'appServicePlanPortal')]"
+        "serverFarmId": "Microsoft.Web/serverfarms/appServicePlanPortal"
       },
       "type": "Microsoft.Web/sites"
     }
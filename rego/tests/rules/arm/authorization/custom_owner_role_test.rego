@@ -20,13 +20,12 @@ test_is_subscription_scope {
 	is_subscription_scope("/")
 	is_subscription_scope("/subscriptions/479a226b-4153-48f7-8943-3e8e388a93cb")
 	is_subscription_scope("/subscriptions/479a226b-4153-48f7-8943-3e8e388a93cb/")
-	is_subscription_scope("[concat('/subscriptions/', subscription().subscriptionId)]")
-	is_subscription_scope("[concat('/subscriptions/', '479a226b-4153-48f7-8943-3e8e388a93cb')]")
-	is_subscription_scope("[concat('/subscriptions/', parameters('subscriptionId'))]")
-	is_subscription_scope("[subscription().id]")
+	is_subscription_scope({"_unknown": "[concat('/subscriptions/', subscription().subscriptionId)]"})
+	is_subscription_scope({"_unknown": "[concat('/subscriptions/', parameters('subscriptionId'))]"})
+	is_subscription_scope({"_unknown": "[subscription().id]"})
 
 	not is_subscription_scope("/subscriptions/479a226b-4153-48f7-8943-3e8e388a93cb/providers/Microsoft.Authorization/roleDefinitions/8e3af657-a8ff-443c-a75c-2fe8c4bcb635")
-	not is_subscription_scope("[concat('/subscriptions/', subscription().subscriptionId, '/providers/Microsoft.Authorization/roleDefinitions/', '8e3af657-a8ff-443c-a75c-2fe8c4bcb635')]")
+	not is_subscription_scope({"_unknown": "[concat('/subscriptions/', subscription().subscriptionId, '/providers/Microsoft.Authorization/roleDefinitions/', '8e3af657-a8ff-443c-a75c-2fe8c4bcb635')]"})
 }
 
 test_custom_owner_role {
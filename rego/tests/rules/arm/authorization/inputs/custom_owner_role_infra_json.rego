@@ -79,7 +79,9 @@ mock_config := {
       "name": "invalidConcat",
       "properties": {
         "assignableScopes": [
-          "[concat('/subscriptions/', subscription().subscriptionId)]"
+          {
+            "_unknown": "[concat('/subscriptions/', subscription().subscriptionId)]"
+          }
         ],
         "permissions": [
           {
@@ -94,7 +96,9 @@ mock_config := {
       "name": "invalidSubscriptionId",
       "properties": {
         "assignableScopes": [
-          "[subscription().id]"
+          {
+            "_unknown": "[subscription().id]"
+          }
         ],
         "permissions": [
           {
@@ -109,7 +113,9 @@ mock_config := {
       "name": "validAction",
       "properties": {
         "assignableScopes": [
-          "[subscription().id]"
+          {
+            "_unknown": "[subscription().id]"
+          }
         ],
         "permissions": [
           {
@@ -124,7 +130,9 @@ mock_config := {
       "name": "validScope",
       "properties": {
         "assignableScopes": [
-          "[concat('Microsoft.Storage/storageAccounts', '/', parameters('storageName'))]"
+          {
+            "_unknown": "[concat('Microsoft.Storage/storageAccounts', '/', parameters('storageName'))]"
+          }
         ],
         "permissions": [
           {
@@ -33,18 +33,24 @@ mock_config := {
   "resources": [
     {
       "apiVersion": "2021-04-01",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "valid",
       "properties": {
         "accessPolicies": [
           {
-            "objectId": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]",
+            "objectId": {
+              "_unknown": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]"
+            },
             "permissions": {
               "secrets": [
                 "get"
               ]
             },
-            "tenantId": "[subscription().tenantId]"
+            "tenantId": {
+              "_unknown": "[subscription().tenantId]"
+            }
           }
         ],
         "enablePurgeProtection": true,
@@ -53,48 +59,64 @@ mock_config := {
           "family": "A",
           "name": "Standard"
         },
-        "tenantId": "[subscription().tenantId]"
+        "tenantId": {
+          "_unknown": "[subscription().tenantId]"
+        }
       },
       "type": "Microsoft.KeyVault/vaults"
     },
     {
       "apiVersion": "2021-04-01",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalidUnset",
       "properties": {
         "accessPolicies": [
           {
-            "objectId": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]",
+            "objectId": {
+              "_unknown": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]"
+            },
             "permissions": {
               "secrets": [
                 "get"
               ]
             },
-            "tenantId": "[subscription().tenantId]"
+            "tenantId": {
+              "_unknown": "[subscription().tenantId]"
+            }
           }
         ],
         "sku": {
           "family": "A",
           "name": "Standard"
         },
-        "tenantId": "[subscription().tenantId]"
+        "tenantId": {
+          "_unknown": "[subscription().tenantId]"
+        }
       },
       "type": "Microsoft.KeyVault/vaults"
     },
     {
       "apiVersion": "2021-04-01",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalidEnablePurgeProtection",
       "properties": {
         "accessPolicies": [
           {
-            "objectId": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]",
+            "objectId": {
+              "_unknown": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]"
+            },
             "permissions": {
               "secrets": [
                 "get"
               ]
             },
-            "tenantId": "[subscription().tenantId]"
+            "tenantId": {
+              "_unknown": "[subscription().tenantId]"
+            }
           }
         ],
         "enablePurgeProtection": false,
@@ -103,24 +125,32 @@ mock_config := {
           "family": "A",
           "name": "Standard"
         },
-        "tenantId": "[subscription().tenantId]"
+        "tenantId": {
+          "_unknown": "[subscription().tenantId]"
+        }
       },
       "type": "Microsoft.KeyVault/vaults"
     },
     {
       "apiVersion": "2021-04-01",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalidEnableSoftDelete",
       "properties": {
         "accessPolicies": [
           {
-            "objectId": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]",
+            "objectId": {
+              "_unknown": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]"
+            },
             "permissions": {
               "secrets": [
                 "get"
               ]
             },
-            "tenantId": "[subscription().tenantId]"
+            "tenantId": {
+              "_unknown": "[subscription().tenantId]"
+            }
           }
         ],
         "enablePurgeProtection": true,
@@ -129,7 +159,9 @@ mock_config := {
           "family": "A",
           "name": "Standard"
         },
-        "tenantId": "[subscription().tenantId]"
+        "tenantId": {
+          "_unknown": "[subscription().tenantId]"
+        }
       },
       "type": "Microsoft.KeyVault/vaults"
     }
@@ -26,12 +26,16 @@ mock_config := {
   "resources": [
     {
       "apiVersion": "2021-06-01-preview",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "regulasecretvault1",
       "properties": {
         "accessPolicies": [
           {
-            "objectId": "[subscription().subscriptionId]",
+            "objectId": {
+              "_unknown": "[subscription().subscriptionId]"
+            },
             "permissions": {
               "certificates": [],
               "keys": [],
@@ -40,7 +44,9 @@ mock_config := {
                 "List"
               ]
             },
-            "tenantId": "[subscription().tenantId]"
+            "tenantId": {
+              "_unknown": "[subscription().tenantId]"
+            }
           }
         ],
         "enablePurgeProtection": true,
@@ -49,36 +55,36 @@ mock_config := {
           "family": "A",
           "name": "Standard"
         },
-        "tenantId": "[subscription().tenantId]"
-      },
-      "resources": [
-        {
-          "apiVersion": "2021-06-01-preview",
-          "dependsOn": [
-            "Microsoft.KeyVault/vaults/regulasecretvault1"
-          ],
-          "name": "secret1",
-          "properties": {
-            "value": "hunter2"
-          },
-          "type": "secrets"
-        },
-        {
-          "apiVersion": "2021-06-01-preview",
-          "dependsOn": [
-            "Microsoft.KeyVault/vaults/regulasecretvault1"
-          ],
-          "name": "secret2",
-          "properties": {
-            "attributes": {
-              "exp": 1700000000
-            },
-            "value": "hunter2"
-          },
-          "type": "secrets"
+        "tenantId": {
+          "_unknown": "[subscription().tenantId]"
         }
-      ],
+      },
       "type": "Microsoft.KeyVault/vaults"
+    },
+    {
+      "apiVersion": "2021-06-01-preview",
+      "dependsOn": [
+        "Microsoft.KeyVault/vaults/regulasecretvault1"
+      ],
+      "name": "regulasecretvault1/secret1",
+      "properties": {
+        "value": "hunter2"
+      },
+      "type": "Microsoft.KeyVault/vaults/secrets"
+    },
+    {
+      "apiVersion": "2021-06-01-preview",
+      "dependsOn": [
+        "Microsoft.KeyVault/vaults/regulasecretvault1"
+      ],
+      "name": "regulasecretvault1/secret2",
+      "properties": {
+        "attributes": {
+          "exp": 1700000000
+        },
+        "value": "hunter2"
+      },
+      "type": "Microsoft.KeyVault/vaults/secrets"
     }
   ]
 }
@@ -29,7 +29,9 @@ mock_config := {
       "identity": {
         "type": "SystemAssigned"
       },
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "valid",
       "properties": {
         "agentPoolProfiles": [
@@ -54,7 +56,9 @@ mock_config := {
       "identity": {
         "type": "SystemAssigned"
       },
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalid",
       "properties": {
         "agentPoolProfiles": [
@@ -79,7 +83,9 @@ mock_config := {
       "identity": {
         "type": "SystemAssigned"
       },
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalidUnset",
       "properties": {
         "agentPoolProfiles": [
@@ -34,7 +34,9 @@ mock_config := {
     {
       "apiVersion": "2021-06-01",
       "kind": "StorageV2",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "[variables('storageAccountName')]",
       "sku": {
         "name": "Standard_LRS"
@@ -43,33 +45,43 @@ mock_config := {
     },
     {
       "apiVersion": "2021-04-01-preview",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "valid",
       "properties": {
         "accessPolicies": [
           {
-            "objectId": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]",
+            "objectId": {
+              "_unknown": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]"
+            },
             "permissions": {
               "secrets": [
                 "get"
               ]
             },
-            "tenantId": "[subscription().tenantId]"
+            "tenantId": {
+              "_unknown": "[subscription().tenantId]"
+            }
           }
         ],
         "sku": {
           "family": "A",
           "name": "standard"
         },
-        "tenantId": "[subscription().tenantId]"
+        "tenantId": {
+          "_unknown": "[subscription().tenantId]"
+        }
       },
       "type": "Microsoft.KeyVault/vaults"
     },
     {
       "apiVersion": "2021-05-01-preview",
       "dependsOn": [
-        "[resourceId('Microsoft.KeyVault/vaults', 'valid')]",
-        "[resourceId('Microsoft.Storage/storageAccounts', variables('storageAccountName'))]"
+        "Microsoft.KeyVault/vaults/valid",
+        {
+          "_unknown": "[resourceId('Microsoft.Storage/storageAccounts', variables('storageAccountName'))]"
+        }
       ],
       "name": "valid",
       "properties": {
@@ -83,40 +95,52 @@ mock_config := {
             }
           }
         ],
-        "storageAccountId": "[resourceId('Microsoft.Storage/storageAccounts', variables('storageAccountName'))]"
+        "storageAccountId": {
+          "_unknown": "[resourceId('Microsoft.Storage/storageAccounts', variables('storageAccountName'))]"
+        }
       },
-      "scope": "[format('Microsoft.KeyVault/vaults/{0}', 'valid')]",
+      "scope": "Microsoft.KeyVault/vaults/valid",
       "type": "Microsoft.Insights/diagnosticSettings"
     },
     {
       "apiVersion": "2021-04-01-preview",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalidNoRetention",
       "properties": {
         "accessPolicies": [
           {
-            "objectId": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]",
+            "objectId": {
+              "_unknown": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]"
+            },
             "permissions": {
               "secrets": [
                 "get"
               ]
             },
-            "tenantId": "[subscription().tenantId]"
+            "tenantId": {
+              "_unknown": "[subscription().tenantId]"
+            }
           }
         ],
         "sku": {
           "family": "A",
           "name": "standard"
         },
-        "tenantId": "[subscription().tenantId]"
+        "tenantId": {
+          "_unknown": "[subscription().tenantId]"
+        }
       },
       "type": "Microsoft.KeyVault/vaults"
     },
     {
       "apiVersion": "2021-05-01-preview",
       "dependsOn": [
-        "[resourceId('Microsoft.KeyVault/vaults', 'invalidNoRetention')]",
-        "[resourceId('Microsoft.Storage/storageAccounts', variables('storageAccountName'))]"
+        "Microsoft.KeyVault/vaults/invalidNoRetention",
+        {
+          "_unknown": "[resourceId('Microsoft.Storage/storageAccounts', variables('storageAccountName'))]"
+        }
       ],
       "name": "invalidNoRetention",
       "properties": {
@@ -129,32 +153,42 @@ mock_config := {
             }
           }
         ],
-        "storageAccountId": "[resourceId('Microsoft.Storage/storageAccounts', variables('storageAccountName'))]"
+        "storageAccountId": {
+          "_unknown": "[resourceId('Microsoft.Storage/storageAccounts', variables('storageAccountName'))]"
+        }
       },
-      "scope": "[format('Microsoft.KeyVault/vaults/{0}', 'invalidNoRetention')]",
+      "scope": "Microsoft.KeyVault/vaults/invalidNoRetention",
       "type": "Microsoft.Insights/diagnosticSettings"
     },
     {
       "apiVersion": "2021-04-01-preview",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalidNoDiagnostics",
       "properties": {
         "accessPolicies": [
           {
-            "objectId": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]",
+            "objectId": {
+              "_unknown": "[reference(resourceId('Microsoft.Compute/virtualMachines/', parameters('vmName')), '2020-12-01', 'full').identity.principalId]"
+            },
             "permissions": {
               "secrets": [
                 "get"
               ]
             },
-            "tenantId": "[subscription().tenantId]"
+            "tenantId": {
+              "_unknown": "[subscription().tenantId]"
+            }
           }
         ],
         "sku": {
           "family": "A",
           "name": "standard"
         },
-        "tenantId": "[subscription().tenantId]"
+        "tenantId": {
+          "_unknown": "[subscription().tenantId]"
+        }
       },
       "type": "Microsoft.KeyVault/vaults"
     }
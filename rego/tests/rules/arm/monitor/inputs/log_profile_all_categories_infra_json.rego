@@ -43,7 +43,9 @@ mock_config := {
         "retentionPolicy": {
           "enabled": false
         },
-        "storageAccountId": "[parameters('storageAccountId')]"
+        "storageAccountId": {
+          "_unknown": "[parameters('storageAccountId')]"
+        }
       },
       "type": "Microsoft.Insights/logprofiles"
     },
@@ -61,7 +63,9 @@ mock_config := {
         "retentionPolicy": {
           "enabled": false
         },
-        "storageAccountId": "[parameters('storageAccountId')]"
+        "storageAccountId": {
+          "_unknown": "[parameters('storageAccountId')]"
+        }
       },
       "type": "Microsoft.Insights/logprofiles"
     },
@@ -79,7 +83,9 @@ mock_config := {
         "retentionPolicy": {
           "enabled": false
         },
-        "storageAccountId": "[parameters('storageAccountId')]"
+        "storageAccountId": {
+          "_unknown": "[parameters('storageAccountId')]"
+        }
       },
       "type": "Microsoft.Insights/logprofiles"
     }
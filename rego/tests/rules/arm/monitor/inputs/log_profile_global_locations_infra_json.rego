@@ -39,7 +39,9 @@ mock_config := {
         "retentionPolicy": {
           "enabled": false
         },
-        "storageAccountId": "[parameters('storageAccountId')]"
+        "storageAccountId": {
+          "_unknown": "[parameters('storageAccountId')]"
+        }
       },
       "type": "Microsoft.Insights/logprofiles"
     },
@@ -56,7 +58,9 @@ mock_config := {
         "retentionPolicy": {
           "enabled": false
         },
-        "storageAccountId": "[parameters('storageAccountId')]"
+        "storageAccountId": {
+          "_unknown": "[parameters('storageAccountId')]"
+        }
       },
       "type": "Microsoft.Insights/logprofiles"
     },
@@ -75,7 +79,9 @@ mock_config := {
         "retentionPolicy": {
           "enabled": false
         },
-        "storageAccountId": "[parameters('storageAccountId')]"
+        "storageAccountId": {
+          "_unknown": "[parameters('storageAccountId')]"
+        }
       },
       "type": "Microsoft.Insights/logprofiles"
     }
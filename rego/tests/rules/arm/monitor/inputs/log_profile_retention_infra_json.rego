@@ -44,7 +44,9 @@ mock_config := {
           "days": 365,
           "enabled": true
         },
-        "storageAccountId": "[parameters('storageAccountId')]"
+        "storageAccountId": {
+          "_unknown": "[parameters('storageAccountId')]"
+        }
       },
       "type": "Microsoft.Insights/logprofiles"
     },
@@ -64,7 +66,9 @@ mock_config := {
           "days": 0,
           "enabled": true
         },
-        "storageAccountId": "[parameters('storageAccountId')]"
+        "storageAccountId": {
+          "_unknown": "[parameters('storageAccountId')]"
+        }
       },
       "type": "Microsoft.Insights/logprofiles"
     },
@@ -84,7 +88,9 @@ mock_config := {
           "days": 0,
           "enabled": false
         },
-        "storageAccountId": "[parameters('storageAccountId')]"
+        "storageAccountId": {
+          "_unknown": "[parameters('storageAccountId')]"
+        }
       },
       "type": "Microsoft.Insights/logprofiles"
     },
@@ -104,7 +110,9 @@ mock_config := {
           "days": 90,
           "enabled": true
         },
-        "storageAccountId": "[parameters('storageAccountId')]"
+        "storageAccountId": {
+          "_unknown": "[parameters('storageAccountId')]"
+        }
       },
       "type": "Microsoft.Insights/logprofiles"
     }
@@ -38,32 +38,6 @@ mock_config := {
         },
         "version": "5.7"
       },
-      "resources": [
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforMySQL/servers/RegulaServer1"
-          ],
-          "name": "Rule1",
-          "properties": {
-            "endIpAddress": "0.0.0.0",
-            "startIpAddress": "0.0.0.0"
-          },
-          "type": "firewallRules"
-        },
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforMySQL/servers/RegulaServer1"
-          ],
-          "name": "Rule2",
-          "properties": {
-            "endIpAddress": "10.0.255.0",
-            "startIpAddress": "10.0.0.0"
-          },
-          "type": "firewallRules"
-        }
-      ],
       "sku": {
         "capacity": "2",
         "family": "Gen5",
@@ -71,6 +45,30 @@ mock_config := {
         "tier": "Basic"
       },
       "type": "Microsoft.DBforMySQL/servers"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforMySQL/servers/RegulaServer1"
+      ],
+      "name": "RegulaServer1/Rule1",
+      "properties": {
+        "endIpAddress": "0.0.0.0",
+        "startIpAddress": "0.0.0.0"
+      },
+      "type": "Microsoft.DBforMySQL/servers/firewallRules"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforMySQL/servers/RegulaServer1"
+      ],
+      "name": "RegulaServer1/Rule2",
+      "properties": {
+        "endIpAddress": "10.0.255.0",
+        "startIpAddress": "10.0.0.0"
+      },
+      "type": "Microsoft.DBforMySQL/servers/firewallRules"
     }
   ]
 }
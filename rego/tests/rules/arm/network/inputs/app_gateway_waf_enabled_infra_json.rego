@@ -35,21 +35,19 @@ mock_config := {
           ]
         }
       },
-      "resources": [
-        {
-          "apiVersion": "2018-10-01",
-          "dependsOn": [
-            "RegulaNet1"
-          ],
-          "name": "Subnet1",
-          "properties": {
-            "addressPrefix": "10.0.0.0/24"
-          },
-          "type": "subnets"
-        }
-      ],
       "type": "Microsoft.Network/virtualNetworks"
     },
+    {
+      "apiVersion": "2018-10-01",
+      "dependsOn": [
+        "RegulaNet1"
+      ],
+      "name": "RegulaNet1/Subnet1",
+      "properties": {
+        "addressPrefix": "10.0.0.0/24"
+      },
+      "type": "Microsoft.Network/virtualNetworks/subnets"
+    },
     {
       "apiVersion": "2021-03-01",
       "dependsOn": [
@@ -77,7 +75,9 @@ mock_config := {
             "name": "FIPC1",
             "properties": {
               "subnet": {
-                "id": "[concat(resourceId('Microsoft.Network/virtualNetworks', 'RegulaNet1'), '/subnets/Subnet1')]"
+                "id": {
+                  "_unknown": "[concat(resourceId('Microsoft.Network/virtualNetworks', 'RegulaNet1'), '/subnets/Subnet1')]"
+                }
               }
             }
           }
@@ -95,7 +95,9 @@ mock_config := {
             "name": "IPC1",
             "properties": {
               "subnet": {
-                "id": "[concat(resourceId('Microsoft.Network/virtualNetworks', 'RegulaNet1'), '/subnets/Subnet1')]"
+                "id": {
+                  "_unknown": "[concat(resourceId('Microsoft.Network/virtualNetworks', 'RegulaNet1'), '/subnets/Subnet1')]"
+                }
               }
             }
           }
@@ -105,10 +107,14 @@ mock_config := {
             "name": "HL1",
             "properties": {
               "frontendIPConfiguration": {
-                "id": "[resourceId('Microsoft.Network/applicationGateways/frontendIPConfigurations', 'RegulaAG1', 'FIPC1')]"
+                "id": {
+                  "_unknown": "[resourceId('Microsoft.Network/applicationGateways/frontendIPConfigurations', 'RegulaAG1', 'FIPC1')]"
+                }
               },
               "frontendPort": {
-                "id": "[resourceId('Microsoft.Network/applicationGateways/frontendPorts', 'RegulaAG1', 'FP1')]"
+                "id": {
+                  "_unknown": "[resourceId('Microsoft.Network/applicationGateways/frontendPorts', 'RegulaAG1', 'FP1')]"
+                }
               }
             }
           }
@@ -118,13 +124,19 @@ mock_config := {
             "name": "RRR1",
             "properties": {
               "backendAddressPool": {
-                "id": "[resourceId('Microsoft.Network/applicationGateways/backendAddressPools', 'RegulaAG1', 'BAP1')]"
+                "id": {
+                  "_unknown": "[resourceId('Microsoft.Network/applicationGateways/backendAddressPools', 'RegulaAG1', 'BAP1')]"
+                }
               },
               "backendHttpSettings": {
-                "id": "[resourceId('Microsoft.Network/applicationGateways/backendHttpSettingsCollection', 'RegulaAG1', 'BHSC1')]"
+                "id": {
+                  "_unknown": "[resourceId('Microsoft.Network/applicationGateways/backendHttpSettingsCollection', 'RegulaAG1', 'BHSC1')]"
+                }
               },
               "httpListener": {
-                "id": "[resourceId('Microsoft.Network/applicationGateways/httpListeners', 'RegulaAG1', 'HL1')]"
+                "id": {
+                  "_unknown": "[resourceId('Microsoft.Network/applicationGateways/httpListeners', 'RegulaAG1', 'HL1')]"
+                }
               }
             }
           }
@@ -164,7 +176,9 @@ mock_config := {
             "name": "FIPC1",
             "properties": {
               "subnet": {
-                "id": "[concat(resourceId('Microsoft.Network/virtualNetworks', 'RegulaNet1'), '/subnets/Subnet1')]"
+                "id": {
+                  "_unknown": "[concat(resourceId('Microsoft.Network/virtualNetworks', 'RegulaNet1'), '/subnets/Subnet1')]"
+                }
               }
             }
           }
@@ -182,7 +196,9 @@ mock_config := {
             "name": "IPC1",
             "properties": {
               "subnet": {
-                "id": "[concat(resourceId('Microsoft.Network/virtualNetworks', 'RegulaNet1'), '/subnets/Subnet1')]"
+                "id": {
+                  "_unknown": "[concat(resourceId('Microsoft.Network/virtualNetworks', 'RegulaNet1'), '/subnets/Subnet1')]"
+                }
               }
             }
           }
@@ -192,10 +208,14 @@ mock_config := {
             "name": "HL1",
             "properties": {
               "frontendIPConfiguration": {
-                "id": "[resourceId('Microsoft.Network/applicationGateways/frontendIPConfigurations', 'RegulaAG2', 'FIPC1')]"
+                "id": {
+                  "_unknown": "[resourceId('Microsoft.Network/applicationGateways/frontendIPConfigurations', 'RegulaAG2', 'FIPC1')]"
+                }
               },
               "frontendPort": {
-                "id": "[resourceId('Microsoft.Network/applicationGateways/frontendPorts', 'RegulaAG2', 'FP1')]"
+                "id": {
+                  "_unknown": "[resourceId('Microsoft.Network/applicationGateways/frontendPorts', 'RegulaAG2', 'FP1')]"
+                }
               }
             }
           }
@@ -205,13 +225,19 @@ mock_config := {
             "name": "RRR1",
             "properties": {
               "backendAddressPool": {
-                "id": "[resourceId('Microsoft.Network/applicationGateways/backendAddressPools', 'RegulaAG2', 'BAP1')]"
+                "id": {
+                  "_unknown": "[resourceId('Microsoft.Network/applicationGateways/backendAddressPools', 'RegulaAG2', 'BAP1')]"
+                }
               },
               "backendHttpSettings": {
-                "id": "[resourceId('Microsoft.Network/applicationGateways/backendHttpSettingsCollection', 'RegulaAG2', 'BHSC1')]"
+                "id": {
+                  "_unknown": "[resourceId('Microsoft.Network/applicationGateways/backendHttpSettingsCollection', 'RegulaAG2', 'BHSC1')]"
+                }
               },
               "httpListener": {
-                "id": "[resourceId('Microsoft.Network/applicationGateways/httpListeners', 'RegulaAG2', 'HL1')]"
+                "id": {
+                  "_unknown": "[resourceId('Microsoft.Network/applicationGateways/httpListeners', 'RegulaAG2', 'HL1')]"
+                }
               }
             }
           }
@@ -56,45 +56,43 @@ mock_config := {
       "apiVersion": "2021-04-01",
       "location": "switzerlandnorth",
       "name": "RegulaWatcher1",
-      "resources": [
-        {
-          "apiVersion": "2021-04-01",
-          "dependsOn": [
-            "Microsoft.Network/networkWatchers/RegulaWatcher1",
-            "Microsoft.Storage/storageAccounts/regulasa01"
-          ],
-          "location": "switzerlandnorth",
-          "name": "FL1",
-          "properties": {
-            "retentionPolicy": {
-              "days": 90,
-              "enabled": true
-            },
-            "storageId": "[resourceId('Microsoft.Storage/storageAccounts', 'regulasa01')]",
-            "targetResourceId": "[resourceId('Microsoft.Network/networkSecurityGroups', 'RegulaNSG1')]"
-          },
-          "type": "flowLogs"
+      "type": "Microsoft.Network/networkWatchers"
+    },
+    {
+      "apiVersion": "2021-04-01",
+      "dependsOn": [
+        "Microsoft.Network/networkWatchers/RegulaWatcher1",
+        "Microsoft.Storage/storageAccounts/regulasa01"
+      ],
+      "location": "switzerlandnorth",
+      "name": "RegulaWatcher1/FL1",
+      "properties": {
+        "retentionPolicy": {
+          "days": 90,
+          "enabled": true
         },
-        {
-          "apiVersion": "2021-04-01",
-          "dependsOn": [
-            "Microsoft.Network/networkWatchers/RegulaWatcher1",
-            "Microsoft.Storage/storageAccounts/regulasa01"
-          ],
-          "location": "switzerlandnorth",
-          "name": "FL2",
-          "properties": {
-            "retentionPolicy": {
-              "days": 70,
-              "enabled": true
-            },
-            "storageId": "[resourceId('Microsoft.Storage/storageAccounts', 'regulasa01')]",
-            "targetResourceId": "[resourceId('Microsoft.Network/networkSecurityGroups', 'RegulaNSG2')]"
-          },
-          "type": "flowLogs"
-        }
+        "storageId": "Microsoft.Storage/storageAccounts/regulasa01",
+        "targetResourceId": "Microsoft.Network/networkSecurityGroups/RegulaNSG1"
+      },
+      "type": "Microsoft.Network/networkWatchers/flowLogs"
+    },
+    {
+      "apiVersion": "2021-04-01",
+      "dependsOn": [
+        "Microsoft.Network/networkWatchers/RegulaWatcher1",
+        "Microsoft.Storage/storageAccounts/regulasa01"
       ],
-      "type": "Microsoft.Network/networkWatchers"
+      "location": "switzerlandnorth",
+      "name": "RegulaWatcher1/FL2",
+      "properties": {
+        "retentionPolicy": {
+          "days": 70,
+          "enabled": true
+        },
+        "storageId": "Microsoft.Storage/storageAccounts/regulasa01",
+        "targetResourceId": "Microsoft.Network/networkSecurityGroups/RegulaNSG2"
+      },
+      "type": "Microsoft.Network/networkWatchers/flowLogs"
     }
   ]
 }
@@ -32,76 +32,74 @@ mock_config := {
         "administratorLogin": "foo",
         "administratorLoginPassword": "abcd1234!"
       },
-      "resources": [
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigValid"
-          ],
-          "name": "log_checkpoints",
-          "properties": {
-            "value": "on"
-          },
-          "type": "configurations"
-        },
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigValid"
-          ],
-          "name": "log_connections",
-          "properties": {
-            "value": "on"
-          },
-          "type": "configurations"
-        },
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigValid"
-          ],
-          "name": "log_disconnections",
-          "properties": {
-            "value": "on"
-          },
-          "type": "configurations"
-        },
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigValid"
-          ],
-          "name": "log_duration",
-          "properties": {
-            "value": "on"
-          },
-          "type": "configurations"
-        },
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigValid"
-          ],
-          "name": "connection_throttling",
-          "properties": {
-            "value": "on"
-          },
-          "type": "configurations"
-        },
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigValid"
-          ],
-          "name": "log_retention_days",
-          "properties": {
-            "value": "7"
-          },
-          "type": "configurations"
-        }
-      ],
       "type": "Microsoft.DBforPostgreSQL/servers"
     },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigValid"
+      ],
+      "name": "RegulaServerConfigValid/log_checkpoints",
+      "properties": {
+        "value": "on"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/configurations"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigValid"
+      ],
+      "name": "RegulaServerConfigValid/log_connections",
+      "properties": {
+        "value": "on"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/configurations"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigValid"
+      ],
+      "name": "RegulaServerConfigValid/log_disconnections",
+      "properties": {
+        "value": "on"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/configurations"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigValid"
+      ],
+      "name": "RegulaServerConfigValid/log_duration",
+      "properties": {
+        "value": "on"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/configurations"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigValid"
+      ],
+      "name": "RegulaServerConfigValid/connection_throttling",
+      "properties": {
+        "value": "on"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/configurations"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigValid"
+      ],
+      "name": "RegulaServerConfigValid/log_retention_days",
+      "properties": {
+        "value": "7"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/configurations"
+    },
     {
       "apiVersion": "2017-12-01",
       "location": "switzerlandnorth",
@@ -110,76 +108,74 @@ mock_config := {
         "administratorLogin": "foo",
         "administratorLoginPassword": "abcd1234!"
       },
-      "resources": [
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigInvalid"
-          ],
-          "name": "log_checkpoints",
-          "properties": {
-            "value": "off"
-          },
-          "type": "configurations"
-        },
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigInvalid"
-          ],
-          "name": "log_connections",
-          "properties": {
-            "value": "off"
-          },
-          "type": "configurations"
-        },
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigInvalid"
-          ],
-          "name": "log_disconnections",
-          "properties": {
-            "value": "off"
-          },
-          "type": "configurations"
-        },
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigInvalid"
-          ],
-          "name": "log_duration",
-          "properties": {
-            "value": "off"
-          },
-          "type": "configurations"
-        },
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigInvalid"
-          ],
-          "name": "connection_throttling",
-          "properties": {
-            "value": "off"
-          },
-          "type": "configurations"
-        },
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigInvalid"
-          ],
-          "name": "log_retention_days",
-          "properties": {
-            "value": "1"
-          },
-          "type": "configurations"
-        }
-      ],
       "type": "Microsoft.DBforPostgreSQL/servers"
     },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigInvalid"
+      ],
+      "name": "RegulaServerConfigInvalid/log_checkpoints",
+      "properties": {
+        "value": "off"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/configurations"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigInvalid"
+      ],
+      "name": "RegulaServerConfigInvalid/log_connections",
+      "properties": {
+        "value": "off"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/configurations"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigInvalid"
+      ],
+      "name": "RegulaServerConfigInvalid/log_disconnections",
+      "properties": {
+        "value": "off"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/configurations"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigInvalid"
+      ],
+      "name": "RegulaServerConfigInvalid/log_duration",
+      "properties": {
+        "value": "off"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/configurations"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigInvalid"
+      ],
+      "name": "RegulaServerConfigInvalid/connection_throttling",
+      "properties": {
+        "value": "off"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/configurations"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServerConfigInvalid"
+      ],
+      "name": "RegulaServerConfigInvalid/log_retention_days",
+      "properties": {
+        "value": "1"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/configurations"
+    },
     {
       "apiVersion": "2017-12-01",
       "location": "switzerlandnorth",
@@ -32,33 +32,31 @@ mock_config := {
         "administratorLogin": "foo",
         "administratorLoginPassword": "abcd1234!"
       },
-      "resources": [
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServer1"
-          ],
-          "name": "Rule1",
-          "properties": {
-            "endIpAddress": "0.0.0.0",
-            "startIpAddress": "0.0.0.0"
-          },
-          "type": "firewallRules"
-        },
-        {
-          "apiVersion": "2017-12-01",
-          "dependsOn": [
-            "Microsoft.DBforPostgreSQL/servers/RegulaServer1"
-          ],
-          "name": "Rule2",
-          "properties": {
-            "endIpAddress": "10.0.255.0",
-            "startIpAddress": "10.0.0.0"
-          },
-          "type": "firewallRules"
-        }
-      ],
       "type": "Microsoft.DBforPostgreSQL/servers"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServer1"
+      ],
+      "name": "RegulaServer1/Rule1",
+      "properties": {
+        "endIpAddress": "0.0.0.0",
+        "startIpAddress": "0.0.0.0"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/firewallRules"
+    },
+    {
+      "apiVersion": "2017-12-01",
+      "dependsOn": [
+        "Microsoft.DBforPostgreSQL/servers/RegulaServer1"
+      ],
+      "name": "RegulaServer1/Rule2",
+      "properties": {
+        "endIpAddress": "10.0.255.0",
+        "startIpAddress": "10.0.0.0"
+      },
+      "type": "Microsoft.DBforPostgreSQL/servers/firewallRules"
     }
   ]
 }
@@ -26,32 +26,34 @@ mock_config := {
   "resources": [
     {
       "apiVersion": "2014-04-01",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "regulaserver1",
       "properties": {
         "administratorLogin": "sysadmin",
         "administratorLoginPassword": "abcd1234!"
       },
-      "resources": [
-        {
-          "apiVersion": "2021-05-01-preview",
-          "dependsOn": [
-            "Microsoft.Sql/servers/regulaserver1"
-          ],
-          "name": "as1",
-          "properties": {
-            "isAzureMonitorTargetEnabled": true,
-            "retentionDays": 90,
-            "state": "Enabled"
-          },
-          "type": "auditingSettings"
-        }
-      ],
       "type": "Microsoft.Sql/servers"
     },
+    {
+      "apiVersion": "2021-05-01-preview",
+      "dependsOn": [
+        "Microsoft.Sql/servers/regulaserver1"
+      ],
+      "name": "regulaserver1/as1",
+      "properties": {
+        "isAzureMonitorTargetEnabled": true,
+        "retentionDays": 90,
+        "state": "Enabled"
+      },
+      "type": "Microsoft.Sql/servers/auditingSettings"
+    },
     {
       "apiVersion": "2014-04-01",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "regulaserver2",
       "properties": {
         "administratorLogin": "sysadmin",
@@ -61,28 +63,28 @@ mock_config := {
     },
     {
       "apiVersion": "2014-04-01",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "regulaserver3",
       "properties": {
         "administratorLogin": "sysadmin",
         "administratorLoginPassword": "abcd1234!"
       },
-      "resources": [
-        {
-          "apiVersion": "2021-05-01-preview",
-          "dependsOn": [
-            "Microsoft.Sql/servers/regulaserver3"
-          ],
-          "name": "as1",
-          "properties": {
-            "isAzureMonitorTargetEnabled": true,
-            "retentionDays": 3,
-            "state": "Enabled"
-          },
-          "type": "auditingSettings"
-        }
-      ],
       "type": "Microsoft.Sql/servers"
+    },
+    {
+      "apiVersion": "2021-05-01-preview",
+      "dependsOn": [
+        "Microsoft.Sql/servers/regulaserver3"
+      ],
+      "name": "regulaserver3/as1",
+      "properties": {
+        "isAzureMonitorTargetEnabled": true,
+        "retentionDays": 3,
+        "state": "Enabled"
+      },
+      "type": "Microsoft.Sql/servers/auditingSettings"
     }
   ]
 }
@@ -27,7 +27,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "kind": "Storage",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "valid",
       "properties": {
         "networkAcls": {
@@ -42,7 +44,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "kind": "Storage",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalidallow",
       "properties": {
         "networkAcls": {
@@ -57,7 +61,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "kind": "Storage",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalidunset",
       "properties": {},
       "sku": {
@@ -52,7 +52,7 @@ mock_config := {
       ],
       "name": "setting1",
       "properties": {
-        "storageAccountId": "[resourceId('Microsoft.Storage/storageAccounts', 'regulalogstorage1')]"
+        "storageAccountId": "Microsoft.Storage/storageAccounts/regulalogstorage1"
       },
       "scope": "Microsoft.Storage/storageAccounts/regulastorage1",
       "type": "Microsoft.Insights/diagnosticSettings"
@@ -79,7 +79,7 @@ mock_config := {
             "enabled": false
           }
         ],
-        "storageAccountId": "[resourceId('Microsoft.Storage/storageAccounts', 'regulalogstorage1')]"
+        "storageAccountId": "Microsoft.Storage/storageAccounts/regulalogstorage1"
       },
       "type": "Microsoft.Storage/storageAccounts/queueServices/providers/diagnosticSettings"
     },
@@ -111,7 +111,7 @@ mock_config := {
       ],
       "name": "setting2",
       "properties": {
-        "storageAccountId": "[resourceId('Microsoft.Storage/storageAccounts', 'regulalogstorage2')]"
+        "storageAccountId": "Microsoft.Storage/storageAccounts/regulalogstorage2"
       },
       "scope": "Microsoft.Storage/storageAccounts/regulastorage2",
       "type": "Microsoft.Insights/diagnosticSettings"
@@ -138,7 +138,7 @@ mock_config := {
             "enabled": true
           }
         ],
-        "storageAccountId": "[resourceId('Microsoft.Storage/storageAccounts', 'regulalogstorage2')]"
+        "storageAccountId": "Microsoft.Storage/storageAccounts/regulalogstorage2"
       },
       "type": "Microsoft.Storage/storageAccounts/queueServices/providers/diagnosticSettings"
     }
@@ -27,7 +27,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "kind": "Storage",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "valid",
       "properties": {
         "supportsHttpsTrafficOnly": true
@@ -40,7 +42,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "kind": "Storage",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalid",
       "properties": {
         "supportsHttpsTrafficOnly": false
@@ -53,7 +57,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "kind": "Storage",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "validdefault",
       "properties": {},
       "sku": {
@@ -64,7 +70,9 @@ mock_config := {
     {
       "apiVersion": "2018-11-01",
       "kind": "Storage",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "validolder",
       "properties": {
         "supportsHttpsTrafficOnly": true
@@ -77,7 +85,9 @@ mock_config := {
     {
       "apiVersion": "2018-11-01",
       "kind": "Storage",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invaliddefault",
       "properties": {},
       "sku": {
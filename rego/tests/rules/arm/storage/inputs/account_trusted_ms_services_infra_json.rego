@@ -27,7 +27,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "kind": "Storage",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "valid",
       "properties": {
         "networkAcls": {
@@ -43,7 +45,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "kind": "Storage",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "validmulti",
       "properties": {
         "networkAcls": {
@@ -59,7 +63,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "kind": "Storage",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "validmulti2",
       "properties": {
         "networkAcls": {
@@ -75,7 +81,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "kind": "Storage",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalid",
       "properties": {
         "networkAcls": {
@@ -91,7 +99,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "kind": "Storage",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "invalidunset",
       "properties": {
         "networkAcls": {
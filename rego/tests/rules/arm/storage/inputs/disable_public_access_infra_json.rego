@@ -27,48 +27,48 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "kind": "StorageV2",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "storage",
       "properties": {
         "accessTier": "Hot"
       },
-      "resources": [
-        {
-          "apiVersion": "2021-04-01",
-          "dependsOn": [
-            "storage"
-          ],
-          "name": "default/valid",
-          "properties": {
-            "publicAccess": "None"
-          },
-          "type": "blobServices/containers"
-        },
-        {
-          "apiVersion": "2021-04-01",
-          "dependsOn": [
-            "storage"
-          ],
-          "name": "default/validUnset",
-          "properties": {},
-          "type": "blobServices/containers"
-        },
-        {
-          "apiVersion": "2021-04-01",
-          "dependsOn": [
-            "storage"
-          ],
-          "name": "default/invalid",
-          "properties": {
-            "publicAccess": "Blob"
-          },
-          "type": "blobServices/containers"
-        }
-      ],
       "sku": {
         "name": "Standard_LRS"
       },
       "type": "Microsoft.Storage/storageAccounts"
+    },
+    {
+      "apiVersion": "2021-04-01",
+      "dependsOn": [
+        "storage"
+      ],
+      "name": "storage/default/valid",
+      "properties": {
+        "publicAccess": "None"
+      },
+      "type": "Microsoft.Storage/storageAccounts/blobServices/containers"
+    },
+    {
+      "apiVersion": "2021-04-01",
+      "dependsOn": [
+        "storage"
+      ],
+      "name": "storage/default/validUnset",
+      "properties": {},
+      "type": "Microsoft.Storage/storageAccounts/blobServices/containers"
+    },
+    {
+      "apiVersion": "2021-04-01",
+      "dependsOn": [
+        "storage"
+      ],
+      "name": "storage/default/invalid",
+      "properties": {
+        "publicAccess": "Blob"
+      },
+      "type": "Microsoft.Storage/storageAccounts/blobServices/containers"
     }
   ]
 }
@@ -26,12 +26,16 @@ mock_config := {
   "resources": [
     {
       "apiVersion": "2021-06-01-preview",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "regulavault3",
       "properties": {
         "accessPolicies": [
           {
-            "objectId": "[subscription().subscriptionId]",
+            "objectId": {
+              "_unknown": "[subscription().subscriptionId]"
+            },
             "permissions": {
               "certificates": [],
               "keys": [
@@ -41,7 +45,9 @@ mock_config := {
               ],
               "secrets": []
             },
-            "tenantId": "[subscription().tenantId]"
+            "tenantId": {
+              "_unknown": "[subscription().tenantId]"
+            }
           }
         ],
         "enablePurgeProtection": true,
@@ -50,35 +56,35 @@ mock_config := {
           "family": "A",
           "name": "Standard"
         },
-        "tenantId": "[subscription().tenantId]"
-      },
-      "resources": [
-        {
-          "apiVersion": "2021-06-01-preview",
-          "dependsOn": [
-            "Microsoft.KeyVault/vaults/regulavault3"
-          ],
-          "name": "key1",
-          "properties": {
-            "keySize": 4096,
-            "kty": "RSA"
-          },
-          "type": "keys"
-        },
-        {
-          "apiVersion": "2021-06-01-preview",
-          "dependsOn": [
-            "Microsoft.KeyVault/vaults/regulavault3"
-          ],
-          "name": "secret1",
-          "properties": {
-            "value": "hunter2"
-          },
-          "type": "secrets"
+        "tenantId": {
+          "_unknown": "[subscription().tenantId]"
         }
-      ],
+      },
       "type": "Microsoft.KeyVault/vaults"
     },
+    {
+      "apiVersion": "2021-06-01-preview",
+      "dependsOn": [
+        "Microsoft.KeyVault/vaults/regulavault3"
+      ],
+      "name": "regulavault3/key1",
+      "properties": {
+        "keySize": 4096,
+        "kty": "RSA"
+      },
+      "type": "Microsoft.KeyVault/vaults/keys"
+    },
+    {
+      "apiVersion": "2021-06-01-preview",
+      "dependsOn": [
+        "Microsoft.KeyVault/vaults/regulavault3"
+      ],
+      "name": "regulavault3/secret1",
+      "properties": {
+        "value": "hunter2"
+      },
+      "type": "Microsoft.KeyVault/vaults/secrets"
+    },
     {
       "apiVersion": "2021-06-01-preview",
       "dependsOn": [
@@ -89,7 +95,9 @@ mock_config := {
       "properties": {
         "accessPolicies": [
           {
-            "objectId": "[reference(resourceId('Microsoft.Compute/diskEncryptionSets', 'regulades1'), '2021-04-01', 'Full').identity.PrincipalId]",
+            "objectId": {
+              "_unknown": "[reference(resourceId('Microsoft.Compute/diskEncryptionSets', 'regulades1'), '2021-04-01', 'Full').identity.PrincipalId]"
+            },
             "permissions": {
               "certificates": [],
               "keys": [
@@ -99,7 +107,9 @@ mock_config := {
               ],
               "secrets": []
             },
-            "tenantId": "[subscription().tenantId]"
+            "tenantId": {
+              "_unknown": "[subscription().tenantId]"
+            }
           }
         ]
       },
@@ -113,13 +123,17 @@ mock_config := {
       "identity": {
         "type": "SystemAssigned"
       },
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "regulades1",
       "properties": {
         "activeKey": {
-          "keyUrl": "[reference(resourceId('Microsoft.KeyVault/vaults/keys', 'regulavault3', 'key1'), '2021-06-01-preview', 'Full').properties.keyUriWithVersion]",
+          "keyUrl": {
+            "_unknown": "[reference(resourceId('Microsoft.KeyVault/vaults/keys', 'regulavault3', 'key1'), '2021-06-01-preview', 'Full').properties.keyUriWithVersion]"
+          },
           "sourceVault": {
-            "id": "[resourceId('Microsoft.KeyVault/vaults', 'regulavault3')]"
+            "id": "Microsoft.KeyVault/vaults/regulavault3"
           }
         }
       },
@@ -131,7 +145,9 @@ mock_config := {
       "dependsOn": [
         "Microsoft.KeyVault/vaults/regulavault3/secrets/secret1"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "reguladisk1",
       "properties": {
         "creationData": {
@@ -143,9 +159,11 @@ mock_config := {
           "encryptionSettings": [
             {
               "diskEncryptionKey": {
-                "secretUrl": "[reference(resourceId('Microsoft.KeyVault/vaults/secrets', 'regulavault3', 'secret1'), '2021-06-01-preview', 'Full').properties.secretUriWithVersion]",
+                "secretUrl": {
+                  "_unknown": "[reference(resourceId('Microsoft.KeyVault/vaults/secrets', 'regulavault3', 'secret1'), '2021-06-01-preview', 'Full').properties.secretUriWithVersion]"
+                },
                 "sourceVault": {
-                  "id": "[resourceId('Microsoft.KeyVault/vaults', 'regulavault3')]"
+                  "id": "Microsoft.KeyVault/vaults/regulavault3"
                 }
               }
             }
@@ -161,7 +179,9 @@ mock_config := {
         "Microsoft.Compute/diskEncryptionSets/regulades1",
         "Microsoft.KeyVault/vaults/regulavault3/accessPolicies/add"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "reguladisk2",
       "properties": {
         "creationData": {
@@ -169,7 +189,7 @@ mock_config := {
         },
         "diskSizeGB": 2,
         "encryption": {
-          "diskEncryptionSetId": "[resourceId('Microsoft.Compute/diskEncryptionSets', 'regulades1')]"
+          "diskEncryptionSetId": "Microsoft.Compute/diskEncryptionSets/regulades1"
         }
       },
       "type": "Microsoft.Compute/disks"
@@ -177,7 +197,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "comments": "Unencrypted, attached as OS disk",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "reguladisk3",
       "properties": {
         "creationData": {
@@ -190,7 +212,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "comments": "Unencrypted, attached as data disk",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "reguladisk4",
       "properties": {
         "creationData": {
@@ -203,7 +227,9 @@ mock_config := {
     {
       "apiVersion": "2021-04-01",
       "comments": "Unencrypted, unattached",
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "reguladisk5",
       "properties": {
         "creationData": {
@@ -239,7 +265,9 @@ mock_config := {
       "dependsOn": [
         "Microsoft.Network/virtualNetworks/regulanet1"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "regulanic1",
       "properties": {
         "ipConfigurations": [
@@ -248,7 +276,9 @@ mock_config := {
             "properties": {
               "privateIPAllocationMethod": "Dynamic",
               "subnet": {
-                "id": "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'regulanet1', 'subnet1')]"
+                "id": {
+                  "_unknown": "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'regulanet1', 'subnet1')]"
+                }
               }
             }
           }
@@ -261,7 +291,9 @@ mock_config := {
       "dependsOn": [
         "Microsoft.Network/virtualNetworks/regulanet1"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "regulanic2",
       "properties": {
         "ipConfigurations": [
@@ -270,7 +302,9 @@ mock_config := {
             "properties": {
               "privateIPAllocationMethod": "Dynamic",
               "subnet": {
-                "id": "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'regulanet1', 'subnet1')]"
+                "id": {
+                  "_unknown": "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'regulanet1', 'subnet1')]"
+                }
               }
             }
           }
@@ -283,7 +317,9 @@ mock_config := {
       "dependsOn": [
         "Microsoft.Network/virtualNetworks/regulanet1"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "regulanic3",
       "properties": {
         "ipConfigurations": [
@@ -292,7 +328,9 @@ mock_config := {
             "properties": {
               "privateIPAllocationMethod": "Dynamic",
               "subnet": {
-                "id": "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'regulanet1', 'subnet1')]"
+                "id": {
+                  "_unknown": "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'regulanet1', 'subnet1')]"
+                }
               }
             }
           }
@@ -309,7 +347,9 @@ mock_config := {
         "Microsoft.Compute/diskEncryptionSets/regulades1",
         "Microsoft.KeyVault/vaults/regulavault3/accessPolicies/add"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "regulavm1",
       "properties": {
         "hardwareProfile": {
@@ -318,7 +358,7 @@ mock_config := {
         "networkProfile": {
           "networkInterfaces": [
             {
-              "id": "[resourceId('Microsoft.Network/networkInterfaces', 'regulanic1')]",
+              "id": "Microsoft.Network/networkInterfaces/regulanic1",
               "properties": {
                 "deleteOption": "Detach"
               }
@@ -337,16 +377,16 @@ mock_config := {
               "lun": 0,
               "managedDisk": {
                 "diskEncryptionSet": {
-                  "id": "[resourceId('Microsoft.Compute/diskEncryptionSets', 'regulades1')]"
+                  "id": "Microsoft.Compute/diskEncryptionSets/regulades1"
                 },
-                "id": "[resourceId('Microsoft.Compute/disks', 'reguladisk2')]"
+                "id": "Microsoft.Compute/disks/reguladisk2"
               }
             },
             {
               "createOption": "Attach",
               "lun": 1,
               "managedDisk": {
-                "id": "[resourceId('Microsoft.Compute/disks', 'reguladisk4')]"
+                "id": "Microsoft.Compute/disks/reguladisk4"
               }
             }
           ],
@@ -366,7 +406,9 @@ mock_config := {
         "Microsoft.Network/networkInterfaces/regulanic2",
         "Microsoft.Compute/disks/reguladisk3"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "regulavm2",
       "properties": {
         "hardwareProfile": {
@@ -375,7 +417,7 @@ mock_config := {
         "networkProfile": {
           "networkInterfaces": [
             {
-              "id": "[resourceId('Microsoft.Network/networkInterfaces', 'regulanic2')]",
+              "id": "Microsoft.Network/networkInterfaces/regulanic2",
               "properties": {
                 "deleteOption": "Detach"
               }
@@ -387,7 +429,7 @@ mock_config := {
           "osDisk": {
             "createOption": "Attach",
             "managedDisk": {
-              "id": "[resourceId('Microsoft.Compute/disks', 'reguladisk3')]"
+              "id": "Microsoft.Compute/disks/reguladisk3"
             },
             "osType": "Linux"
           }
@@ -400,7 +442,9 @@ mock_config := {
       "dependsOn": [
         "Microsoft.Network/networkInterfaces/regulanic3"
       ],
-      "location": "[resourceGroup().location]",
+      "location": {
+        "_unknown": "[resourceGroup().location]"
+      },
       "name": "regulavm3",
       "properties": {
         "hardwareProfile": {
@@ -409,7 +453,7 @@ mock_config := {
         "networkProfile": {
           "networkInterfaces": [
             {
-              "id": "[resourceId('Microsoft.Network/networkInterfaces', 'regulanic3')]",
+              "id": "Microsoft.Network/networkInterfaces/regulanic3",
               "properties": {
                 "deleteOption": "Detach"
               }
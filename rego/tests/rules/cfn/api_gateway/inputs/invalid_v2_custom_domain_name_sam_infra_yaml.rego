@@ -25,22 +25,82 @@ mock_config := {
   "Resources": {
     "ServerlessAPI": {
       "Properties": {
-        "Domain": {
-          "CertificateArn": "arn:aws:acm:us-east-1:111122223333:certificate/9bb7fd90-00cf-4326-ae14-7dc62c92dfe5",
-          "DomainName": "api.example.com",
-          "SecurityPolicy": "TLS_1_0"
+        "Body": {
+          "info": {
+            "title": {
+              "_unknown": {
+                "Ref": "AWS::StackName"
+              }
+            },
+            "version": "1.0"
+          },
+          "openapi": "3.0.1",
+          "paths": {}
         }
       },
-      "Type": "AWS::Serverless::HttpApi"
+      "Type": "AWS::ApiGatewayV2::Api"
     },
     "ServerlessAPI2": {
       "Properties": {
-        "Domain": {
-          "CertificateArn": "arn:aws:acm:us-east-1:111122223333:certificate/cf9e8763-2af9-490f-84ea-c91c0f668755",
-          "DomainName": "api-2.example.com"
+        "Body": {
+          "info": {
+            "title": {
+              "_unknown": {
+                "Ref": "AWS::StackName"
+              }
+            },
+            "version": "1.0"
+          },
+          "openapi": "3.0.1",
+          "paths": {}
         }
       },
-      "Type": "AWS::Serverless::HttpApi"
+      "Type": "AWS::ApiGatewayV2::Api"
+    },
+    "ServerlessAPI2ApiGatewayDefaultStage": {
+      "Properties": {
+        "ApiId": {
+          "Ref": "ServerlessAPI2"
+        },
+        "AutoDeploy": true,
+        "StageName": "$default"
+      },
+      "Type": "AWS::ApiGatewayV2::Stage"
+    },
+    "ServerlessAPI2DomainName": {
+      "Properties": {
+        "DomainName": "api-2.example.com",
+        "DomainNameConfigurations": [
+          {
+            "CertificateArn": "arn:aws:acm:us-east-1:111122223333:certificate/cf9e8763-2af9-490f-84ea-c91c0f668755",
+            "EndpointType": "REGIONAL"
+          }
+        ]
+      },
+      "Type": "AWS::ApiGatewayV2::DomainName"
+    },
+    "ServerlessAPIApiGatewayDefaultStage": {
+      "Properties": {
+        "ApiId": {
+          "Ref": "ServerlessAPI"
+        },
+        "AutoDeploy": true,
+        "StageName": "$default"
+      },
+      "Type": "AWS::ApiGatewayV2::Stage"
+    },
+    "ServerlessAPIDomainName": {
+      "Properties": {
+        "DomainName": "api.example.com",
+        "DomainNameConfigurations": [
+          {
+            "CertificateArn": "arn:aws:acm:us-east-1:111122223333:certificate/9bb7fd90-00cf-4326-ae14-7dc62c92dfe5",
+            "EndpointType": "REGIONAL",
+            "SecurityPolicy": "TLS_1_0"
+          }
+        ]
+      },
+      "Type": "AWS::ApiGatewayV2::DomainName"
     }
   },
   "Transform": "AWS::Serverless-2016-10-31"
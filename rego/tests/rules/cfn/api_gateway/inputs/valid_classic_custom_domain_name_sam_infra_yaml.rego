@@ -24,21 +24,65 @@ mock_config := {
   "Description": "Valid classic custom domain name configuration",
   "Resources": {
     "ServerlessAPI": {
+      "Properties": {},
+      "Type": "AWS::ApiGateway::RestApi"
+    },
+    "ServerlessAPI2": {
+      "Properties": {},
+      "Type": "AWS::ApiGateway::RestApi"
+    },
+    "ServerlessAPI2Deployment": {
       "Properties": {
-        "Domain": {
-          "CertificateArn": "arn:aws:acm:us-east-1:111122223333:certificate/9bb7fd90-00cf-4326-ae14-7dc62c92dfe5",
-          "DomainName": "api.example.com",
-          "SecurityPolicy": "TLS_1_2"
+        "RestApiId": {
+          "Ref": "ServerlessAPI2"
+        }
+      },
+      "Type": "AWS::ApiGateway::Deployment"
+    },
+    "ServerlessAPI2ProdStage": {
+      "Properties": {
+        "DeploymentId": {
+          "Ref": "ServerlessAPI2Deployment"
+        },
+        "RestApiId": {
+          "Ref": "ServerlessAPI2"
         },
         "StageName": "Prod"
       },
-      "Type": "AWS::Serverless::Api"
+      "Type": "AWS::ApiGateway::Stage"
     },
-    "ServerlessAPI2": {
+    "ServerlessAPIDeployment": {
       "Properties": {
+        "RestApiId": {
+          "Ref": "ServerlessAPI"
+        }
+      },
+      "Type": "AWS::ApiGateway::Deployment"
+    },
+    "ServerlessAPIDomainName": {
+      "Properties": {
+        "DomainName": "api.example.com",
+        "EndpointConfiguration": {
+          "Types": [
+            "REGIONAL"
+          ]
+        },
+        "RegionalCertificateArn": "arn:aws:acm:us-east-1:111122223333:certificate/9bb7fd90-00cf-4326-ae14-7dc62c92dfe5",
+        "SecurityPolicy": "TLS_1_2"
+      },
+      "Type": "AWS::ApiGateway::DomainName"
+    },
+    "ServerlessAPIProdStage": {
+      "Properties": {
+        "DeploymentId": {
+          "Ref": "ServerlessAPIDeployment"
+        },
+        "RestApiId": {
+          "Ref": "ServerlessAPI"
+        },
         "StageName": "Prod"
       },
-      "Type": "AWS::Serverless::Api"
+      "Type": "AWS::ApiGateway::Stage"
     }
   },
   "Transform": "AWS::Serverless-2016-10-31"
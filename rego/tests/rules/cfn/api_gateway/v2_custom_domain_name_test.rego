@@ -26,7 +26,7 @@ test_valid_v2_custom_domain_name_sam {
   pol = policy with input as inputs.valid_v2_custom_domain_name_sam_infra_yaml.mock_input
   by_resource_id = {p.id: p.valid | pol[p]}
   count(by_resource_id) == 1
-  by_resource_id["ServerlessAPI"] == true
+  by_resource_id["ServerlessAPIDomainName"] == true
 }
 
 test_invalid_v2_custom_domain_name {
@@ -43,6 +43,6 @@ test_invalid_v2_custom_domain_name_sam {
   pol = policy with input as inputs.invalid_v2_custom_domain_name_sam_infra_yaml.mock_input
   by_resource_id = {p.id: p.valid | pol[p]}
   count(by_resource_id) == 2
-  by_resource_id["ServerlessAPI"] == false
-  by_resource_id["ServerlessAPI2"] == false
+  by_resource_id["ServerlessAPIDomainName"] == false
+  by_resource_id["ServerlessAPI2DomainName"] == false
 }
@@ -30,9 +30,7 @@ mock_config := {
   "Resources": {
     "InvalidVolume01": {
       "Properties": {
-        "AvailabilityZone": {
-          "Ref": "AvailabilityZone"
-        },
+        "AvailabilityZone": "us-east-1b",
         "Encrypted": false,
         "Size": 1
       },
@@ -40,18 +38,14 @@ mock_config := {
     },
     "InvalidVolume02": {
       "Properties": {
-        "AvailabilityZone": {
-          "Ref": "AvailabilityZone"
-        },
+        "AvailabilityZone": "us-east-1b",
         "Size": 1
       },
       "Type": "AWS::EC2::Volume"
     },
     "ValidVolume01": {
       "Properties": {
-        "AvailabilityZone": {
-          "Ref": "AvailabilityZone"
-        },
+        "AvailabilityZone": "us-east-1b",
         "Encrypted": true,
         "Size": 1
       },
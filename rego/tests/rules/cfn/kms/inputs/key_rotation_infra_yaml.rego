@@ -37,7 +37,7 @@ mock_config := {
             "Effect": "Allow",
             "Principal": {
               "AWS": {
-                "Fn::Sub": "arn:aws:iam::${AWS::AccountId}:${KeyManager}"
+                "Fn::Sub": "arn:aws:iam::${AWS::AccountId}:user/jasper"
               }
             },
             "Resource": "*"
@@ -55,7 +55,7 @@ mock_config := {
             "Effect": "Allow",
             "Principal": {
               "AWS": {
-                "Fn::Sub": "arn:aws:iam::${AWS::AccountId}:${KeyManager}"
+                "Fn::Sub": "arn:aws:iam::${AWS::AccountId}:user/jasper"
               }
             },
             "Resource": "*"
@@ -74,7 +74,7 @@ mock_config := {
             "Effect": "Allow",
             "Principal": {
               "AWS": {
-                "Fn::Sub": "arn:aws:iam::${AWS::AccountId}:${KeyManager}"
+                "Fn::Sub": "arn:aws:iam::${AWS::AccountId}:user/jasper"
               }
             },
             "Resource": "*"
@@ -25,11 +25,25 @@ mock_config := {
   "Resources": {
     "Function": {
       "Properties": {
+        "Code": {
+          "ZipFile": "exports.handler = (event, context) => {\n  console.log(JSON.stringify(event))\n}\n"
+        },
         "Handler": "index.handler",
-        "InlineCode": "exports.handler = (event, context) => {\n  console.log(JSON.stringify(event))\n}\n",
-        "Runtime": "nodejs12.x"
+        "Role": {
+          "Fn::GetAtt": [
+            "FunctionRole",
+            "Arn"
+          ]
+        },
+        "Runtime": "nodejs12.x",
+        "Tags": [
+          {
+            "Key": "lambda:createdBy",
+            "Value": "SAM"
+          }
+        ]
       },
-      "Type": "AWS::Serverless::Function"
+      "Type": "AWS::Lambda::Function"
     },
     "FunctionPermission": {
       "Properties": {
@@ -45,6 +59,36 @@ mock_config := {
         }
       },
       "Type": "AWS::Lambda::Permission"
+    },
+    "FunctionRole": {
+      "Properties": {
+        "AssumeRolePolicyDocument": {
+          "Statement": [
+            {
+              "Action": [
+                "sts:AssumeRole"
+              ],
+              "Effect": "Allow",
+              "Principal": {
+                "Service": [
+                  "lambda.amazonaws.com"
+                ]
+              }
+            }
+          ],
+          "Version": "2012-10-17"
+        },
+        "ManagedPolicyArns": [
+          "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+        ],
+        "Tags": [
+          {
+            "Key": "lambda:createdBy",
+            "Value": "SAM"
+          }
+        ]
+      },
+      "Type": "AWS::IAM::Role"
     }
   },
   "Transform": "AWS::Serverless-2016-10-31"
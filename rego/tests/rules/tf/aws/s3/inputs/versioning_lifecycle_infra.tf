@@ -0,0 +1,60 @@
+# Copyright 2022 Fugue, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+provider "aws" {
+  region = "us-east-1"
+}
+
+resource "aws_s3_bucket" "valid" {
+  versioning {
+    enabled = true
+  }
+
+  lifecycle_rule {
+    enabled = true
+    expiration {
+      days = 90
+    }
+  }
+}
+
+resource "aws_s3_bucket" "unversioned" {
+  lifecycle_rule {
+    enabled = true
+    expiration {
+      days = 90
+    }
+  }
+}
+
+resource "aws_s3_bucket" "no_lifecycle" {
+  versioning {
+    enabled = true
+  }
+}
+
+resource "aws_s3_bucket" "separate_versioning" {
+  lifecycle_rule {
+    enabled = true
+    expiration {
+      days = 90
+    }
+  }
+}
+
+resource "aws_s3_bucket_versioning" "separate_versioning" {
+  bucket = aws_s3_bucket.separate_versioning.id
+  versioning_configuration {
+    status = "Suspended"
+  }
+}
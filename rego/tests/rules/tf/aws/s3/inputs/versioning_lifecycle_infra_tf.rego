@@ -0,0 +1,108 @@
+# Copyright 2020-2021 Fugue, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+package tests.rules.tf.aws.s3.inputs.versioning_lifecycle_infra_tf
+
+import data.fugue.resource_view.resource_view_input
+
+mock_input := ret {
+  ret = resource_view_input with input as mock_config
+}
+mock_resources := mock_input.resources
+mock_config := {
+  "hcl_resource_view_version": "0.0.1",
+  "resources": {
+    "aws_s3_bucket.no_lifecycle": {
+      "_filepath": "tests/rules/tf/aws/s3/inputs/versioning_lifecycle_infra.tf",
+      "_provider": "aws",
+      "_tags": {},
+      "_type": "aws_s3_bucket",
+      "id": "aws_s3_bucket.no_lifecycle",
+      "versioning": [
+        {
+          "enabled": true
+        }
+      ]
+    },
+    "aws_s3_bucket.separate_versioning": {
+      "_filepath": "tests/rules/tf/aws/s3/inputs/versioning_lifecycle_infra.tf",
+      "_provider": "aws",
+      "_tags": {},
+      "_type": "aws_s3_bucket",
+      "id": "aws_s3_bucket.separate_versioning",
+      "lifecycle_rule": [
+        {
+          "enabled": true,
+          "expiration": [
+            {
+              "days": 90
+            }
+          ]
+        }
+      ]
+    },
+    "aws_s3_bucket.unversioned": {
+      "_filepath": "tests/rules/tf/aws/s3/inputs/versioning_lifecycle_infra.tf",
+      "_provider": "aws",
+      "_tags": {},
+      "_type": "aws_s3_bucket",
+      "id": "aws_s3_bucket.unversioned",
+      "lifecycle_rule": [
+        {
+          "enabled": true,
+          "expiration": [
+            {
+              "days": 90
+            }
+          ]
+        }
+      ]
+    },
+    "aws_s3_bucket.valid": {
+      "_filepath": "tests/rules/tf/aws/s3/inputs/versioning_lifecycle_infra.tf",
+      "_provider": "aws",
+      "_tags": {},
+      "_type": "aws_s3_bucket",
+      "id": "aws_s3_bucket.valid",
+      "lifecycle_rule": [
+        {
+          "enabled": true,
+          "expiration": [
+            {
+              "days": 90
+            }
+          ]
+        }
+      ],
+      "versioning": [
+        {
+          "enabled": true
+        }
+      ]
+    },
+    "aws_s3_bucket_versioning.separate_versioning": {
+      "_filepath": "tests/rules/tf/aws/s3/inputs/versioning_lifecycle_infra.tf",
+      "_provider": "aws",
+      "_tags": {},
+      "_type": "aws_s3_bucket_versioning",
+      "bucket": "aws_s3_bucket.separate_versioning",
+      "id": "aws_s3_bucket_versioning.separate_versioning",
+      "versioning_configuration": [
+        {
+          "status": "Suspended"
+        }
+      ]
+    }
+  }
+}
+
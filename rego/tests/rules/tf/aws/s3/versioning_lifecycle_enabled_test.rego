@@ -0,0 +1,35 @@
+# Copyright 2022 Fugue, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+package rules.tf_aws_s3_versioning_lifecycle_enabled
+
+import data.tests.rules.tf.aws.s3.inputs.versioning_lifecycle_infra_tf
+
+versioning_fix = {"attribute": ["versioning", 0, "enabled"], "value": true}
+
+test_versioning_lifecycle_enabled {
+  pol = policy with input as versioning_lifecycle_infra_tf.mock_input
+  by_resource_id = {p.id: p.valid | pol[p]}
+  by_resource_id["aws_s3_bucket.valid"] == true
+  by_resource_id["aws_s3_bucket.unversioned"] == false
+  by_resource_id["aws_s3_bucket.no_lifecycle"] == false
+  by_resource_id["aws_s3_bucket.separate_versioning"] == false
+}
+
+test_versioning_lifecycle_enabled_fixes {
+  pol = policy with input as versioning_lifecycle_infra_tf.mock_input
+  fixes = {p.id: p.fixes | pol[p]; not p.valid}
+  fixes["aws_s3_bucket.unversioned"] == [versioning_fix]
+  fixes["aws_s3_bucket.no_lifecycle"] == []
+  fixes["aws_s3_bucket.separate_versioning"] == []
+}